@@ -4,6 +4,7 @@ package config
 
 import (
 	"slices"
+	"strings"
 
 	"github.com/brainexe/viper"
 )
@@ -17,20 +18,18 @@ type Config struct {
 	AllowedUsers     UserList `mapstructure:"allowed_users,flow"`
 	AdminUsers       UserList `mapstructure:"admin_users,flow"`
 
-	Pool        Pool      `mapstructure:"pool"`
-	Jenkins     Jenkins   `mapstructure:"jenkins"`
-	Jira        Jira      `mapstructure:"jira"`
-	StoragePath string    `mapstructure:"storage_path"`
-	Bitbucket   Bitbucket `mapstructure:"bitbucket"`
-	Github      Github    `mapstructure:"github"`
-	Gitlab      struct {
-		AccessToken string
-		Host        string
-	} `mapstructure:"gitlab"`
-	Aws      Aws       `mapstructure:"aws"`
-	Commands []Command `mapstructure:"commands"`
-	Crons    []Cron    `mapstructure:"crons"`
-	Logger   Logger    `mapstructure:"logger"`
+	Pool           Pool           `mapstructure:"pool"`
+	Jenkins        Jenkins        `mapstructure:"jenkins"`
+	Jira           Jira           `mapstructure:"jira"`
+	StoragePath    string         `mapstructure:"storage_path"`
+	Bitbucket      Bitbucket      `mapstructure:"bitbucket"`
+	BitbucketCloud BitbucketCloud `mapstructure:"bitbucket_cloud"`
+	Github         Github         `mapstructure:"github"`
+	Gitlab         Gitlab         `mapstructure:"gitlab"`
+	Aws            Aws            `mapstructure:"aws"`
+	Commands       []Command      `mapstructure:"commands"`
+	Crons          []Cron         `mapstructure:"crons"`
+	Logger         Logger         `mapstructure:"logger"`
 
 	BranchLookup VCS `mapstructure:"branch_lookup"`
 
@@ -64,9 +63,33 @@ func (c *Config) Set(key string, value any) {
 	c.viper.Set(key, value)
 }
 
-// Github config, currently just an access token
+// Github config, the access token for github.com and optional GitHub Enterprise servers
 type Github struct {
 	AccessToken string `mapstructure:"access_token"`
+
+	// list of self-hosted GitHub Enterprise servers, each with its own access token
+	Enterprise []GithubEnterprise `mapstructure:"enterprise"`
+
+	// GitHub Actions workflows which can be started via Slack
+	Workflows GithubWorkflows `mapstructure:"workflows"`
+}
+
+// GithubEnterprise is a self-hosted GitHub server, like "https://github.example.com"
+type GithubEnterprise struct {
+	Host        string `mapstructure:"host"`
+	AccessToken string `mapstructure:"access_token"`
+
+	// optional, default is "<host>/api/v3/"
+	APIURL string `mapstructure:"api_url"`
+}
+
+// GetAPIURL returns the REST API endpoint of the server
+func (g GithubEnterprise) GetAPIURL() string {
+	if g.APIURL != "" {
+		return g.APIURL
+	}
+
+	return strings.TrimSuffix(g.Host, "/") + "/api/v3/"
 }
 
 // OpenWeather is an optional feature to get current weather
@@ -126,6 +149,34 @@ func (c *Bitbucket) IsEnabled() bool {
 	return c.Host != ""
 }
 
+// BitbucketCloud credentials/options for bitbucket.org. Either add Username+AppPassword OR an AccessToken (workspace/repository token)
+type BitbucketCloud struct {
+	Username    string `mapstructure:"username"`
+	AppPassword string `mapstructure:"app_password"`
+	AccessToken string `mapstructure:"access_token"`
+
+	// workspace and repository, used for the branch lookup
+	Workspace  string `mapstructure:"workspace"`
+	Repository string `mapstructure:"repository"`
+
+	// optional, default is "https://api.bitbucket.org/2.0"
+	APIURL string `mapstructure:"api_url"`
+}
+
+// IsEnabled checks if credentials are defined in the Bitbucket Cloud config
+func (c *BitbucketCloud) IsEnabled() bool {
+	return c.AccessToken != "" || (c.Username != "" && c.AppPassword != "")
+}
+
+// GetAPIURL returns the REST API endpoint of Bitbucket Cloud
+func (c *BitbucketCloud) GetAPIURL() string {
+	if c.APIURL != "" {
+		return strings.TrimSuffix(c.APIURL, "/")
+	}
+
+	return "https://api.bitbucket.org/2.0"
+}
+
 // UserList is a wrapper for []string with some helper to check is a user is in the list (e.g. used for AdminUser list)
 type UserList []string
 
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// GithubWorkflow is a whitelisted GitHub Actions workflow which can be started via "workflow_dispatch"
+type GithubWorkflow struct {
+	// repository like "innogames/slack-bot"
+	Repo string `mapstructure:"repo"`
+
+	// file name (like "deploy.yml") or ID of the workflow
+	Workflow string `mapstructure:"workflow"`
+
+	// allowed refs (branches/tags). Default: all refs
+	Refs []string `mapstructure:"refs"`
+
+	// allowed workflow inputs. Default: no inputs
+	Inputs []string `mapstructure:"inputs"`
+}
+
+// IsAllowedRef checks if the workflow can be started for the given ref
+func (w GithubWorkflow) IsAllowedRef(ref string) bool {
+	return len(w.Refs) == 0 || slices.Contains(w.Refs, ref)
+}
+
+// IsAllowedInput checks if the input can be passed to the workflow
+func (w GithubWorkflow) IsAllowedInput(name string) bool {
+	return slices.Contains(w.Inputs, name)
+}
+
+// GithubWorkflows is the list of all (whitelisted) GitHub Actions workflows
+type GithubWorkflows []GithubWorkflow
+
+// Get returns the config of the given workflow, the repository is case-insensitive
+func (w GithubWorkflows) Get(repo string, workflow string) (GithubWorkflow, bool) {
+	for _, cfg := range w {
+		if strings.EqualFold(cfg.Repo, repo) && cfg.Workflow == workflow {
+			return cfg, true
+		}
+	}
+
+	return GithubWorkflow{}, false
+}
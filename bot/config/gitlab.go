@@ -0,0 +1,63 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Gitlab config: credentials and the whitelisted pipelines which can be started/managed via Slack
+type Gitlab struct {
+	AccessToken string
+	Host        string
+
+	// projects (like "group/project") whose pipelines can be started, retried, canceled and played
+	Pipelines GitlabPipelines `mapstructure:"pipelines"`
+
+	ApprovalTimeout time.Duration `mapstructure:"approval_timeout"`
+}
+
+// GetApprovalTimeout returns the configured approval timeout or the default (5 minutes)
+func (c Gitlab) GetApprovalTimeout() time.Duration {
+	if c.ApprovalTimeout > 0 {
+		return c.ApprovalTimeout
+	}
+	return defaultApprovalTimeout
+}
+
+// GitlabPipelineConfig defines which refs and variables are allowed for the pipelines of a project
+type GitlabPipelineConfig struct {
+	// allowed refs (branches/tags). Default: all refs
+	Refs []string
+
+	// allowed pipeline variables. Default: no variables
+	Variables []string
+
+	NeedsApproval bool `mapstructure:"needs_approval"`
+}
+
+// IsAllowedRef checks if a pipeline can be started for the given ref
+func (c GitlabPipelineConfig) IsAllowedRef(ref string) bool {
+	return len(c.Refs) == 0 || slices.Contains(c.Refs, ref)
+}
+
+// IsAllowedVariable checks if the variable can be passed to the pipeline
+func (c GitlabPipelineConfig) IsAllowedVariable(name string) bool {
+	return slices.ContainsFunc(c.Variables, func(variable string) bool {
+		return strings.EqualFold(variable, name)
+	})
+}
+
+// GitlabPipelines is the list of all (whitelisted) GitLab projects, indexed by the project path
+type GitlabPipelines map[string]GitlabPipelineConfig
+
+// Get returns the config of the given project. The lookup is case-insensitive, as viper is using lowercase keys
+func (p GitlabPipelines) Get(project string) (GitlabPipelineConfig, bool) {
+	for name, cfg := range p {
+		if strings.EqualFold(name, project) {
+			return cfg, true
+		}
+	}
+
+	return GitlabPipelineConfig{}, false
+}
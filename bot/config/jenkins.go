@@ -45,6 +45,9 @@ type JobParameter struct {
 	Name    string
 	Default string
 	Type    string
+
+	// name of the branch_lookup repository which is used for parameters of type "branch". Default: all repositories
+	Repository string
 }
 
 // JenkinsJobs is the list of all (whitelisted) Jenkins jobs
@@ -7,17 +7,58 @@ type Pool struct {
 	LockDuration time.Duration
 	NotifyExpire time.Duration
 	Resources    []*Resource
+
+	// HistoryRetention defines how long lock/unlock events are kept for "pool history" and "pool report" (default: 30 days)
+	HistoryRetention time.Duration
 }
 
+const defaultHistoryRetention = 30 * 24 * time.Hour
+
 // Resource config contains definitions about the
 type Resource struct {
 	Name         string
 	ExplicitLock bool
 	Addresses    []string
 	Features     []string
+
+	// optional check of the Addresses: unhealthy resources are not lockable
+	HealthCheck HealthCheck `mapstructure:"health_check"`
+
+	// commands which are executed after the resource got locked/unlocked, like "trigger job ResetServer {{.resource}}"
+	OnLock   []string `mapstructure:"on_lock"`
+	OnUnlock []string `mapstructure:"on_unlock"`
+}
+
+// HealthCheck defines how the Addresses of a Resource are checked: via "http" (status code < 400) or "tcp" (open port)
+type HealthCheck struct {
+	Type    string        `mapstructure:"type"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+const defaultHealthCheckTimeout = 5 * time.Second
+
+// IsEnabled checks if a health check type was defined
+func (c HealthCheck) IsEnabled() bool {
+	return c.Type != ""
+}
+
+// GetTimeout returns the configured timeout of a single check or the default (5 seconds)
+func (c HealthCheck) GetTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return defaultHealthCheckTimeout
 }
 
 // IsEnabled checks if there are resources in the pool
 func (c *Pool) IsEnabled() bool {
 	return len(c.Resources) > 0
 }
+
+// GetHistoryRetention returns the configured history retention or the default (30 days)
+func (c *Pool) GetHistoryRetention() time.Duration {
+	if c.HistoryRetention > 0 {
+		return c.HistoryRetention
+	}
+	return defaultHistoryRetention
+}
@@ -1,6 +1,11 @@
 package config
 
-import "github.com/innogames/slack-bot/v2/bot/util"
+import (
+	"strings"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot/util"
+)
 
 // PullRequest special configuration to change the pull request behavior
 type PullRequest struct {
@@ -17,6 +22,166 @@ type PullRequest struct {
 	// pull requests, based on the Jira ticket referenced in the branch name or PR title.
 	// Requires a configured Jira connection (see "jira" section).
 	JiraPriorityReactions map[string]util.Reaction `mapstructure:"jira_priority_reactions"`
+
+	// optional webhook receiver to get PR updates pushed by GitHub/GitLab/Bitbucket instead of frequent polling
+	Webhook PullRequestWebhook `mapstructure:"webhook"`
+
+	// maps VCS usernames (GitHub/GitLab/Bitbucket) to Slack users, used for private notifications and reminders
+	UserMapping map[string]string `mapstructure:"user_mapping"`
+
+	// remind reviewers and authors about pull requests which are stuck in review
+	Reminders PullRequestReminders `mapstructure:"reminders"`
+
+	// optional: merge pull requests via "merge pr <url>" or a button in the "ready to merge" notification
+	Merge PullRequestMerge `mapstructure:"merge"`
+
+	// optional: AI generated summaries of pull requests, requires the "openai" config
+	Summary PullRequestSummary `mapstructure:"summary"`
+}
+
+// GetSlackUser returns the mapped Slack user of a VCS username, or the given name when there is no mapping
+func (c PullRequest) GetSlackUser(vcsUser string) string {
+	// viper is using lowercase keys
+	if slackUser, ok := c.UserMapping[strings.ToLower(vcsUser)]; ok {
+		return slackUser
+	}
+
+	return vcsUser
+}
+
+// PullRequestMerge allows to merge pull requests of the listed repositories from Slack
+type PullRequestMerge struct {
+	// allowlist of repositories like "innogames/slack-bot" (GitHub/Bitbucket) or "group/project" (GitLab). "*" allows all repositories
+	Repos []string `mapstructure:"repos"`
+
+	// default merge method: "merge", "squash" or "rebase". Default: "merge"
+	Method string `mapstructure:"method"`
+}
+
+// IsEnabled returns true if at least one repository is allowed to be merged
+func (c PullRequestMerge) IsEnabled() bool {
+	return len(c.Repos) > 0
+}
+
+// IsAllowedRepo checks if PRs of the given repository can be merged via Slack
+func (c PullRequestMerge) IsAllowedRepo(repo string) bool {
+	for _, allowed := range c.Repos {
+		if allowed == "*" || strings.EqualFold(allowed, repo) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetMethod returns the default merge method
+func (c PullRequestMerge) GetMethod() string {
+	if c.Method == "" {
+		return "merge"
+	}
+
+	return c.Method
+}
+
+// PullRequestSummary posts an AI generated summary of posted pull requests into the thread
+type PullRequestSummary struct {
+	// repositories like "innogames/slack-bot" which get a summary automatically when a PR is posted. "*" for all repositories
+	Repos []string `mapstructure:"repos"`
+
+	// the diff is truncated to this amount of tokens. Default: 8000
+	MaxTokens int `mapstructure:"max_tokens"`
+}
+
+// IsAutoEnabled checks if PRs of the given repository get a summary automatically
+func (c PullRequestSummary) IsAutoEnabled(repo string) bool {
+	for _, allowed := range c.Repos {
+		if allowed == "*" || strings.EqualFold(allowed, repo) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetMaxTokens returns the max size of the diff which is sent to the model
+func (c PullRequestSummary) GetMaxTokens() int {
+	if c.MaxTokens == 0 {
+		return 8000
+	}
+
+	return c.MaxTokens
+}
+
+// PullRequestReminders defines when and whom to remind about watched pull requests
+type PullRequestReminders struct {
+	Rules []ReminderRule `mapstructure:"rules"`
+
+	// reminders are only sent within the working hours, like "09:00-18:00". Default: at any time
+	WorkingHours string `mapstructure:"working_hours"`
+
+	// reminders are only sent on these days, like "Monday". Default: Monday till Friday
+	WorkingDays []string `mapstructure:"working_days"`
+}
+
+// IsEnabled returns true if there is at least one reminder rule
+func (c PullRequestReminders) IsEnabled() bool {
+	return len(c.Rules) > 0
+}
+
+// GetWorkingDays returns the days where reminders are sent
+func (c PullRequestReminders) GetWorkingDays() []string {
+	if len(c.WorkingDays) == 0 {
+		return []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}
+	}
+
+	return c.WorkingDays
+}
+
+// ReminderRule like "after 24h in review without approval, DM the reviewers"
+type ReminderRule struct {
+	// "in_review": in review without any approval, "open": not merged or closed yet
+	Status string `mapstructure:"status"`
+
+	// the PR needs to have the status for at least this duration
+	After time.Duration `mapstructure:"after"`
+
+	// "reviewers": DM to all reviewers without approval, "author": DM to the author, "thread": reply in the thread of the posted PR
+	Notify string `mapstructure:"notify"`
+
+	// optional: repeat the reminder in this interval. Default: only remind once
+	Repeat time.Duration `mapstructure:"repeat"`
+}
+
+// PullRequestWebhook receives PR/MR events. Each provider endpoint is only active when its secret is set
+type PullRequestWebhook struct {
+	// e.g. ":8083" to listen on all interfaces
+	Listener string `mapstructure:"listener"`
+
+	// secret of the GitHub webhook, used to validate the "X-Hub-Signature-256" header
+	GithubSecret string `mapstructure:"github_secret"`
+
+	// secret token of the GitLab webhook, sent in the "X-Gitlab-Token" header
+	GitlabToken string `mapstructure:"gitlab_token"`
+
+	// secret of the Bitbucket webhook, used to validate the "X-Hub-Signature" header
+	BitbucketSecret string `mapstructure:"bitbucket_secret"`
+
+	// PRs are still polled in this interval as a fallback, default: 15m
+	FallbackInterval time.Duration `mapstructure:"fallback_interval"`
+}
+
+// IsEnabled returns true if the webhook receiver is enabled by config
+func (c PullRequestWebhook) IsEnabled() bool {
+	return c.Listener != ""
+}
+
+// GetFallbackInterval returns the polling interval which is used while the webhook receiver is active
+func (c PullRequestWebhook) GetFallbackInterval() time.Duration {
+	if c.FallbackInterval == 0 {
+		return 15 * time.Minute
+	}
+
+	return c.FallbackInterval
 }
 
 // Notifications can be defined in the config.yaml to enable notifications for pull request builds.
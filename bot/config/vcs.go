@@ -2,12 +2,42 @@ package config
 
 import "time"
 
+// DefaultRepository is the name of the repository which is defined by "type" and "repository" of the branch_lookup config
+const DefaultRepository = "default"
+
 type VCS struct {
-	Type           string        `mapstructure:"type"` // stash/bitbucket/git/null
+	Type           string        `mapstructure:"type"` // stash/bitbucket/bitbucket_cloud/github/gitlab/git/null
 	Repository     string        `mapstructure:"repository"`
 	UpdateInterval time.Duration `mapstructure:"update_interval"`
+
+	// additional named repositories, a Jenkins parameter of type "branch" can reference them via "repository"
+	Repositories []VCSRepository `mapstructure:"repositories"`
+}
+
+// VCSRepository is a named repository whose branches are used for the branch lookup
+type VCSRepository struct {
+	Name string `mapstructure:"name"`
+	Type string `mapstructure:"type"` // stash/bitbucket/bitbucket_cloud/github/gitlab/git
+
+	// git: URL of the repository, github: "owner/repo", gitlab: "group/project",
+	// bitbucket: "project/repo", bitbucket_cloud: "workspace/repo" (default: the repository of the bitbucket config)
+	Repository string `mapstructure:"repository"`
 }
 
 func (c VCS) IsEnabled() bool {
-	return c.Type != "" && c.UpdateInterval > 0
+	return (c.Type != "" || len(c.Repositories) > 0) && c.UpdateInterval > 0
+}
+
+// GetRepositories returns all configured repositories, including the default one
+func (c VCS) GetRepositories() []VCSRepository {
+	repositories := make([]VCSRepository, 0, len(c.Repositories)+1)
+	if c.Type != "" {
+		repositories = append(repositories, VCSRepository{
+			Name:       DefaultRepository,
+			Type:       c.Type,
+			Repository: c.Repository,
+		})
+	}
+
+	return append(repositories, c.Repositories...)
 }
@@ -39,6 +39,7 @@ func (b *Bot) handleEvent(eventsAPIEvent slackevents.EventsAPIEvent) {
 					User:            ev.User,
 					Timestamp:       ev.TimeStamp,
 					ThreadTimestamp: ev.ThreadTimeStamp,
+					Files:           ev.Message.Files,
 				},
 			}
 			b.HandleMessage(message)
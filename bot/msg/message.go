@@ -1,13 +1,18 @@
 package msg
 
 // Message is a wrapper which holds all important fields from slack.MessageEvent
-import "sync"
+import (
+	"sync"
+
+	"github.com/slack-go/slack"
+)
 
 // Message represents a slack.Message in a slim format. The MessageRef contains the context of the message
 type Message struct {
 	MessageRef
-	Text string          `json:"text,omitempty"`
-	Done *sync.WaitGroup `json:"-"` // WaitGroup gets unlocked when the message was processed
+	Text  string          `json:"text,omitempty"`
+	Files []slack.File    `json:"-"` // attached files, text files are already included in the Text
+	Done  *sync.WaitGroup `json:"-"` // WaitGroup gets unlocked when the message was processed
 }
 
 // GetText returns the attached text of the message
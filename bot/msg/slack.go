@@ -10,7 +10,8 @@ func (msg *Message) GetMessageRef() slack.ItemRef {
 // FromSlackEvent generates a slack.MessageEvent into a msg.Message
 func FromSlackEvent(event *slack.MessageEvent) Message {
 	return Message{
-		Text: event.Text,
+		Text:  event.Text,
+		Files: event.Files,
 		MessageRef: MessageRef{
 			Channel:   event.Channel,
 			Thread:    event.ThreadTimestamp,
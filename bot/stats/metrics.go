@@ -3,6 +3,7 @@ package stats
 import (
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/innogames/slack-bot/v2/bot/config"
@@ -13,6 +14,19 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+var (
+	customCollectors   []prometheus.Collector
+	customCollectorsMu sync.Mutex
+)
+
+// RegisterCollector adds an additional prometheus.Collector (e.g. gauges of a command) which is exported when metrics are enabled
+func RegisterCollector(collector prometheus.Collector) {
+	customCollectorsMu.Lock()
+	defer customCollectorsMu.Unlock()
+
+	customCollectors = append(customCollectors, collector)
+}
+
 type statRegistry struct{}
 
 // Describe returns all descriptions of the collector.
@@ -45,6 +59,10 @@ func InitMetrics(cfg config.Config, ctx *util.ServerContext) {
 		collectors.NewGoCollector(),
 	)
 
+	customCollectorsMu.Lock()
+	registry.MustRegister(customCollectors...)
+	customCollectorsMu.Unlock()
+
 	ctx.Go(func() {
 		log.Infof("Init prometheus handler on http://%s/metrics", cfg.Metrics.PrometheusListener)
 
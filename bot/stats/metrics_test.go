@@ -10,6 +10,7 @@ import (
 
 	"github.com/innogames/slack-bot/v2/bot/config"
 	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,6 +28,12 @@ func TestMetrics(t *testing.T) {
 
 	Set("test_value", 500)
 
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "slack_bot_custom_gauge",
+	})
+	gauge.Set(42)
+	RegisterCollector(gauge)
+
 	InitMetrics(cfg, ctx)
 	time.Sleep(time.Millisecond * 10)
 
@@ -40,6 +47,7 @@ func TestMetrics(t *testing.T) {
 
 	content, _ := io.ReadAll(resp.Body)
 	assert.Contains(t, string(content), "slack_bot_test_value 500")
+	assert.Contains(t, string(content), "slack_bot_custom_gauge 42")
 }
 
 // get a random free port on the host
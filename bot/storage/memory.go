@@ -5,8 +5,7 @@ import (
 	"errors"
 	"maps"
 	"slices"
-
-	"github.com/innogames/slack-bot/v2/bot/util"
+	"sync"
 )
 
 // this is a primitive in-memory storage which is used for faster storage of data.
@@ -18,18 +17,18 @@ type memoryCollection map[string][]byte
 func newMemoryStorage() Storage {
 	return &memoryStorage{
 		storage: make(map[string]memoryCollection),
-		locks:   util.NewGroupedLogger(),
 	}
 }
 
+// a single lock is used for all collections, as new collections are added to the same map
 type memoryStorage struct {
 	storage map[string]memoryCollection
-	locks   util.GroupedLock[string]
+	mu      sync.RWMutex
 }
 
 func (s *memoryStorage) Write(collection, key string, v any) error {
-	lock := s.locks.GetLock(collection)
-	defer lock.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	if _, ok := s.storage[collection]; !ok {
 		s.storage[collection] = make(memoryCollection)
@@ -46,8 +45,8 @@ func (s *memoryStorage) Write(collection, key string, v any) error {
 }
 
 func (s *memoryStorage) Read(collection, key string, v any) error {
-	lock := s.locks.GetRLock(collection)
-	defer lock.Unlock()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
 	if _, ok := s.storage[collection]; !ok {
 		return errors.New("collection is empty")
@@ -61,8 +60,8 @@ func (s *memoryStorage) Read(collection, key string, v any) error {
 }
 
 func (s *memoryStorage) GetKeys(collection string) ([]string, error) {
-	lock := s.locks.GetRLock(collection)
-	defer lock.Unlock()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
 	keys := slices.Collect(maps.Keys(s.storage[collection]))
 
@@ -70,8 +69,8 @@ func (s *memoryStorage) GetKeys(collection string) ([]string, error) {
 }
 
 func (s *memoryStorage) Delete(collection, key string) error {
-	lock := s.locks.GetLock(collection)
-	defer lock.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	// an empty key deletes the whole collection (see DeleteCollection)
 	if key == "" {
@@ -0,0 +1,301 @@
+package util
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	// max decompressed size of a single PDF stream, to avoid zip bombs
+	maxPDFStreamSize = 10 * 1024 * 1024
+
+	// max decompressed size of all streams of a PDF document
+	maxPDFTotalSize = 50 * 1024 * 1024
+
+	// max length of the extracted text, the rest of the document is ignored
+	maxPDFTextSize = 1024 * 1024
+)
+
+var (
+	pdfStreamRe  = regexp.MustCompile(`stream\r?\n`)
+	pdfObjectRe  = regexp.MustCompile(`\d+\s+\d+\s+obj\b`)
+	whitespaceRe = regexp.MustCompile(`[ \t]+`)
+	emptyLinesRe = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// ExtractPDFText extracts the plain text of the content streams of a PDF document.
+// It's a simple extractor without font support: scanned documents or custom font encodings result in an empty or garbled text.
+func ExtractPDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", errors.New("not a PDF document")
+	}
+
+	var text strings.Builder
+	decompressed := 0
+	for _, loc := range pdfStreamRe.FindAllIndex(data, -1) {
+		if decompressed >= maxPDFTotalSize || text.Len() >= maxPDFTextSize {
+			break
+		}
+
+		// skip the "endstream" keyword itself
+		if loc[0] >= 3 && string(data[loc[0]-3:loc[0]]) == "end" {
+			continue
+		}
+
+		end := bytes.Index(data[loc[1]:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		content := data[loc[1] : loc[1]+end]
+
+		// the dictionary of the stream is placed between the "obj" keyword and the stream
+		dictionary := data[:loc[0]]
+		if objects := pdfObjectRe.FindAllIndex(dictionary, -1); len(objects) > 0 {
+			dictionary = dictionary[objects[len(objects)-1][0]:]
+		}
+
+		switch {
+		case bytes.Contains(dictionary, []byte("/Subtype/Image")), bytes.Contains(dictionary, []byte("/Subtype /Image")):
+			continue
+		case bytes.Contains(dictionary, []byte("/FlateDecode")):
+			reader, err := zlib.NewReader(bytes.NewReader(content))
+			if err != nil {
+				continue
+			}
+			// partially broken streams are still used
+			content, _ = io.ReadAll(io.LimitReader(reader, int64(min(maxPDFStreamSize, maxPDFTotalSize-decompressed))))
+			decompressed += len(content)
+		case bytes.Contains(dictionary, []byte("/Filter")):
+			// other filters (like images) are not supported
+			continue
+		}
+
+		if bytes.Contains(content, []byte("BT")) {
+			text.WriteString(extractPDFContentText(content))
+		}
+	}
+
+	result := text.String()
+	if len(result) > maxPDFTextSize {
+		result = strings.ToValidUTF8(result[:maxPDFTextSize], "")
+	}
+	result = whitespaceRe.ReplaceAllString(result, " ")
+	result = emptyLinesRe.ReplaceAllString(result, "\n")
+
+	return strings.TrimSpace(result), nil
+}
+
+// extractPDFContentText interprets the text operators (like Tj and TJ) of a content stream
+func extractPDFContentText(content []byte) string {
+	var text strings.Builder
+	var operands []string
+	var array []string
+	inArray := false
+
+	for pos := 0; pos < len(content); {
+		char := content[pos]
+		switch {
+		case char == '(':
+			str, next := readPDFLiteralString(content, pos)
+			pos = next
+			if inArray {
+				array = append(array, str)
+			} else {
+				operands = append(operands, str)
+			}
+		case char == '<' && pos+1 < len(content) && content[pos+1] != '<':
+			end := bytes.IndexByte(content[pos:], '>')
+			if end < 0 {
+				return text.String()
+			}
+			str := decodePDFHexString(content[pos+1 : pos+end])
+			pos += end + 1
+			if inArray {
+				array = append(array, str)
+			} else {
+				operands = append(operands, str)
+			}
+		case char == '[':
+			inArray = true
+			array = array[:0]
+			pos++
+		case char == ']':
+			inArray = false
+			operands = append(operands, strings.Join(array, ""))
+			pos++
+		case char == '%':
+			// comment till the end of the line
+			for pos < len(content) && content[pos] != '\n' && content[pos] != '\r' {
+				pos++
+			}
+		case isPDFDelimiter(char):
+			pos++
+		default:
+			start := pos
+			for pos < len(content) && !isPDFDelimiter(content[pos]) && content[pos] != '(' && content[pos] != '<' && content[pos] != '[' && content[pos] != ']' {
+				pos++
+			}
+			token := string(content[start:pos])
+
+			if inArray {
+				// big negative kerning within TJ arrays is usually used as word spacing
+				if number, err := strconv.ParseFloat(token, 64); err == nil && number < -200 {
+					array = append(array, " ")
+				}
+				continue
+			}
+
+			switch token {
+			case "Tj", "TJ":
+				if len(operands) > 0 {
+					text.WriteString(operands[len(operands)-1])
+				}
+			case "'", "\"":
+				text.WriteString("\n")
+				if len(operands) > 0 {
+					text.WriteString(operands[len(operands)-1])
+				}
+			case "T*", "ET":
+				text.WriteString("\n")
+			case "Td", "TD":
+				if len(operands) > 0 && operands[len(operands)-1] != "0" {
+					text.WriteString("\n")
+				} else {
+					text.WriteString(" ")
+				}
+			default:
+				if _, err := strconv.ParseFloat(token, 64); err == nil {
+					operands = append(operands, token)
+					continue
+				}
+			}
+			if !strings.HasPrefix(token, "/") {
+				operands = operands[:0]
+			}
+		}
+	}
+
+	return text.String()
+}
+
+func isPDFDelimiter(char byte) bool {
+	switch char {
+	case ' ', '\t', '\r', '\n', '\f', 0:
+		return true
+	}
+
+	return false
+}
+
+// readPDFLiteralString reads a "(string)" starting at pos and returns the string and the position after it
+func readPDFLiteralString(content []byte, pos int) (string, int) {
+	var str []byte
+	depth := 0
+
+	for pos < len(content) {
+		char := content[pos]
+		pos++
+
+		switch char {
+		case '(':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case ')':
+			depth--
+			if depth == 0 {
+				return decodePDFString(str), pos
+			}
+		case '\\':
+			if pos >= len(content) {
+				continue
+			}
+			escaped := content[pos]
+			pos++
+			switch escaped {
+			case 'n':
+				str = append(str, '\n')
+			case 'r':
+				str = append(str, '\r')
+			case 't':
+				str = append(str, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// line continuation
+			default:
+				if escaped >= '0' && escaped <= '7' {
+					octal := []byte{escaped}
+					for len(octal) < 3 && pos < len(content) && content[pos] >= '0' && content[pos] <= '7' {
+						octal = append(octal, content[pos])
+						pos++
+					}
+					value, _ := strconv.ParseUint(string(octal), 8, 8)
+					str = append(str, byte(value))
+				} else {
+					str = append(str, escaped)
+				}
+			}
+			continue
+		}
+		str = append(str, char)
+	}
+
+	return decodePDFString(str), pos
+}
+
+func decodePDFHexString(hex []byte) string {
+	hex = bytes.Join(bytes.Fields(hex), nil)
+	if len(hex)%2 == 1 {
+		hex = append(hex, '0')
+	}
+
+	str := make([]byte, 0, len(hex)/2)
+	for i := 0; i < len(hex); i += 2 {
+		value, err := strconv.ParseUint(string(hex[i:i+2]), 16, 8)
+		if err != nil {
+			return ""
+		}
+		str = append(str, byte(value))
+	}
+
+	return decodePDFString(str)
+}
+
+// decodePDFString handles UTF-16 strings (with BOM or as two byte glyph ids) and single byte (Latin-1 like) strings
+func decodePDFString(str []byte) string {
+	isUTF16 := bytes.HasPrefix(str, []byte{0xFE, 0xFF})
+	if isUTF16 {
+		str = str[2:]
+	} else if len(str) >= 2 && len(str)%2 == 0 {
+		isUTF16 = true
+		for i := 0; i < len(str); i += 2 {
+			if str[i] != 0 {
+				isUTF16 = false
+				break
+			}
+		}
+	}
+
+	if isUTF16 && len(str)%2 == 0 {
+		chars := make([]uint16, 0, len(str)/2)
+		for i := 0; i < len(str); i += 2 {
+			chars = append(chars, uint16(str[i])<<8|uint16(str[i+1]))
+		}
+
+		return string(utf16.Decode(chars))
+	}
+
+	runes := make([]rune, len(str))
+	for i, char := range str {
+		runes[i] = rune(char)
+	}
+
+	return string(runes)
+}
@@ -0,0 +1,66 @@
+package util
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildPDF creates a minimal PDF document with the given content streams
+func buildPDF(streams ...string) []byte {
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+
+	for i, stream := range streams {
+		fmt.Fprintf(&pdf, "%d 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n", i+3, len(stream), stream)
+	}
+
+	return pdf.Bytes()
+}
+
+func TestExtractPDFText(t *testing.T) {
+	t.Run("No PDF", func(t *testing.T) {
+		_, err := ExtractPDFText([]byte("hello"))
+		require.EqualError(t, err, "not a PDF document")
+	})
+
+	t.Run("Text operators", func(t *testing.T) {
+		pdf := buildPDF(
+			"BT /F1 12 Tf 72 712 Td (Error report) Tj 0 -14 Td [(Build) -250 (failed) 10 (!)] TJ T* (Line \\(3\\)) Tj ET",
+			"BT /F1 12 Tf <00480069> Tj ET",
+		)
+
+		text, err := ExtractPDFText(pdf)
+		require.NoError(t, err)
+		assert.Equal(t, "Error report\nBuild failed!\nLine (3)\nHi", text)
+	})
+
+	t.Run("Compressed stream", func(t *testing.T) {
+		var compressed bytes.Buffer
+		writer := zlib.NewWriter(&compressed)
+		writer.Write([]byte("BT /F1 12 Tf (compressed text) Tj ET"))
+		writer.Close()
+
+		var pdf bytes.Buffer
+		pdf.WriteString("%PDF-1.5\n4 0 obj\n<< /Length 10 /Filter /FlateDecode >>\nstream\n")
+		pdf.Write(compressed.Bytes())
+		pdf.WriteString("\nendstream\nendobj\n5 0 obj\n<< /Subtype /Image /Filter /DCTDecode >>\nstream\nBT (image) Tj ET\nendstream\nendobj\n")
+
+		text, err := ExtractPDFText(pdf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, "compressed text", text)
+	})
+	t.Run("Text limit", func(t *testing.T) {
+		stream := "BT (" + strings.Repeat("a", 600*1024) + ") Tj ET"
+		pdf := buildPDF(stream, stream, stream)
+
+		text, err := ExtractPDFText(pdf)
+		require.NoError(t, err)
+		assert.Len(t, text, maxPDFTextSize)
+	})
+}
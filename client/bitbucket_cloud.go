@@ -0,0 +1,128 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/innogames/slack-bot/v2/bot/config"
+)
+
+// BitbucketCloud is a small client for the REST API 2.0 of bitbucket.org
+type BitbucketCloud struct {
+	cfg    config.BitbucketCloud
+	client *http.Client
+}
+
+// BitbucketCloudError is returned for all non 2xx responses
+type BitbucketCloudError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e BitbucketCloudError) Error() string {
+	return fmt.Sprintf("bitbucket cloud: status %d: %s", e.StatusCode, e.Message)
+}
+
+// GetBitbucketCloudClient initialized a API client based on the given config
+func GetBitbucketCloudClient(cfg config.BitbucketCloud) (*BitbucketCloud, error) {
+	if !cfg.IsEnabled() {
+		return nil, errors.New("bitbucket cloud: no credentials given")
+	}
+
+	return &BitbucketCloud{cfg, GetHTTPClient()}, nil
+}
+
+// Get loads the given API path (like "repositories/workspace/repo") and decodes the JSON response into result
+func (c *BitbucketCloud) Get(path string, result any) error {
+	body, err := c.GetRaw(path)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, result)
+}
+
+// GetRaw returns the raw response of the given API path, e.g. used for diffs
+func (c *BitbucketCloud) GetRaw(path string) ([]byte, error) {
+	return c.do(http.MethodGet, path, nil)
+}
+
+// Post sends the given body as JSON to the API path and decodes the response into result (optional)
+func (c *BitbucketCloud) Post(path string, body any, result any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.do(http.MethodPost, path, payload)
+	if err != nil || result == nil {
+		return err
+	}
+
+	return json.Unmarshal(response, result)
+}
+
+// GetAllPages loads all pages of a paginated API path and returns the raw "values" of each page
+func (c *BitbucketCloud) GetAllPages(path string) ([]json.RawMessage, error) {
+	var values []json.RawMessage
+
+	for path != "" {
+		var page struct {
+			Values []json.RawMessage `json:"values"`
+			Next   string            `json:"next"`
+		}
+		if err := c.Get(path, &page); err != nil {
+			return values, err
+		}
+
+		values = append(values, page.Values...)
+		path = page.Next
+	}
+
+	return values, nil
+}
+
+func (c *BitbucketCloud) do(method string, path string, payload []byte) ([]byte, error) {
+	// "next" links of paginated responses are already absolute URLs
+	url := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		url = c.cfg.GetAPIURL() + "/" + strings.TrimPrefix(path, "/")
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), method, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.cfg.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.AccessToken)
+	} else {
+		req.SetBasicAuth(c.cfg.Username, c.cfg.AppPassword)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, BitbucketCloudError{resp.StatusCode, strings.TrimSpace(string(body))}
+	}
+
+	return body, nil
+}
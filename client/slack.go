@@ -127,6 +127,9 @@ type SlackClient interface {
 	// PinMessage will pin a message to the channel
 	PinMessage(channel string, timestamp string) error
 
+	// ListPins returns the pinned items of the channel
+	ListPins(channel string) ([]slack.Item, *slack.Paging, error)
+
 	// GetFile downloads a file from Slack by its private URL
 	GetFile(downloadURL string, writer io.Writer) error
 }
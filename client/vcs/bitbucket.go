@@ -14,26 +14,28 @@ type bitbucket struct {
 	cfg    config.Bitbucket
 }
 
-// LoadBranches will load the branches from a stash/bitbucket server
-func (f *bitbucket) LoadBranches() (branchNames []string, err error) {
+// LoadBranches will load the branches from a stash/bitbucket server. The API doesn't return the date of the last commit,
+// but the branches are ordered by modification, so the most recently updated ones are preferred.
+func (f *bitbucket) LoadBranches() (branchList []Branch, err error) {
 	branchesRaw, err := f.client.GetBranches(f.cfg.Project, f.cfg.Repository, map[string]any{
-		"limit": bitbucketBranchLimit,
+		"limit":   bitbucketBranchLimit,
+		"orderBy": "MODIFICATION",
 	})
 	if err != nil {
-		return branchNames, err
+		return branchList, err
 	}
 
 	branchesRaw.Body.Close()
 
 	branches, err := bitbucketApi.GetBranchesResponse(branchesRaw)
 	if err != nil {
-		return branchNames, errors.Wrap(err, "Can't load branched from Bitbucket")
+		return branchList, errors.Wrap(err, "Can't load branched from Bitbucket")
 	}
 
-	branchNames = make([]string, 0, len(branches))
+	branchList = make([]Branch, 0, len(branches))
 	for _, branch := range branches {
-		branchNames = append(branchNames, branch.DisplayID)
+		branchList = append(branchList, Branch{Name: branch.DisplayID})
 	}
 
-	return branchNames, err
+	return branchList, err
 }
@@ -0,0 +1,44 @@
+package vcs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/pkg/errors"
+)
+
+type bitbucketCloud struct {
+	client *client.BitbucketCloud
+	cfg    config.BitbucketCloud
+}
+
+// LoadBranches will load all branches of the configured bitbucket.org repository
+func (f *bitbucketCloud) LoadBranches() ([]Branch, error) {
+	rawBranches, err := f.client.GetAllPages(fmt.Sprintf(
+		"repositories/%s/%s/refs/branches?pagelen=100&fields=values.name,values.target.date,next",
+		f.cfg.Workspace,
+		f.cfg.Repository,
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't load branches from Bitbucket Cloud")
+	}
+
+	branchList := make([]Branch, 0, len(rawBranches))
+	for _, rawBranch := range rawBranches {
+		var branch struct {
+			Name   string `json:"name"`
+			Target struct {
+				Date time.Time `json:"date"`
+			} `json:"target"`
+		}
+		if err = json.Unmarshal(rawBranch, &branch); err != nil {
+			return branchList, err
+		}
+		branchList = append(branchList, Branch{Name: branch.Name, Updated: branch.Target.Date})
+	}
+
+	return branchList, nil
+}
@@ -0,0 +1,68 @@
+package vcs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitbucketCloudLoader(t *testing.T) {
+	var server *httptest.Server
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repositories/workspace/repo/refs/branches", func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Bearer 0815", req.Header.Get("Authorization"))
+
+		if req.URL.Query().Get("page") == "2" {
+			fmt.Fprint(res, `{"values": [{"name": "release", "target": {"date": "2024-01-02T10:00:00Z"}}]}`)
+			return
+		}
+		fmt.Fprintf(res, `{"values": [{"name": "master"}], "next": "%s/repositories/workspace/repo/refs/branches?page=2"}`, server.URL)
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Run("Load branches", func(t *testing.T) {
+		cfg := config.BitbucketCloud{
+			AccessToken: "0815",
+			Workspace:   "workspace",
+			Repository:  "repo",
+			APIURL:      server.URL,
+		}
+		bitbucketClient, err := client.GetBitbucketCloudClient(cfg)
+		require.NoError(t, err)
+
+		fetcher := &bitbucketCloud{bitbucketClient, cfg}
+
+		branches, err := fetcher.LoadBranches()
+		require.NoError(t, err)
+		assert.Equal(t, []Branch{
+			{Name: "master"},
+			{Name: "release", Updated: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)},
+		}, branches)
+	})
+
+	t.Run("Load branches with not existing repo", func(t *testing.T) {
+		cfg := config.BitbucketCloud{
+			AccessToken: "0815",
+			Workspace:   "workspace",
+			Repository:  "notExisting",
+			APIURL:      server.URL,
+		}
+		bitbucketClient, err := client.GetBitbucketCloudClient(cfg)
+		require.NoError(t, err)
+
+		fetcher := &bitbucketCloud{bitbucketClient, cfg}
+
+		branches, err := fetcher.LoadBranches()
+		require.EqualError(t, err, "can't load branches from Bitbucket Cloud: bitbucket cloud: status 404: 404 page not found")
+		assert.Empty(t, branches)
+	})
+}
@@ -31,7 +31,7 @@ func TestBitbucketLoader(t *testing.T) {
 
 		branches, err := fetcher.LoadBranches()
 		require.NoError(t, err)
-		assert.Equal(t, []string{"master", "release"}, branches)
+		assert.Equal(t, []Branch{{Name: "master"}, {Name: "release"}}, branches)
 	})
 
 	t.Run("Load branches with not existing repo", func(t *testing.T) {
@@ -57,7 +57,12 @@ func spawnBitbucketTestServer() *httptest.Server {
 	mux := http.NewServeMux()
 
 	// 1337: merged pr
-	mux.HandleFunc("/rest/api/1.0/projects/myProject/repos/myRepo/branches", func(res http.ResponseWriter, _ *http.Request) {
+	mux.HandleFunc("/rest/api/1.0/projects/myProject/repos/myRepo/branches", func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("orderBy") != "MODIFICATION" {
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
 		res.Write([]byte(`{
 			"values": [
 				{
@@ -15,8 +15,8 @@ type git struct {
 
 var gitBranchRe = regexp.MustCompile(`refs/(remotes/origin|heads)/(.*)\n`)
 
-// LoadBranches will load the branches from a (remote) git repository
-func (f git) LoadBranches() (branchNames []string, err error) {
+// LoadBranches will load the branches from a (remote) git repository. ls-remote doesn't provide the date of the last commit.
+func (f git) LoadBranches() (branchList []Branch, err error) {
 	/* #nosec */
 	cmd := exec.CommandContext(context.Background(), "git", "ls-remote", "--refs", f.repoURL)
 	output, err := cmd.Output()
@@ -25,12 +25,12 @@ func (f git) LoadBranches() (branchNames []string, err error) {
 			err,
 			"failed to load branches: "+cmd.String(),
 		)
-		return branchNames, err
+		return branchList, err
 	}
 
 	for _, match := range gitBranchRe.FindAllStringSubmatch(string(output), -1) {
-		branchNames = append(branchNames, match[2])
+		branchList = append(branchList, Branch{Name: match[2]})
 	}
 
-	return branchNames, err
+	return branchList, err
 }
@@ -0,0 +1,50 @@
+package vcs
+
+import (
+	"context"
+
+	githubApi "github.com/google/go-github/github"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+type github struct {
+	client *githubApi.Client
+	owner  string
+	repo   string
+}
+
+func newGithub(cfg config.Github, owner string, repo string) *github {
+	httpClient := client.GetHTTPClient()
+	if cfg.AccessToken != "" {
+		httpClient = oauth2.NewClient(
+			context.WithValue(context.Background(), oauth2.HTTPClient, httpClient),
+			oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cfg.AccessToken}),
+		)
+	}
+
+	return &github{githubApi.NewClient(httpClient), owner, repo}
+}
+
+// LoadBranches will load all branches of the GitHub repository. The list API doesn't provide the date of the last commit.
+func (f *github) LoadBranches() ([]Branch, error) {
+	branchList := make([]Branch, 0)
+	options := &githubApi.ListOptions{PerPage: 100}
+	for {
+		githubBranches, resp, err := f.client.Repositories.ListBranches(context.Background(), f.owner, f.repo, options)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't load branches from GitHub")
+		}
+
+		for _, branch := range githubBranches {
+			branchList = append(branchList, Branch{Name: branch.GetName()})
+		}
+
+		if resp.NextPage == 0 {
+			return branchList, nil
+		}
+		options.Page = resp.NextPage
+	}
+}
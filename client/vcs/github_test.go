@@ -0,0 +1,50 @@
+package vcs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGithubLoader(t *testing.T) {
+	var server *httptest.Server
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/innogames/slack-bot/branches", func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Bearer 0815", req.Header.Get("Authorization"))
+
+		if req.URL.Query().Get("page") == "2" {
+			fmt.Fprint(res, `[{"name": "release"}]`)
+			return
+		}
+		res.Header().Set("Link", fmt.Sprintf(`<%s/repos/innogames/slack-bot/branches?page=2>; rel="next"`, server.URL))
+		fmt.Fprint(res, `[{"name": "master"}]`)
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	newFetcher := func(repo string) *github {
+		fetcher := newGithub(config.Github{AccessToken: "0815"}, "innogames", repo)
+		fetcher.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+		return fetcher
+	}
+
+	t.Run("Load branches", func(t *testing.T) {
+		branches, err := newFetcher("slack-bot").LoadBranches()
+		require.NoError(t, err)
+		assert.Equal(t, []Branch{{Name: "master"}, {Name: "release"}}, branches)
+	})
+
+	t.Run("Load branches with not existing repo", func(t *testing.T) {
+		branches, err := newFetcher("notExisting").LoadBranches()
+		require.ErrorContains(t, err, "can't load branches from GitHub")
+		assert.Empty(t, branches)
+	})
+}
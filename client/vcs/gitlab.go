@@ -0,0 +1,51 @@
+package vcs
+
+import (
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/pkg/errors"
+	gitlabApi "gitlab.com/gitlab-org/api/client-go"
+)
+
+type gitlab struct {
+	client  *gitlabApi.Client
+	project string
+}
+
+func newGitlab(cfg config.Gitlab, project string) (*gitlab, error) {
+	options := make([]gitlabApi.ClientOptionFunc, 0)
+	if cfg.Host != "" {
+		options = append(options, gitlabApi.WithBaseURL(cfg.Host))
+	}
+
+	gitlabClient, err := gitlabApi.NewClient(cfg.AccessToken, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gitlab{gitlabClient, project}, nil
+}
+
+// LoadBranches will load all branches of the GitLab project ("group/project"), including the date of the last commit
+func (f *gitlab) LoadBranches() ([]Branch, error) {
+	branchList := make([]Branch, 0)
+	options := &gitlabApi.ListBranchesOptions{ListOptions: gitlabApi.ListOptions{PerPage: 100}}
+	for {
+		gitlabBranches, resp, err := f.client.Branches.ListBranches(f.project, options)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't load branches from GitLab")
+		}
+
+		for _, branch := range gitlabBranches {
+			newBranch := Branch{Name: branch.Name}
+			if branch.Commit != nil && branch.Commit.CommittedDate != nil {
+				newBranch.Updated = *branch.Commit.CommittedDate
+			}
+			branchList = append(branchList, newBranch)
+		}
+
+		if resp.NextPage == 0 {
+			return branchList, nil
+		}
+		options.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,50 @@
+package vcs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitlabLoader(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/group%2Fproject/repository/branches", func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "0815", req.Header.Get("Private-Token"))
+
+		if req.URL.Query().Get("page") == "2" {
+			fmt.Fprint(res, `[{"name": "release", "commit": {"committed_date": "2024-01-02T10:00:00Z"}}]`)
+			return
+		}
+		res.Header().Set("X-Next-Page", "2")
+		fmt.Fprint(res, `[{"name": "master"}]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Run("Load branches", func(t *testing.T) {
+		fetcher, err := newGitlab(config.Gitlab{AccessToken: "0815", Host: server.URL}, "group/project")
+		require.NoError(t, err)
+
+		branches, err := fetcher.LoadBranches()
+		require.NoError(t, err)
+		assert.Equal(t, []Branch{
+			{Name: "master"},
+			{Name: "release", Updated: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)},
+		}, branches)
+	})
+
+	t.Run("Load branches with not existing repo", func(t *testing.T) {
+		fetcher, err := newGitlab(config.Gitlab{AccessToken: "0815", Host: server.URL}, "group/notExisting")
+		require.NoError(t, err)
+
+		branches, err := fetcher.LoadBranches()
+		require.ErrorContains(t, err, "can't load branches from GitLab")
+		assert.Empty(t, branches)
+	})
+}
@@ -3,8 +3,8 @@ package vcs
 type null struct{}
 
 // LoadBranches will just return nothing
-func (f null) LoadBranches() ([]string, error) {
-	var branchNames []string
+func (f null) LoadBranches() ([]Branch, error) {
+	var branchList []Branch
 
-	return branchNames, nil
+	return branchList, nil
 }
@@ -1,36 +1,79 @@
 package vcs
 
 import (
-	"fmt"
+	"maps"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/innogames/slack-bot/v2/bot/config"
 	"github.com/innogames/slack-bot/v2/bot/util"
 	"github.com/innogames/slack-bot/v2/client"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
-// cached list of branch names
-var branches []string
+// max number of suggested branches when the input is ambiguous
+const maxBranchSuggestions = 5
 
-// BranchFetcher loads a list of all available branch names in a repository
+// ranks of a matching branch: the higher the better
+const (
+	matchSubstring = iota + 1
+	matchJiraKey
+	matchPrefix
+	matchExact
+)
+
+var jiraKeyRe = regexp.MustCompile(`^[a-z][a-z0-9]+-\d+$`)
+
+// cached list of branches per repository name
+var (
+	branches   = map[string][]Branch{}
+	branchesMu sync.RWMutex
+)
+
+// Branch of a repository, with the date of the last commit if the VCS provides it
+type Branch struct {
+	Name    string
+	Updated time.Time
+}
+
+// BranchFetcher loads a list of all available branches in a repository
 type BranchFetcher interface {
-	LoadBranches() ([]string, error)
+	LoadBranches() ([]Branch, error)
+}
+
+// AmbiguousBranchError is returned when multiple branches match the input equally well
+type AmbiguousBranchError struct {
+	Input string
+
+	// best matching branches, the most recently updated first
+	Branches []string
+}
+
+func (e AmbiguousBranchError) Error() string {
+	return "multiple branches found: " + strings.Join(e.Branches, ", ")
 }
 
 // InitBranchWatcher will load the current branches each X from the configured VCS -> e.g. used for branch lookup for Jenkins parameters
 func InitBranchWatcher(cfg *config.Config, ctx *util.ServerContext) {
-	var err error
-	fetcher := createBranchFetcher(cfg)
+	fetchers := make(map[string]BranchFetcher)
+	for _, repository := range cfg.BranchLookup.GetRepositories() {
+		fetchers[repository.Name] = createBranchFetcher(cfg, repository)
+	}
 
 	ticker := time.NewTicker(cfg.BranchLookup.UpdateInterval)
 	defer ticker.Stop()
 
 	for {
-		branches, err = fetcher.LoadBranches()
-		if err != nil {
-			log.Error(err)
+		for name, fetcher := range fetchers {
+			repoBranches, err := fetcher.LoadBranches()
+			if err != nil {
+				log.WithField("repository", name).Error(err)
+			}
+			setBranches(name, repoBranches)
 		}
 
 		select {
@@ -43,50 +86,186 @@ func InitBranchWatcher(cfg *config.Config, ctx *util.ServerContext) {
 	}
 }
 
-// GetBranches returns a list of currently known branches
-func GetBranches() []string {
-	return branches
+func setBranches(repository string, repoBranches []Branch) {
+	branchesMu.Lock()
+	defer branchesMu.Unlock()
+
+	branches[repository] = repoBranches
 }
 
-// GetMatchingBranch does a fuzzy search on all loaded branches. If there are multiple matching branches, it fails.
+// GetRepositories returns the names of all repositories with loaded branches
+func GetRepositories() []string {
+	branchesMu.RLock()
+	defer branchesMu.RUnlock()
+
+	return slices.Sorted(maps.Keys(branches))
+}
+
+// GetBranches returns the currently known branches of the repository, or of all repositories when the name is empty
+func GetBranches(repository string) []Branch {
+	branchesMu.RLock()
+	defer branchesMu.RUnlock()
+
+	if repository != "" {
+		return slices.Clone(branches[repository])
+	}
+
+	// a branch can exist in multiple repositories: keep the most recently updated one
+	allBranches := make([]Branch, 0)
+	positions := make(map[string]int)
+	for _, name := range slices.Sorted(maps.Keys(branches)) {
+		for _, branch := range branches[name] {
+			idx, ok := positions[branch.Name]
+			if !ok {
+				positions[branch.Name] = len(allBranches)
+				allBranches = append(allBranches, branch)
+			} else if branch.Updated.After(allBranches[idx].Updated) {
+				allBranches[idx] = branch
+			}
+		}
+	}
+
+	return allBranches
+}
+
+// GetMatchingBranch does a fuzzy search on all loaded branches, see GetMatchingBranchOfRepository
 func GetMatchingBranch(input string) (string, error) {
-	var foundBranches []string
+	return GetMatchingBranchOfRepository("", input)
+}
 
-	loweredInput := strings.ToLower(input)
-	for _, branch := range GetBranches() {
-		loweredBranch := strings.ToLower(branch)
-		if loweredBranch == loweredInput {
-			return input, nil
-		} else if strings.Contains(loweredBranch, loweredInput) {
-			foundBranches = append(foundBranches, branch)
+// GetMatchingBranchOfRepository does a fuzzy search on the loaded branches of the repository (or all repositories when empty).
+// Exact matches are preferred over prefix matches, Jira keys and substrings. If multiple branches match equally well,
+// an AmbiguousBranchError with the best candidates is returned.
+func GetMatchingBranchOfRepository(repository string, input string) (string, error) {
+	if input == "" {
+		return "", errors.New("please provide a branch name")
+	}
+
+	repoBranches := GetBranches(repository)
+	candidates := rankBranches(repoBranches, input)
+	if len(candidates) == 0 {
+		log.Errorf("Branch not found: %s. We have %d known branches", input, len(repoBranches))
+
+		// branch not found in local list, but maybe it was created recently -> let's try it if jenkins accept it
+		return input, nil
+	}
+
+	bestRank := candidates[0].rank
+	if len(candidates) == 1 || candidates[1].rank < bestRank {
+		return candidates[0].Name, nil
+	}
+
+	ambiguous := AmbiguousBranchError{Input: input}
+	for _, candidate := range candidates {
+		if candidate.rank < bestRank || len(ambiguous.Branches) >= maxBranchSuggestions {
+			break
 		}
+		ambiguous.Branches = append(ambiguous.Branches, candidate.Name)
+	}
+
+	return "", ambiguous
+}
+
+type rankedBranch struct {
+	Branch
+	rank int
+}
+
+// rankBranches returns the matching branches, the best matches and the most recently updated first
+func rankBranches(branchList []Branch, input string) []rankedBranch {
+	loweredInput := strings.ToLower(input)
+
+	var keyRe *regexp.Regexp
+	if jiraKeyRe.MatchString(loweredInput) {
+		// the key must not be followed by another digit: PROJ-12 should not match PROJ-123
+		keyRe = regexp.MustCompile(`(^|[^a-z0-9])` + regexp.QuoteMeta(loweredInput) + `($|\D)`)
 	}
 
-	if len(foundBranches) > 1 {
-		return "", fmt.Errorf("multiple branches found: %s", strings.Join(foundBranches, ", "))
-	} else if len(foundBranches) == 1 {
-		return foundBranches[0], nil
+	candidates := make([]rankedBranch, 0)
+	for _, branch := range branchList {
+		loweredBranch := strings.ToLower(branch.Name)
+
+		rank := 0
+		switch {
+		case loweredBranch == loweredInput:
+			rank = matchExact
+		case strings.HasPrefix(loweredBranch, loweredInput):
+			rank = matchPrefix
+		case keyRe != nil && keyRe.MatchString(loweredBranch):
+			rank = matchJiraKey
+		case strings.Contains(loweredBranch, loweredInput):
+			rank = matchSubstring
+		default:
+			continue
+		}
+
+		candidates = append(candidates, rankedBranch{branch, rank})
 	}
 
-	log.Errorf("Branch not found: %s. We have %d known branches", input, len(branches))
+	// the order of the VCS is kept for branches without update date
+	slices.SortStableFunc(candidates, func(a, b rankedBranch) int {
+		if a.rank != b.rank {
+			return b.rank - a.rank
+		}
+
+		return b.Updated.Compare(a.Updated)
+	})
 
-	// branch not found in local list, but maybe it was created recently -> let's try it if jenkins accept it
-	return input, nil
+	return candidates
 }
 
-func createBranchFetcher(cfg *config.Config) BranchFetcher {
-	switch cfg.BranchLookup.Type {
+func createBranchFetcher(cfg *config.Config, repository config.VCSRepository) BranchFetcher {
+	switch repository.Type {
 	case "stash", "bitbucket":
-		bitbucketClient, err := client.GetBitbucketClient(cfg.Bitbucket)
+		bitbucketCfg := cfg.Bitbucket
+		if project, repo, ok := strings.Cut(repository.Repository, "/"); ok {
+			bitbucketCfg.Project = project
+			bitbucketCfg.Repository = repo
+		}
+
+		bitbucketClient, err := client.GetBitbucketClient(bitbucketCfg)
 		if err != nil {
 			log.Errorf("Cannot init Bitbucket client: %s", err)
 			return null{}
 		}
 
-		return &bitbucket{bitbucketClient, cfg.Bitbucket}
+		return &bitbucket{bitbucketClient, bitbucketCfg}
+	case "bitbucket_cloud":
+		bitbucketCfg := cfg.BitbucketCloud
+		if workspace, repo, ok := strings.Cut(repository.Repository, "/"); ok {
+			bitbucketCfg.Workspace = workspace
+			bitbucketCfg.Repository = repo
+		}
+
+		bitbucketClient, err := client.GetBitbucketCloudClient(bitbucketCfg)
+		if err != nil {
+			log.Errorf("Cannot init Bitbucket Cloud client: %s", err)
+			return null{}
+		}
+
+		return &bitbucketCloud{bitbucketClient, bitbucketCfg}
+	case "github":
+		owner, repo, ok := strings.Cut(repository.Repository, "/")
+		if !ok {
+			log.Errorf("Invalid GitHub repository %s, expected owner/repo", repository.Repository)
+			return null{}
+		}
+
+		return newGithub(cfg.Github, owner, repo)
+	case "gitlab":
+		fetcher, err := newGitlab(cfg.Gitlab, repository.Repository)
+		if err != nil {
+			log.Errorf("Cannot init GitLab client: %s", err)
+			return null{}
+		}
+
+		return fetcher
 	case "git":
-		return git{cfg.BranchLookup.Repository}
+		return git{repository.Repository}
 	default:
+		if repository.Type != "" && repository.Type != "null" {
+			log.Errorf("Unknown branch lookup type %s of repository %s", repository.Type, repository.Name)
+		}
 		return null{}
 	}
 }
@@ -15,11 +15,17 @@ func TestInitBranchWatcher(t *testing.T) {
 		cfg := &config.Config{}
 		cfg.BranchLookup.UpdateInterval = time.Second
 
-		branches = []string{
-			"release/3.12.23",
+		cfg.BranchLookup.Type = "null"
+		cfg.BranchLookup.Repositories = []config.VCSRepository{
+			{Name: "frontend", Type: "null"},
 		}
 
-		assert.Len(t, GetBranches(), 1)
+		setBranches(config.DefaultRepository, []Branch{{Name: "release/3.12.23"}})
+		setBranches("frontend", []Branch{{Name: "release/3.12.23"}, {Name: "master"}})
+
+		assert.Len(t, GetBranches(""), 2)
+		assert.Len(t, GetBranches("frontend"), 2)
+		assert.Equal(t, []string{"default", "frontend"}, GetRepositories())
 
 		ctx := util.NewServerContext()
 		go InitBranchWatcher(cfg, ctx)
@@ -27,24 +33,22 @@ func TestInitBranchWatcher(t *testing.T) {
 		ctx.StopTheWorld()
 
 		// as a nullFetcher is used -> should be empty now
-		assert.Empty(t, GetBranches())
+		assert.Empty(t, GetBranches(""))
+		assert.Empty(t, GetBranches("frontend"))
 	})
 
 	t.Run("Git", func(t *testing.T) {
 		cfg := &config.Config{}
-		cfg.BranchLookup.Type = "git"
-		cfg.BranchLookup.Repository = "test.git"
 
-		fetcher := createBranchFetcher(cfg)
+		fetcher := createBranchFetcher(cfg, config.VCSRepository{Type: "git", Repository: "test.git"})
 
 		assert.Equal(t, "test.git", fetcher.(git).repoURL)
 	})
 
 	t.Run("Bitbucket with invalid config", func(t *testing.T) {
 		cfg := &config.Config{}
-		cfg.BranchLookup.Type = "bitbucket"
 
-		fetcher := createBranchFetcher(cfg)
+		fetcher := createBranchFetcher(cfg, config.VCSRepository{Type: "bitbucket"})
 
 		// we expect a null-fetcher as we don't have valid bitbucket config
 		assert.IsType(t, null{}, fetcher)
@@ -52,23 +56,65 @@ func TestInitBranchWatcher(t *testing.T) {
 
 	t.Run("Bitbucket", func(t *testing.T) {
 		cfg := &config.Config{}
-		cfg.BranchLookup.Type = "bitbucket"
 		cfg.Bitbucket.Host = "https://bitbucket.example.com"
 		cfg.Bitbucket.APIKey = "iamsecret"
+		cfg.Bitbucket.Project = "myProject"
+		cfg.Bitbucket.Repository = "myRepo"
 
-		fetcher := createBranchFetcher(cfg)
-
+		fetcher := createBranchFetcher(cfg, config.VCSRepository{Type: "bitbucket"})
 		assert.IsType(t, &bitbucket{}, fetcher)
+		assert.Equal(t, "myRepo", fetcher.(*bitbucket).cfg.Repository)
+
+		// the repository of a named repository overrides the one of the bitbucket config
+		fetcher = createBranchFetcher(cfg, config.VCSRepository{Type: "bitbucket", Repository: "otherProject/otherRepo"})
+		assert.Equal(t, "otherProject", fetcher.(*bitbucket).cfg.Project)
+		assert.Equal(t, "otherRepo", fetcher.(*bitbucket).cfg.Repository)
+	})
+
+	t.Run("Bitbucket Cloud", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.BitbucketCloud.AccessToken = "iamsecret"
+
+		fetcher := createBranchFetcher(cfg, config.VCSRepository{Type: "bitbucket_cloud"})
+
+		assert.IsType(t, &bitbucketCloud{}, fetcher)
+	})
+
+	t.Run("GitHub", func(t *testing.T) {
+		cfg := &config.Config{}
+
+		fetcher := createBranchFetcher(cfg, config.VCSRepository{Type: "github", Repository: "innogames/slack-bot"})
+		assert.Equal(t, "slack-bot", fetcher.(*github).repo)
+
+		fetcher = createBranchFetcher(cfg, config.VCSRepository{Type: "github", Repository: "slack-bot"})
+		assert.IsType(t, null{}, fetcher)
+	})
+
+	t.Run("GitLab", func(t *testing.T) {
+		cfg := &config.Config{}
+
+		fetcher := createBranchFetcher(cfg, config.VCSRepository{Type: "gitlab", Repository: "group/project"})
+		assert.Equal(t, "group/project", fetcher.(*gitlab).project)
 	})
 }
 
 func TestGetMatchingBranches(t *testing.T) {
-	branches = []string{
-		"master",
-		"feature/PROJ-1234-do-something",
-		"feature/PROJ-1234-do-something-hotfix",
-		"bugfix/PROJ-1235-fixed",
-		"release/3.12.23",
+	now := time.Now()
+	branches = map[string][]Branch{
+		config.DefaultRepository: {
+			{Name: "master"},
+			{Name: "feature/PROJ-1234-do-something", Updated: now.Add(-time.Hour)},
+			{Name: "feature/PROJ-1234-do-something-hotfix", Updated: now},
+			{Name: "bugfix/PROJ-1235-fixed"},
+			{Name: "bugfix/PROJ-12-old-fix"},
+			{Name: "release/3.12.23"},
+		},
+		"frontend": {
+			{Name: "master"},
+			{Name: "feature/new-login"},
+			{Name: "feature/new-login-design"},
+			{Name: "hotfix/new-login"},
+		},
 	}
 
 	t.Run("Empty", func(t *testing.T) {
@@ -85,10 +131,41 @@ func TestGetMatchingBranches(t *testing.T) {
 
 	t.Run("Not unique", func(t *testing.T) {
 		actual, err := GetMatchingBranch("PROJ-1234")
-		require.EqualError(t, err, "multiple branches found: feature/PROJ-1234-do-something, feature/PROJ-1234-do-something-hotfix")
+		require.EqualError(t, err, "multiple branches found: feature/PROJ-1234-do-something-hotfix, feature/PROJ-1234-do-something")
+		assert.Empty(t, actual)
+
+		var ambiguousErr AmbiguousBranchError
+		require.ErrorAs(t, err, &ambiguousErr)
+		assert.Equal(t, "PROJ-1234", ambiguousErr.Input)
+
+		// both are prefix matches
+		actual, err = GetMatchingBranchOfRepository("frontend", "feature/new")
+		require.EqualError(t, err, "multiple branches found: feature/new-login, feature/new-login-design")
 		assert.Empty(t, actual)
 	})
 
+	t.Run("Ranking", func(t *testing.T) {
+		// prefix match is preferred over substring matches
+		actual, err := GetMatchingBranchOfRepository("frontend", "feature/new-login-")
+		require.NoError(t, err)
+		assert.Equal(t, "feature/new-login-design", actual)
+
+		// Jira key must match completely: PROJ-12 is not PROJ-1234
+		actual, err = GetMatchingBranch("proj-12")
+		require.NoError(t, err)
+		assert.Equal(t, "bugfix/PROJ-12-old-fix", actual)
+
+		// exact match (case insensitive) wins
+		actual, err = GetMatchingBranchOfRepository("frontend", "FEATURE/new-login")
+		require.NoError(t, err)
+		assert.Equal(t, "feature/new-login", actual)
+
+		// branch of another repository is unknown
+		actual, err = GetMatchingBranchOfRepository("frontend", "PROJ-1235")
+		require.NoError(t, err)
+		assert.Equal(t, "PROJ-1235", actual)
+	})
+
 	t.Run("Test unique branches", func(t *testing.T) {
 		actual, err := GetMatchingBranch("master")
 		assert.Equal(t, "master", actual)
@@ -0,0 +1,149 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// workflowRun is a single run of a GitHub Actions workflow
+type workflowRun struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	DisplayTitle string    `json:"display_title"`
+	HeadBranch   string    `json:"head_branch"`
+	Event        string    `json:"event"`
+	Status       string    `json:"status"`
+	Conclusion   string    `json:"conclusion"`
+	HTMLURL      string    `json:"html_url"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	RunStartedAt time.Time `json:"run_started_at"`
+
+	// the user who started the run
+	Actor struct {
+		Login string `json:"login"`
+	} `json:"actor"`
+}
+
+// getState returns the conclusion of a completed run, otherwise the current status (like "in_progress")
+func (r *workflowRun) getState() string {
+	if r.Status == "completed" && r.Conclusion != "" {
+		return r.Conclusion
+	}
+
+	return r.Status
+}
+
+// getDuration returns the current runtime of the run
+func (r *workflowRun) getDuration(now time.Time) time.Duration {
+	if r.RunStartedAt.IsZero() {
+		return 0
+	}
+	if r.Status == "completed" {
+		return r.UpdatedAt.Sub(r.RunStartedAt)
+	}
+
+	return now.Sub(r.RunStartedAt)
+}
+
+// workflowJob is a job of a workflow run
+type workflowJob struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	HTMLURL    string `json:"html_url"`
+}
+
+// getState returns the conclusion of a completed job, otherwise the current status
+func (j *workflowJob) getState() string {
+	if j.Status == "completed" && j.Conclusion != "" {
+		return j.Conclusion
+	}
+
+	return j.Status
+}
+
+// githubAPI abstracts the used GitHub Actions API calls for testability
+type githubAPI interface {
+	GetWorkflowRun(owner string, repo string, runID int64) (*workflowRun, error)
+	ListWorkflowRunJobs(owner string, repo string, runID int64) ([]*workflowJob, error)
+	ListWorkflowRuns(owner string, repo string, workflow string, ref string) ([]*workflowRun, error)
+	DispatchWorkflow(owner string, repo string, workflow string, ref string, inputs map[string]string) error
+	GetLogin() (string, error)
+}
+
+// realGithubAPI uses the raw REST endpoints, as the used go-github version has no support for GitHub Actions
+type realGithubAPI struct {
+	client *github.Client
+}
+
+func (g *realGithubAPI) GetWorkflowRun(owner string, repo string, runID int64) (*workflowRun, error) {
+	run := &workflowRun{}
+	err := g.do(http.MethodGet, fmt.Sprintf("repos/%s/%s/actions/runs/%d", owner, repo, runID), nil, run)
+
+	return run, err
+}
+
+func (g *realGithubAPI) ListWorkflowRunJobs(owner string, repo string, runID int64) ([]*workflowJob, error) {
+	var response struct {
+		Jobs []*workflowJob `json:"jobs"`
+	}
+	err := g.do(http.MethodGet, fmt.Sprintf("repos/%s/%s/actions/runs/%d/jobs?per_page=100", owner, repo, runID), nil, &response)
+
+	return response.Jobs, err
+}
+
+func (g *realGithubAPI) ListWorkflowRuns(owner string, repo string, workflow string, ref string) ([]*workflowRun, error) {
+	var response struct {
+		WorkflowRuns []*workflowRun `json:"workflow_runs"`
+	}
+
+	query := url.Values{}
+	query.Set("event", "workflow_dispatch")
+	query.Set("branch", ref)
+	query.Set("per_page", "10")
+	err := g.do(
+		http.MethodGet,
+		fmt.Sprintf("repos/%s/%s/actions/workflows/%s/runs?%s", owner, repo, url.PathEscape(workflow), query.Encode()),
+		nil,
+		&response,
+	)
+
+	return response.WorkflowRuns, err
+}
+
+func (g *realGithubAPI) DispatchWorkflow(owner string, repo string, workflow string, ref string, inputs map[string]string) error {
+	body := map[string]any{
+		"ref":    ref,
+		"inputs": inputs,
+	}
+
+	return g.do(http.MethodPost, fmt.Sprintf("repos/%s/%s/actions/workflows/%s/dispatches", owner, repo, url.PathEscape(workflow)), body, nil)
+}
+
+// GetLogin returns the login of the authenticated user, who is the actor of the dispatched runs
+func (g *realGithubAPI) GetLogin() (string, error) {
+	user, _, err := g.client.Users.Get(context.Background(), "")
+	if err != nil {
+		return "", err
+	}
+
+	return user.GetLogin(), nil
+}
+
+func (g *realGithubAPI) do(method string, path string, body any, result any) error {
+	req, err := g.client.NewRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.client.Do(context.Background(), req, result)
+
+	return err
+}
@@ -0,0 +1,70 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/client"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+const githubHost = "https://github.com"
+
+var category = bot.Category{
+	Name:        "GitHub Actions",
+	Description: "Watch and start GitHub Actions workflows",
+}
+
+// GetCommands returns the GitHub Actions commands, only if a GitHub access token or a GitHub Enterprise server is configured
+func GetCommands(base bot.BaseCommand, cfg *config.Config) bot.Commands {
+	var commands bot.Commands
+
+	if cfg.Github.AccessToken != "" {
+		baseCmd := actionsCommand{
+			BaseCommand: base,
+			api:         &realGithubAPI{client: github.NewClient(getHTTPClient(cfg.Github.AccessToken))},
+			host:        githubHost,
+			workflows:   cfg.Github.Workflows,
+		}
+
+		commands.AddCommand(
+			newNotifyCommand(baseCmd),
+			newWorkflowCommand(baseCmd),
+		)
+	}
+
+	// workflow runs of GitHub Enterprise servers can be watched as well
+	for _, enterprise := range cfg.Github.Enterprise {
+		apiURL := enterprise.GetAPIURL()
+		githubClient, err := github.NewEnterpriseClient(apiURL, apiURL, getHTTPClient(enterprise.AccessToken))
+		if err != nil {
+			log.Errorf("Error while initializing GitHub Enterprise client for %s: %s", enterprise.Host, err)
+			continue
+		}
+
+		commands.AddCommand(newNotifyCommand(actionsCommand{
+			BaseCommand: base,
+			api:         &realGithubAPI{client: githubClient},
+			host:        strings.TrimSuffix(enterprise.Host, "/"),
+		}))
+	}
+
+	return commands
+}
+
+// getHTTPClient returns an authenticated http client, when an access token is given
+func getHTTPClient(accessToken string) *http.Client {
+	if accessToken == "" {
+		return client.GetHTTPClient()
+	}
+
+	return oauth2.NewClient(
+		context.WithValue(context.Background(), oauth2.HTTPClient, client.GetHTTPClient()),
+		oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken}),
+	)
+}
@@ -0,0 +1,326 @@
+package actions
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/innogames/slack-bot/v2/command/queue"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	iconRunning = "arrows_counterclockwise"
+	iconSuccess = "white_check_mark"
+	iconFailed  = "x"
+
+	colorRunning = "#E0E000"
+	colorSuccess = "#00EE00"
+	colorFailed  = "#CC0000"
+	colorOther   = "#CCCCCC"
+
+	pollInterval = 15 * time.Second
+)
+
+type actionsCommand struct {
+	bot.BaseCommand
+	api githubAPI
+
+	// host of the GitHub server, like "https://github.com" or a GitHub Enterprise host
+	host string
+
+	// whitelisted workflows which can be started via Slack
+	workflows config.GithubWorkflows
+}
+
+// runRef identifies a single workflow run
+type runRef struct {
+	owner string
+	repo  string
+	id    int64
+}
+
+func (r runRef) String() string {
+	return fmt.Sprintf("%s/%s#%d", r.owner, r.repo, r.id)
+}
+
+type notifyCommand struct {
+	actionsCommand
+}
+
+func newNotifyCommand(base actionsCommand) bot.Command {
+	return &notifyCommand{base}
+}
+
+func (c *notifyCommand) GetMatcher() matcher.Matcher {
+	return matcher.NewRegexpMatcher(
+		`github notify (?P<url>`+regexp.QuoteMeta(c.host)+`/(?P<owner>[\w.-]+)/(?P<repo>[\w.-]+)/actions/runs/(?P<run>\d+)\S*)`,
+		c.watch,
+	)
+}
+
+func (c *notifyCommand) watch(match matcher.Result, message msg.Message) {
+	runID, _ := strconv.ParseInt(match.GetString("run"), 10, 64)
+
+	c.startWatch(message, runRef{
+		owner: match.GetString("owner"),
+		repo:  match.GetString("repo"),
+		id:    runID,
+	})
+}
+
+// statusInfo is the current state of a workflow run, including all jobs
+type statusInfo struct {
+	run  *workflowRun
+	jobs []*workflowJob
+}
+
+func (s statusInfo) isFinished() bool {
+	return s.run.Status == "completed"
+}
+
+func (c *actionsCommand) fetchStatus(ref runRef) (statusInfo, error) {
+	run, err := c.api.GetWorkflowRun(ref.owner, ref.repo, ref.id)
+	if err != nil {
+		return statusInfo{}, err
+	}
+
+	jobs, err := c.api.ListWorkflowRunJobs(ref.owner, ref.repo, ref.id)
+	if err != nil {
+		return statusInfo{}, err
+	}
+
+	return statusInfo{run, jobs}, nil
+}
+
+// startWatch posts the current status and watches the workflow run until it's finished
+func (c *actionsCommand) startWatch(message msg.Message, ref runRef) {
+	status, err := c.fetchStatus(ref)
+	if err != nil {
+		c.ReplyError(message, fmt.Errorf("error while fetching workflow run %s: %w", ref, err))
+		return
+	}
+
+	if status.isFinished() {
+		title := fmt.Sprintf("GitHub workflow run %s already finished", ref)
+		c.SendMessage(message, "", slack.MsgOptionAttachments(buildAttachment(title, status, time.Now())))
+		return
+	}
+
+	title := fmt.Sprintf("Watching GitHub workflow run %s", ref)
+	msgTimestamp := c.SendMessage(message, "", slack.MsgOptionAttachments(buildAttachment(title, status, time.Now())))
+
+	// when the bot gets restarted, the watcher is restarted as well
+	runningCommand := queue.AddRunningCommand(
+		message,
+		"github notify "+status.run.HTMLURL,
+	)
+
+	c.AddReaction(iconRunning, message)
+
+	go c.pollUntilDone(message, ref, title, msgTimestamp, runningCommand)
+}
+
+func (c *actionsCommand) pollUntilDone(
+	message msg.Message,
+	ref runRef,
+	title string,
+	msgTimestamp string,
+	runningCommand *queue.RunningCommand,
+) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var status statusInfo
+	for range ticker.C {
+		var err error
+		status, err = c.fetchStatus(ref)
+		if err != nil {
+			log.Warnf("Error polling GitHub workflow run %s: %s", ref, err)
+			continue
+		}
+
+		c.SendMessage(
+			message,
+			"",
+			slack.MsgOptionUpdate(msgTimestamp),
+			slack.MsgOptionAttachments(buildAttachment(title, status, time.Now())),
+		)
+
+		if status.isFinished() {
+			break
+		}
+	}
+
+	state := status.run.getState()
+
+	c.RemoveReaction(iconRunning, message)
+	if state == "success" {
+		c.AddReaction(iconSuccess, message)
+		runningCommand.DoneWithResult(queue.ResultSuccess)
+	} else {
+		c.AddReaction(iconFailed, message)
+		runningCommand.DoneWithResult(queue.ResultFailure)
+	}
+
+	c.SendMessage(message, fmt.Sprintf(
+		"<@%s> GitHub workflow run *%s*: %s %s in %s",
+		message.User,
+		state,
+		ref,
+		status.run.HTMLURL,
+		util.FormatDuration(status.run.getDuration(time.Now())),
+	))
+}
+
+func buildAttachment(title string, status statusInfo, now time.Time) slack.Attachment {
+	run := status.run
+	state := run.getState()
+
+	attachment := slack.Attachment{
+		Title:     title,
+		TitleLink: run.HTMLURL,
+		Color:     stateColor(state),
+	}
+
+	attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+		Title: "Workflow",
+		Value: run.Name,
+		Short: true,
+	})
+
+	attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+		Title: "Status",
+		Value: state,
+		Short: true,
+	})
+
+	if run.HeadBranch != "" {
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: "Ref",
+			Value: run.HeadBranch,
+			Short: true,
+		})
+	}
+
+	if duration := run.getDuration(now); duration > 0 {
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: "Duration",
+			Value: util.FormatDuration(duration),
+			Short: true,
+		})
+	}
+
+	if progress := util.RenderCountProgressBar(countDoneJobs(status.jobs), len(status.jobs)); progress != "" {
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: "Progress",
+			Value: progress,
+			Short: false,
+		})
+	}
+
+	if summary := buildJobSummary(status.jobs); summary != "" {
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: "Jobs",
+			Value: summary,
+			Short: false,
+		})
+	}
+
+	if details := formatNotableJobs(status.jobs); details != "" {
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: "Running/Failed Jobs",
+			Value: details,
+			Short: false,
+		})
+	}
+
+	attachment.Actions = []slack.AttachmentAction{
+		client.GetSlackLink("View in GitHub", run.HTMLURL),
+	}
+
+	return attachment
+}
+
+func stateColor(state string) string {
+	switch state {
+	case "success":
+		return colorSuccess
+	case "failure", "timed_out", "startup_failure":
+		return colorFailed
+	case "queued", "in_progress", "waiting", "requested", "pending":
+		return colorRunning
+	default:
+		return colorOther
+	}
+}
+
+// buildJobSummary returns the number of jobs per state, like "2 in_progress, 3 success"
+func buildJobSummary(jobs []*workflowJob) string {
+	counts := make(map[string]int)
+	for _, job := range jobs {
+		counts[job.getState()]++
+	}
+
+	states := make([]string, 0, len(counts))
+	for state := range counts {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+
+	parts := make([]string, 0, len(states))
+	for _, state := range states {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[state], state))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func countDoneJobs(jobs []*workflowJob) int {
+	done := 0
+	for _, job := range jobs {
+		if job.Status == "completed" {
+			done++
+		}
+	}
+
+	return done
+}
+
+// formatNotableJobs renders the running and failed jobs as a Slack mrkdwn list with links
+func formatNotableJobs(jobs []*workflowJob) string {
+	var lines []string
+	for _, job := range jobs {
+		switch job.getState() {
+		case "in_progress":
+			lines = append(lines, fmt.Sprintf(":arrow_forward: <%s|%s>", job.HTMLURL, job.Name))
+		case "failure", "timed_out":
+			lines = append(lines, fmt.Sprintf(":x: <%s|%s>", job.HTMLURL, job.Name))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (c *notifyCommand) GetHelp() []bot.Help {
+	return []bot.Help{
+		{
+			Command:     "github notify <workflow-run-url>",
+			Description: "watch a GitHub Actions workflow run and notify when it finishes",
+			Examples: []string{
+				"github notify " + c.host + "/innogames/slack-bot/actions/runs/12345",
+			},
+			Category: category,
+		},
+	}
+}
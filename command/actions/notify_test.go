@@ -0,0 +1,267 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockGithubAPI struct {
+	mock.Mock
+}
+
+func (m *mockGithubAPI) GetWorkflowRun(owner string, repo string, runID int64) (*workflowRun, error) {
+	args := m.Called(owner, repo, runID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*workflowRun), args.Error(1)
+}
+
+func (m *mockGithubAPI) ListWorkflowRunJobs(owner string, repo string, runID int64) ([]*workflowJob, error) {
+	args := m.Called(owner, repo, runID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*workflowJob), args.Error(1)
+}
+
+func (m *mockGithubAPI) ListWorkflowRuns(owner string, repo string, workflow string, ref string) ([]*workflowRun, error) {
+	args := m.Called(owner, repo, workflow, ref)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*workflowRun), args.Error(1)
+}
+
+func (m *mockGithubAPI) DispatchWorkflow(owner string, repo string, workflow string, ref string, inputs map[string]string) error {
+	args := m.Called(owner, repo, workflow, ref, inputs)
+	return args.Error(0)
+}
+
+func (m *mockGithubAPI) GetLogin() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func getTestCommands(t *testing.T, workflows config.GithubWorkflows) (*mocks.SlackClient, *mockGithubAPI, bot.Commands) {
+	t.Helper()
+
+	slackClient := mocks.NewSlackClient(t)
+	api := &mockGithubAPI{}
+
+	base := actionsCommand{
+		BaseCommand: bot.BaseCommand{SlackClient: slackClient},
+		api:         api,
+		host:        githubHost,
+		workflows:   workflows,
+	}
+
+	commands := bot.Commands{}
+	commands.AddCommand(
+		newNotifyCommand(base),
+		newWorkflowCommand(base),
+	)
+
+	return slackClient, api, commands
+}
+
+func mockFinishedRun(api *mockGithubAPI, runID int64) {
+	startedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	api.On("GetWorkflowRun", "innogames", "slack-bot", runID).Return(&workflowRun{
+		ID:           runID,
+		Name:         "Tests",
+		HeadBranch:   "main",
+		Status:       "completed",
+		Conclusion:   "success",
+		HTMLURL:      fmt.Sprintf("https://github.com/innogames/slack-bot/actions/runs/%d", runID),
+		RunStartedAt: startedAt,
+		UpdatedAt:    startedAt.Add(90 * time.Second),
+	}, nil)
+	api.On("ListWorkflowRunJobs", "innogames", "slack-bot", runID).Return([]*workflowJob{
+		{Name: "build", Status: "completed", Conclusion: "success"},
+		{Name: "test", Status: "completed", Conclusion: "success"},
+	}, nil)
+}
+
+func assertAlreadyFinished(slackClient *mocks.SlackClient, message msg.Message) {
+	slackClient.On("SendMessage", message, "", mock.MatchedBy(func(opt slack.MsgOption) bool {
+		attachment := mocks.GetAttachmentJSON(opt)
+		return strings.Contains(attachment, "already finished") && strings.Contains(attachment, "1m30s")
+	})).Once().Return("")
+}
+
+func TestNotify(t *testing.T) {
+	t.Run("no workflow run URL", func(t *testing.T) {
+		_, _, commands := getTestCommands(t, nil)
+
+		message := msg.Message{}
+		message.Text = "github notify https://github.com/innogames/slack-bot/pull/12"
+
+		assert.False(t, commands.Run(message))
+	})
+
+	t.Run("run already finished", func(t *testing.T) {
+		slackClient, api, commands := getTestCommands(t, nil)
+
+		message := msg.Message{}
+		message.Text = "github notify https://github.com/innogames/slack-bot/actions/runs/100/job/200"
+
+		mockFinishedRun(api, 100)
+		assertAlreadyFinished(slackClient, message)
+
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		slackClient, api, commands := getTestCommands(t, nil)
+
+		message := msg.Message{}
+		message.Text = "github notify https://github.com/innogames/slack-bot/actions/runs/101"
+
+		api.On("GetWorkflowRun", "innogames", "slack-bot", int64(101)).Return(nil, fmt.Errorf("not found"))
+		mocks.AssertError(slackClient, message, "error while fetching workflow run innogames/slack-bot#101: not found")
+
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("github enterprise", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		api := &mockGithubAPI{}
+
+		commands := bot.Commands{}
+		commands.AddCommand(newNotifyCommand(actionsCommand{
+			BaseCommand: bot.BaseCommand{SlackClient: slackClient},
+			api:         api,
+			host:        "https://github.example.com",
+		}))
+
+		message := msg.Message{}
+		message.Text = "github notify https://github.com/innogames/slack-bot/actions/runs/100"
+		assert.False(t, commands.Run(message))
+
+		message.Text = "github notify https://github.example.com/innogames/slack-bot/actions/runs/100"
+		mockFinishedRun(api, 100)
+		assertAlreadyFinished(slackClient, message)
+		assert.True(t, commands.Run(message))
+
+		help := commands.GetHelp()
+		require.Len(t, help, 1)
+		assert.Equal(t, []string{"github notify https://github.example.com/innogames/slack-bot/actions/runs/12345"}, help[0].Examples)
+	})
+
+	t.Run("help", func(t *testing.T) {
+		_, _, commands := getTestCommands(t, nil)
+
+		assert.Len(t, commands.GetHelp(), 1)
+	})
+}
+
+func TestRunState(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 5, 0, 0, time.UTC)
+
+	run := &workflowRun{Status: "in_progress", RunStartedAt: now.Add(-time.Minute)}
+	assert.Equal(t, "in_progress", run.getState())
+	assert.Equal(t, time.Minute, run.getDuration(now))
+
+	run.Status = "completed"
+	run.Conclusion = "failure"
+	run.UpdatedAt = now.Add(-30 * time.Second)
+	assert.Equal(t, "failure", run.getState())
+	assert.Equal(t, 30*time.Second, run.getDuration(now))
+
+	assert.Equal(t, time.Duration(0), (&workflowRun{}).getDuration(now))
+}
+
+func TestJobSummary(t *testing.T) {
+	jobs := []*workflowJob{
+		{Name: "build", Status: "completed", Conclusion: "success", HTMLURL: "https://github.com/job/1"},
+		{Name: "lint", Status: "completed", Conclusion: "failure", HTMLURL: "https://github.com/job/2"},
+		{Name: "test", Status: "in_progress", HTMLURL: "https://github.com/job/3"},
+		{Name: "deploy", Status: "queued", HTMLURL: "https://github.com/job/4"},
+	}
+
+	assert.Equal(t, "1 failure, 1 in_progress, 1 queued, 1 success", buildJobSummary(jobs))
+	assert.Equal(t, 2, countDoneJobs(jobs))
+	assert.Equal(t, ":x: <https://github.com/job/2|lint>\n:arrow_forward: <https://github.com/job/3|test>", formatNotableJobs(jobs))
+
+	assert.Empty(t, buildJobSummary(nil))
+	assert.Empty(t, formatNotableJobs(nil))
+}
+
+func TestStateColor(t *testing.T) {
+	assert.Equal(t, colorSuccess, stateColor("success"))
+	assert.Equal(t, colorFailed, stateColor("failure"))
+	assert.Equal(t, colorRunning, stateColor("in_progress"))
+	assert.Equal(t, colorOther, stateColor("cancelled"))
+}
+
+func TestRealGithubAPI(t *testing.T) {
+	var dispatched map[string]any
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/innogames/slack-bot/actions/runs/12", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"id": 12, "name": "Tests", "status": "completed", "conclusion": "success", "html_url": "https://github.com/innogames/slack-bot/actions/runs/12"}`)
+	})
+	mux.HandleFunc("/repos/innogames/slack-bot/actions/runs/12/jobs", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"total_count": 1, "jobs": [{"id": 1, "name": "build", "status": "in_progress"}]}`)
+	})
+	mux.HandleFunc("/repos/innogames/slack-bot/actions/workflows/deploy.yml/runs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "main", r.URL.Query().Get("branch"))
+		assert.Equal(t, "workflow_dispatch", r.URL.Query().Get("event"))
+		fmt.Fprint(w, `{"workflow_runs": [{"id": 13, "created_at": "2024-01-01T10:00:00Z", "actor": {"login": "slack-bot"}}]}`)
+	})
+	mux.HandleFunc("POST /repos/innogames/slack-bot/actions/workflows/deploy.yml/dispatches", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &dispatched)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"login": "slack-bot"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	githubClient, err := github.NewEnterpriseClient(server.URL+"/", server.URL+"/", server.Client())
+	require.NoError(t, err)
+	api := &realGithubAPI{client: githubClient}
+
+	run, err := api.GetWorkflowRun("innogames", "slack-bot", 12)
+	require.NoError(t, err)
+	assert.Equal(t, "success", run.getState())
+
+	jobs, err := api.ListWorkflowRunJobs("innogames", "slack-bot", 12)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "build", jobs[0].Name)
+
+	runs, err := api.ListWorkflowRuns("innogames", "slack-bot", "deploy.yml", "main")
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, int64(13), runs[0].ID)
+	assert.Equal(t, "slack-bot", runs[0].Actor.Login)
+
+	err = api.DispatchWorkflow("innogames", "slack-bot", "deploy.yml", "main", map[string]string{"environment": "prod"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"ref": "main", "inputs": map[string]any{"environment": "prod"}}, dispatched)
+
+	login, err := api.GetLogin()
+	require.NoError(t, err)
+	assert.Equal(t, "slack-bot", login)
+}
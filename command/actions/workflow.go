@@ -0,0 +1,218 @@
+package actions
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/command/queue"
+	log "github.com/sirupsen/logrus"
+)
+
+// the dispatch API returns no run ID: we have to look for the new run for a few times
+const runLookupAttempts = 5
+
+var runLookupInterval = 2 * time.Second
+
+type workflowCommand struct {
+	actionsCommand
+}
+
+// newWorkflowCommand starts whitelisted workflows via "workflow_dispatch" and watches the new run
+func newWorkflowCommand(base actionsCommand) bot.Command {
+	return &workflowCommand{base}
+}
+
+func (c *workflowCommand) IsEnabled() bool {
+	return len(c.workflows) > 0
+}
+
+func (c *workflowCommand) GetMatcher() matcher.Matcher {
+	return matcher.NewRegexpMatcher(
+		`github run workflow (?P<owner>[\w.-]+)/(?P<repo>[\w.-]+) (?P<workflow>\S+) (?P<ref>\S+)(?P<inputs>.*)`,
+		c.run,
+	)
+}
+
+func (c *workflowCommand) run(match matcher.Result, message msg.Message) {
+	owner := match.GetString("owner")
+	repo := match.GetString("repo")
+	workflow := match.GetString("workflow")
+	ref := match.GetString("ref")
+
+	workflowConfig, ok := c.workflows.Get(owner+"/"+repo, workflow)
+	if !ok {
+		c.ReplyError(message, fmt.Errorf("workflow %s of %s/%s is not whitelisted", workflow, owner, repo))
+		return
+	}
+
+	if !workflowConfig.IsAllowedRef(ref) {
+		c.ReplyError(message, fmt.Errorf("ref %s is not allowed for workflow %s", ref, workflow))
+		return
+	}
+
+	inputs, err := parseInputs(match.GetString("inputs"))
+	if err != nil {
+		c.ReplyError(message, err)
+		return
+	}
+	for name := range inputs {
+		if !workflowConfig.IsAllowedInput(name) {
+			c.ReplyError(message, fmt.Errorf("input %s is not allowed for workflow %s", name, workflow))
+			return
+		}
+	}
+
+	// runs which existed before our dispatch can't be the dispatched one
+	knownRuns := make(map[int64]bool)
+	if runs, err := c.api.ListWorkflowRuns(owner, repo, workflow, ref); err == nil {
+		for _, run := range runs {
+			knownRuns[run.ID] = true
+		}
+	}
+
+	actor, err := c.api.GetLogin()
+	if err != nil {
+		log.Warnf("Error while loading the GitHub user: %s", err)
+	}
+
+	dispatchedAt := time.Now()
+	if err = c.api.DispatchWorkflow(owner, repo, workflow, ref, inputs); err != nil {
+		c.ReplyError(message, fmt.Errorf("error while starting workflow %s: %w", workflow, err))
+		return
+	}
+	log.Infof("GitHub workflow %s of %s/%s started by %s", workflow, owner, repo, message.GetUser())
+
+	// the lookup of the new run takes some seconds: "then" commands wait for the lookup and the watcher
+	lookupCommand := queue.AddRunningCommand(message, "")
+
+	go func() {
+		run := c.findDispatchedRun(owner, repo, workflow, ref, actor, dispatchedAt, knownRuns)
+		if run == nil {
+			c.SendMessage(message, fmt.Sprintf(
+				"Workflow *%s* of %s/%s got started, but the run was not found yet: %s/%s/%s/actions/workflows/%s",
+				workflow,
+				owner,
+				repo,
+				c.host,
+				owner,
+				repo,
+				workflow,
+			))
+			lookupCommand.DoneWithResult(queue.ResultFailure)
+			return
+		}
+
+		c.startWatch(message, runRef{owner: owner, repo: repo, id: run.ID})
+
+		// startWatch registered a new running command for the watcher: chain our lookup to the result of the run
+		watchCommand := queue.GetRunningCommand(message.GetUniqueKey())
+		if watchCommand != nil && watchCommand != lookupCommand {
+			lookupCommand.DoneWithResult(watchCommand.WaitForResult())
+		} else {
+			lookupCommand.Done()
+		}
+	}()
+}
+
+// findDispatchedRun returns the run which was created by our dispatch, nil if it was not found in time.
+// The run has to be started by our GitHub user for the given ref, after the dispatch and must not be watched already.
+func (c *workflowCommand) findDispatchedRun(
+	owner string,
+	repo string,
+	workflow string,
+	ref string,
+	actor string,
+	dispatchedAt time.Time,
+	knownRuns map[int64]bool,
+) *workflowRun {
+	// tolerate a small clock difference between the bot and GitHub
+	since := dispatchedAt.Add(-10 * time.Second)
+
+	for range runLookupAttempts {
+		time.Sleep(runLookupInterval)
+
+		runs, err := c.api.ListWorkflowRuns(owner, repo, workflow, ref)
+		if err != nil {
+			log.Warnf("Error while loading runs of GitHub workflow %s: %s", workflow, err)
+			continue
+		}
+
+		// the runs are sorted by creation date, the newest first: the oldest new run is the one of our dispatch
+		for _, run := range slices.Backward(runs) {
+			if knownRuns[run.ID] ||
+				!run.CreatedAt.After(since) ||
+				(run.HeadBranch != "" && run.HeadBranch != ref) ||
+				(actor != "" && !strings.EqualFold(run.Actor.Login, actor)) {
+				continue
+			}
+
+			if claimRun(run.ID) {
+				return run
+			}
+		}
+	}
+
+	return nil
+}
+
+// IDs of the dispatched runs which are already assigned to a dispatch, so parallel dispatches don't watch the same run
+var (
+	claimedRuns   = make(map[int64]bool)
+	claimedRunsMu sync.Mutex
+)
+
+// claimRun returns false if the run was already claimed by a different dispatch
+func claimRun(runID int64) bool {
+	claimedRunsMu.Lock()
+	defer claimedRunsMu.Unlock()
+
+	if claimedRuns[runID] {
+		return false
+	}
+	claimedRuns[runID] = true
+
+	return true
+}
+
+// parseInputs parses workflow inputs like "environment=prod debug=true"
+func parseInputs(text string) (map[string]string, error) {
+	inputs := make(map[string]string)
+	for _, field := range strings.Fields(text) {
+		name, value, found := strings.Cut(field, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid input %s, use name=value", field)
+		}
+		inputs[name] = value
+	}
+
+	return inputs, nil
+}
+
+func (c *workflowCommand) GetHelp() []bot.Help {
+	workflows := make([]string, 0, len(c.workflows))
+	for _, workflow := range c.workflows {
+		workflows = append(workflows, workflow.Repo+" "+workflow.Workflow)
+	}
+	sort.Strings(workflows)
+
+	examples := make([]string, 0, 1)
+	if len(workflows) > 0 {
+		examples = append(examples, "github run workflow "+workflows[0]+" main")
+	}
+
+	return []bot.Help{
+		{
+			Command:     "github run workflow <owner/repo> <workflow> <ref> [input=value...]",
+			Description: "starts a whitelisted GitHub Actions workflow and watches the run till it's finished. Allowed workflows: " + strings.Join(workflows, ", "),
+			Examples:    examples,
+			Category:    category,
+		},
+	}
+}
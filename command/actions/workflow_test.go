@@ -0,0 +1,166 @@
+package actions
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/command/queue"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow(t *testing.T) {
+	runLookupInterval = 0
+
+	workflows := config.GithubWorkflows{
+		{
+			Repo:     "innogames/slack-bot",
+			Workflow: "deploy.yml",
+			Refs:     []string{"main"},
+			Inputs:   []string{"environment"},
+		},
+	}
+
+	t.Run("not enabled without workflows", func(t *testing.T) {
+		cmd := newWorkflowCommand(actionsCommand{}).(*workflowCommand)
+		assert.False(t, cmd.IsEnabled())
+	})
+
+	t.Run("workflow not whitelisted", func(t *testing.T) {
+		slackClient, _, commands := getTestCommands(t, workflows)
+
+		message := msg.Message{}
+		message.Text = "github run workflow innogames/slack-bot tests.yml main"
+
+		mocks.AssertError(slackClient, message, "workflow tests.yml of innogames/slack-bot is not whitelisted")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("ref not allowed", func(t *testing.T) {
+		slackClient, _, commands := getTestCommands(t, workflows)
+
+		message := msg.Message{}
+		message.Text = "github run workflow innogames/slack-bot deploy.yml feature"
+
+		mocks.AssertError(slackClient, message, "ref feature is not allowed for workflow deploy.yml")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("input not allowed", func(t *testing.T) {
+		slackClient, _, commands := getTestCommands(t, workflows)
+
+		message := msg.Message{}
+		message.Text = "github run workflow innogames/slack-bot deploy.yml main token=secret"
+
+		mocks.AssertError(slackClient, message, "input token is not allowed for workflow deploy.yml")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("dispatch failed", func(t *testing.T) {
+		slackClient, api, commands := getTestCommands(t, workflows)
+
+		message := msg.Message{}
+		message.Text = "github run workflow innogames/slack-bot deploy.yml main"
+
+		api.On("ListWorkflowRuns", "innogames", "slack-bot", "deploy.yml", "main").Return(nil, nil).Once()
+		api.On("GetLogin").Return("slack-bot", nil)
+		api.On("DispatchWorkflow", "innogames", "slack-bot", "deploy.yml", "main", map[string]string{}).Return(errors.New("forbidden"))
+		mocks.AssertError(slackClient, message, "error while starting workflow deploy.yml: forbidden")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("run not found", func(t *testing.T) {
+		slackClient, api, commands := getTestCommands(t, workflows)
+
+		message := msg.Message{}
+		message.Text = "github run workflow innogames/slack-bot deploy.yml main"
+
+		api.On("GetLogin").Return("slack-bot", nil)
+		api.On("DispatchWorkflow", "innogames", "slack-bot", "deploy.yml", "main", map[string]string{}).Return(nil)
+		api.On("ListWorkflowRuns", "innogames", "slack-bot", "deploy.yml", "main").Return([]*workflowRun{
+			{ID: 1, CreatedAt: time.Now().Add(-time.Hour)},
+		}, nil).Times(runLookupAttempts + 1)
+		mocks.AssertSlackMessage(
+			slackClient,
+			message,
+			"Workflow *deploy.yml* of innogames/slack-bot got started, but the run was not found yet: https://github.com/innogames/slack-bot/actions/workflows/deploy.yml",
+		)
+		assert.True(t, commands.Run(message))
+
+		// the run is looked up in the background
+		lookupCommand := queue.GetRunningCommand(message.GetUniqueKey())
+		require.NotNil(t, lookupCommand)
+		assert.Equal(t, queue.ResultFailure, lookupCommand.WaitForResult())
+		api.AssertExpectations(t)
+	})
+
+	t.Run("run workflow", func(t *testing.T) {
+		slackClient, api, commands := getTestCommands(t, workflows)
+
+		message := msg.Message{}
+		message.Text = "github run workflow Innogames/Slack-Bot deploy.yml main environment=prod"
+
+		newRun := &workflowRun{ID: 100, HeadBranch: "main", CreatedAt: time.Now()}
+		newRun.Actor.Login = "slack-bot"
+		otherRun := &workflowRun{ID: 98, HeadBranch: "main", CreatedAt: time.Now()}
+		otherRun.Actor.Login = "alice"
+		knownRun := &workflowRun{ID: 99, HeadBranch: "main", CreatedAt: time.Now()}
+		knownRun.Actor.Login = "slack-bot"
+
+		api.On("GetLogin").Return("slack-bot", nil)
+		api.On("ListWorkflowRuns", "Innogames", "Slack-Bot", "deploy.yml", "main").Return([]*workflowRun{knownRun}, nil).Once()
+		api.On("DispatchWorkflow", "Innogames", "Slack-Bot", "deploy.yml", "main", map[string]string{"environment": "prod"}).Return(nil)
+		api.On("ListWorkflowRuns", "Innogames", "Slack-Bot", "deploy.yml", "main").Return(nil, errors.New("timeout")).Once()
+
+		// runs of other users and runs which existed before the dispatch are ignored
+		api.On("ListWorkflowRuns", "Innogames", "Slack-Bot", "deploy.yml", "main").Return([]*workflowRun{
+			newRun,
+			otherRun,
+			knownRun,
+		}, nil).Once()
+
+		// the repo casing of the user is kept for the API calls
+		startedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		api.On("GetWorkflowRun", "Innogames", "Slack-Bot", int64(100)).Return(&workflowRun{
+			ID:           100,
+			Status:       "completed",
+			Conclusion:   "success",
+			RunStartedAt: startedAt,
+			UpdatedAt:    startedAt.Add(90 * time.Second),
+		}, nil)
+		api.On("ListWorkflowRunJobs", "Innogames", "Slack-Bot", int64(100)).Return([]*workflowJob{}, nil)
+		assertAlreadyFinished(slackClient, message)
+
+		assert.True(t, commands.Run(message))
+
+		lookupCommand := queue.GetRunningCommand(message.GetUniqueKey())
+		require.NotNil(t, lookupCommand)
+		assert.Equal(t, queue.ResultSuccess, lookupCommand.WaitForResult())
+		api.AssertExpectations(t)
+
+		// a run can only be watched by one dispatch
+		assert.False(t, claimRun(100))
+		assert.True(t, claimRun(101))
+	})
+
+	t.Run("help", func(t *testing.T) {
+		_, _, commands := getTestCommands(t, workflows)
+
+		help := commands.GetHelp()
+		assert.Len(t, help, 2)
+		assert.Equal(t, []string{"github run workflow innogames/slack-bot deploy.yml main"}, help[1].Examples)
+	})
+}
+
+func TestParseInputs(t *testing.T) {
+	inputs, err := parseInputs(" environment=prod  debug=")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"environment": "prod", "debug": ""}, inputs)
+
+	_, err = parseInputs("environment")
+	assert.EqualError(t, err, "invalid input environment, use name=value")
+}
@@ -0,0 +1,165 @@
+// Package approval provides the shared approval flow of commands which need a confirmation of the user before
+// starting an action, like a Jenkins job or a GitLab pipeline.
+package approval
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/innogames/slack-bot/v2/command/queue"
+	"github.com/slack-go/slack"
+)
+
+// Request is an action which is waiting for an approval
+type Request[T any] struct {
+	ID      string
+	Data    T
+	Message msg.Message // original message for posting results back to the original channel
+
+	expiresAt      time.Time
+	runningCommand *queue.RunningCommand
+	doneOnce       sync.Once
+}
+
+// MarkDone releases the running command exactly once, unblocking any chained "then" commands
+func (r *Request[T]) MarkDone(result queue.Result) {
+	r.doneOnce.Do(func() {
+		if r.runningCommand != nil {
+			r.runningCommand.DoneWithResult(result)
+		}
+	})
+}
+
+// ChainResult is called after the approved action got started: when the action registered a new running command
+// (like the watcher of a Jenkins build), the request is done when the action is finished. So "then" commands typed
+// before the approval still wait for the full action to finish, not just for the approval to be granted.
+func (r *Request[T]) ChainResult() {
+	actionCmd := queue.GetRunningCommand(r.Message.GetUniqueKey())
+	if actionCmd == nil || actionCmd == r.runningCommand {
+		r.MarkDone(queue.ResultSuccess)
+		return
+	}
+
+	go func() {
+		r.MarkDone(actionCmd.WaitForResult())
+	}()
+}
+
+// Store holds the pending requests until they got approved, rejected or expired
+type Store[T any] struct {
+	mu      sync.Mutex
+	pending map[string]*Request[T]
+	timeout time.Duration
+}
+
+// NewStore creates a store whose requests expire after the given timeout
+func NewStore[T any](timeout time.Duration) *Store[T] {
+	return &Store[T]{
+		pending: make(map[string]*Request[T]),
+		timeout: timeout,
+	}
+}
+
+// Request creates a pending request and sends a DM with approve/reject buttons to the user.
+// The buttons execute "<commandPrefix> approve <id>" and "<commandPrefix> reject <id>".
+func (s *Store[T]) Request(base bot.BaseCommand, message msg.Message, data T, description string, commandPrefix string) *Request[T] {
+	request := &Request[T]{
+		ID:             generateID(),
+		Data:           data,
+		Message:        message,
+		expiresAt:      time.Now().Add(s.timeout),
+		runningCommand: queue.AddRunningCommand(message, ""),
+	}
+
+	s.mu.Lock()
+	s.pending[request.ID] = request
+	s.mu.Unlock()
+
+	blocks := []slack.Block{
+		client.GetTextBlock(":warning: *Approval Required*"),
+		client.GetTextBlock(description),
+		client.GetContextBlock(fmt.Sprintf("This approval expires in %s.", util.FormatDuration(s.timeout))),
+		slack.NewActionBlock(
+			"",
+			client.GetInteractionButton("approve", "Approve", commandPrefix+" approve "+request.ID, slack.StylePrimary),
+			client.GetInteractionButton("reject", "Reject", commandPrefix+" reject "+request.ID, slack.StyleDanger),
+		),
+	}
+	base.SendBlockMessageToUser(message.GetUser(), blocks)
+
+	return request
+}
+
+// Take removes and returns a pending request by ID. Returns nil if not found or expired.
+func (s *Store[T]) Take(id string) *Request[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	request, ok := s.pending[id]
+	if !ok {
+		return nil
+	}
+	delete(s.pending, id)
+
+	if time.Now().After(request.expiresAt) {
+		request.MarkDone(queue.ResultFailure)
+		return nil
+	}
+
+	return request
+}
+
+// GetIDs returns the IDs of all pending requests
+func (s *Store[T]) GetIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Sorted(maps.Keys(s.pending))
+}
+
+// Cleanup removes the expired requests and releases their running commands
+func (s *Store[T]) Cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, request := range s.pending {
+		if now.After(request.expiresAt) {
+			delete(s.pending, id)
+			request.MarkDone(queue.ResultFailure)
+		}
+	}
+}
+
+// RunCleanup periodically cleans up the expired requests, used in RunAsync of the commands
+func (s *Store[T]) RunCleanup(ctx *util.ServerContext) {
+	ctx.RegisterChild()
+	defer ctx.ChildDone()
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Cleanup()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func generateID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
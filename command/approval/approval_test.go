@@ -0,0 +1,80 @@
+package approval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/command/queue"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	slackClient := mocks.NewSlackClient(t)
+	base := bot.BaseCommand{SlackClient: slackClient}
+
+	store := NewStore[string](5 * time.Minute)
+
+	t.Run("request and take", func(t *testing.T) {
+		message := msg.Message{}
+		message.User = "U1"
+		message.Channel = "C1"
+		message.Timestamp = "1234.0001"
+
+		slackClient.On("SendBlockMessageToUser", "U1", mock.AnythingOfType("[]slack.Block")).Once().Return("")
+		request := store.Request(base, message, "TestJob", "please approve", "jenkins")
+		assert.Len(t, request.ID, 8)
+		assert.Equal(t, []string{request.ID}, store.GetIDs())
+
+		runningCommand := queue.GetRunningCommand(message.GetUniqueKey())
+		require.NotNil(t, runningCommand)
+
+		got := store.Take(request.ID)
+		require.NotNil(t, got)
+		assert.Equal(t, "TestJob", got.Data)
+
+		// can only be taken once
+		assert.Nil(t, store.Take(request.ID))
+		assert.Empty(t, store.GetIDs())
+
+		got.ChainResult()
+		assert.Equal(t, queue.ResultSuccess, runningCommand.WaitForResult())
+	})
+
+	t.Run("take non-existent", func(t *testing.T) {
+		assert.Nil(t, store.Take("doesnotexist"))
+	})
+
+	t.Run("take expired", func(t *testing.T) {
+		store.pending["expired1"] = &Request[string]{
+			ID:        "expired1",
+			expiresAt: time.Now().Add(-1 * time.Minute),
+		}
+
+		assert.Nil(t, store.Take("expired1"))
+	})
+
+	t.Run("cleanup removes expired", func(t *testing.T) {
+		cleanStore := NewStore[string](time.Minute)
+		cleanStore.pending["valid1"] = &Request[string]{ID: "valid1", expiresAt: time.Now().Add(5 * time.Minute)}
+		cleanStore.pending["expired2"] = &Request[string]{ID: "expired2", expiresAt: time.Now().Add(-1 * time.Minute)}
+		cleanStore.pending["expired3"] = &Request[string]{ID: "expired3", expiresAt: time.Now().Add(-5 * time.Minute)}
+
+		cleanStore.Cleanup()
+
+		assert.Equal(t, []string{"valid1"}, cleanStore.GetIDs())
+	})
+}
+
+func TestGenerateID(t *testing.T) {
+	id1 := generateID()
+	id2 := generateID()
+
+	assert.Len(t, id1, 8)
+	assert.Len(t, id2, 8)
+	assert.NotEqual(t, id1, id2)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/innogames/slack-bot/v2/bot"
 	"github.com/innogames/slack-bot/v2/bot/config"
 	"github.com/innogames/slack-bot/v2/client"
+	"github.com/innogames/slack-bot/v2/command/actions"
 	"github.com/innogames/slack-bot/v2/command/admin"
 	"github.com/innogames/slack-bot/v2/command/clouds/aws"
 	"github.com/innogames/slack-bot/v2/command/cron"
@@ -24,7 +25,8 @@ import (
 
 // GetCommands returns the list of default command which are available
 func GetCommands(slackClient client.SlackClient, cfg config.Config) *bot.Commands {
-	base := bot.BaseCommand{SlackClient: slackClient}
+	// the output of the commands which are executed as openai tools is captured, all other messages are sent to Slack
+	base := bot.BaseCommand{SlackClient: openai.NewOutputCapture(slackClient)}
 
 	commands := &bot.Commands{}
 	commands.AddCommand(
@@ -58,7 +60,7 @@ func GetCommands(slackClient client.SlackClient, cfg config.Config) *bot.Command
 	commands.Merge(admin.GetCommands(base, &cfg))
 
 	// jira
-	commands.Merge(jira.GetCommands(&cfg.Jira, slackClient))
+	commands.Merge(jira.GetCommands(&cfg.Jira, base.SlackClient))
 
 	// jenkins
 	commands.Merge(jenkins.GetCommands(cfg.Jenkins, base))
@@ -69,14 +71,17 @@ func GetCommands(slackClient client.SlackClient, cfg config.Config) *bot.Command
 	// gitlab pipeline/job watcher
 	commands.Merge(gitlabcmd.GetCommands(base, &cfg))
 
+	// GitHub Actions workflow watcher
+	commands.Merge(actions.GetCommands(base, &cfg))
+
 	// aws
 	commands.Merge(aws.GetCommands(cfg.Aws, base))
 
 	// pool
-	commands.Merge(pool.GetCommands(&cfg.Pool, base))
+	commands.Merge(pool.GetCommands(&cfg, base))
 
-	// openai/chatgpt
-	commands.Merge(openai.GetCommands(base, &cfg))
+	// openai/chatgpt: tools are executed on the same commands, to share their state (like locked pool resources)
+	commands.Merge(openai.GetCommands(base, &cfg, commands))
 
 	// vcs branch watcher
 	commands.Merge(vcs.GetCommands(base, &cfg))
@@ -31,10 +31,12 @@ func GetCommands(base bot.BaseCommand, cfg *config.Config) bot.Commands {
 		BaseCommand: base,
 		api:         &realGitlabAPI{client: gitlabClient},
 		host:        cfg.Gitlab.Host,
+		pipelines:   cfg.Gitlab.Pipelines,
 	}
 
 	commands.AddCommand(
 		newNotifyCommand(baseCmd),
+		newPipelineCommand(baseCmd, cfg.Gitlab.GetApprovalTimeout()),
 	)
 
 	return commands
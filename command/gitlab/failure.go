@@ -0,0 +1,142 @@
+package gitlab
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/client"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// number of trace lines which are attached for each failed job
+	traceLines = 50
+
+	// max length of a single trace line, e.g. for minified output
+	maxTraceLineLength = 500
+
+	// max number of bytes which are loaded from the end of a job trace
+	maxTraceSize = 256 * 1024
+)
+
+// matches ANSI escape sequences (colors, cursor movement) and the GitLab section markers of the job trace
+var (
+	ansiRe    = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+	sectionRe = regexp.MustCompile(`section_(start|end):\d+:[^\r\n]*?\r`)
+)
+
+// postFailureDetails attaches the tail of the log of each failed job to the thread and offers buttons to retry the jobs
+func (c *gitlabCommand) postFailureDetails(message msg.Message, parsed parsedURL, status statusInfo) {
+	thread := message.GetThread()
+	if thread == "" {
+		thread = message.GetTimestamp()
+	}
+
+	buttons := make([]slack.BlockElement, 0)
+	for _, job := range status.jobDetails {
+		if job.status != "failed" {
+			continue
+		}
+
+		trace, err := c.api.GetJobTrace(parsed.project, job.id)
+		if err != nil {
+			log.Warnf("Error fetching trace of GitLab job %s#%d: %s", parsed.project, job.id, err)
+		} else if trace = tailTrace(trace, traceLines); trace != "" {
+			_, err = c.UploadFile(slack.UploadFileParameters{
+				Filename:        job.name + ".log",
+				Title:           fmt.Sprintf("Last %d lines of job %s", traceLines, job.name),
+				Content:         trace,
+				SnippetType:     "text",
+				FileSize:        len(trace),
+				Channel:         message.GetChannel(),
+				ThreadTimestamp: thread,
+				InitialComment:  formatFailedJob(job),
+			})
+			if err != nil {
+				log.Warnf("Error uploading trace of GitLab job %s#%d: %s", parsed.project, job.id, err)
+			}
+		}
+
+		buttons = append(buttons, client.GetInteractionButton("retry", "Retry job "+job.name, "gitlab retry "+job.webURL))
+	}
+
+	// retrying is only possible for the whitelisted projects
+	if _, ok := c.pipelines.Get(parsed.project); !ok || len(buttons) == 0 {
+		return
+	}
+
+	c.SendBlockMessage(
+		message,
+		[]slack.Block{slack.NewActionBlock("", buttons...)},
+		slack.MsgOptionTS(thread),
+	)
+}
+
+// formatFailedJob renders the failed job with its failure reason as Slack mrkdwn
+func formatFailedJob(job jobDetail) string {
+	text := fmt.Sprintf(":x: <%s|%s> (%s)", job.webURL, job.name, job.stage)
+	if job.failureReason != "" {
+		text += ": " + strings.ReplaceAll(job.failureReason, "_", " ")
+	}
+
+	return text
+}
+
+// tailTrace returns the last lines of the job trace, without ANSI codes and GitLab section markers
+func tailTrace(trace string, lines int) string {
+	trace = sectionRe.ReplaceAllString(trace, "")
+	trace = ansiRe.ReplaceAllString(trace, "")
+	trace = strings.ReplaceAll(trace, "\r\n", "\n")
+
+	result := strings.Split(strings.TrimRight(trace, "\n\r "), "\n")
+	if len(result) > lines {
+		result = result[len(result)-lines:]
+	}
+
+	for i, line := range result {
+		// progress bars are using carriage returns: only the last state is relevant
+		if pos := strings.LastIndex(line, "\r"); pos >= 0 {
+			line = line[pos+1:]
+		}
+		if len(line) > maxTraceLineLength {
+			// don't cut within a multibyte character
+			line = strings.ToValidUTF8(line[:maxTraceLineLength], "") + "..."
+		}
+		result[i] = line
+	}
+
+	return strings.TrimSpace(strings.Join(result, "\n"))
+}
+
+// tailWriter keeps only the last bytes which got written into it
+type tailWriter struct {
+	limit     int
+	buf       []byte
+	truncated bool
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	if over := len(w.buf) - w.limit; over > 0 {
+		w.buf = append(w.buf[:0], w.buf[over:]...)
+		w.truncated = true
+	}
+
+	return len(p), nil
+}
+
+// String returns the written data, without the cut off first line when older data got dropped
+func (w *tailWriter) String() string {
+	data := w.buf
+	if w.truncated {
+		if pos := bytes.IndexByte(data, '\n'); pos >= 0 {
+			data = data[pos+1:]
+		}
+	}
+
+	return string(data)
+}
@@ -0,0 +1,130 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestTailTrace(t *testing.T) {
+	t.Run("strip ANSI codes and sections", func(t *testing.T) {
+		trace := "section_start:1560896352:step_script\r\x1b[0K\x1b[32;1m$ make test\x1b[0;m\n" +
+			"Downloading 10%\rDownloading 100%\r\n" +
+			"\x1b[31;1mERROR: tests failed\x1b[0;m\n" +
+			"section_end:1560896353:step_script\r\x1b[0K\n"
+
+		assert.Equal(t, "$ make test\nDownloading 100%\nERROR: tests failed", tailTrace(trace, 10))
+	})
+
+	t.Run("only last lines", func(t *testing.T) {
+		var trace strings.Builder
+		for i := 1; i <= 100; i++ {
+			fmt.Fprintf(&trace, "line %d\n", i)
+		}
+
+		assert.Equal(t, "line 98\nline 99\nline 100", tailTrace(trace.String(), 3))
+	})
+
+	t.Run("truncate long lines", func(t *testing.T) {
+		actual := tailTrace(strings.Repeat("x", 1000), 3)
+		assert.Equal(t, strings.Repeat("x", maxTraceLineLength)+"...", actual)
+
+		actual = tailTrace("x"+strings.Repeat("ä", 1000), 3)
+		assert.Equal(t, "x"+strings.Repeat("ä", maxTraceLineLength/2-1)+"...", actual)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, tailTrace("", 10))
+	})
+}
+
+func TestGetJobTrace(t *testing.T) {
+	t.Run("tail writer", func(t *testing.T) {
+		writer := &tailWriter{limit: 10}
+		_, _ = writer.Write([]byte("line 1\n"))
+		assert.Equal(t, "line 1\n", writer.String())
+
+		// the cut off first line is dropped
+		_, _ = writer.Write([]byte("line 2\nline 3\n"))
+		assert.Equal(t, "line 3\n", writer.String())
+	})
+
+	t.Run("only the end of the trace is loaded", func(t *testing.T) {
+		var trace strings.Builder
+		for i := 1; i <= 100000; i++ {
+			fmt.Fprintf(&trace, "line %d\n", i)
+		}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v4/projects/group%2Fproject/jobs/3/trace", r.URL.EscapedPath())
+			fmt.Fprint(w, trace.String())
+		}))
+		defer server.Close()
+
+		gitlabClient, err := gitlab.NewClient("secret", gitlab.WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		api := &realGitlabAPI{client: gitlabClient}
+		actual, err := api.GetJobTrace("group/project", 3)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(actual), maxTraceSize)
+		assert.True(t, strings.HasSuffix(actual, "line 99999\nline 100000\n"))
+		assert.True(t, strings.HasPrefix(actual, "line "))
+	})
+}
+
+func TestFormatFailedJob(t *testing.T) {
+	job := jobDetail{name: "lint", stage: "test", status: "failed", webURL: "https://gitlab.example.com/jobs/3"}
+	assert.Equal(t, ":x: <https://gitlab.example.com/jobs/3|lint> (test)", formatFailedJob(job))
+
+	job.failureReason = "script_failure"
+	assert.Equal(t, ":x: <https://gitlab.example.com/jobs/3|lint> (test): script failure", formatFailedJob(job))
+}
+
+func TestPostFailureDetails(t *testing.T) {
+	slackClient, api, cmd, _ := getTestPipelineCommand(t)
+
+	message := msg.Message{}
+	message.Channel = "C123"
+	message.Timestamp = "1234.5678"
+
+	status := statusInfo{
+		state: "failed",
+		jobDetails: []jobDetail{
+			{id: 2, name: "test", stage: "test", status: "running", webURL: "https://gitlab.example.com/group/project/-/jobs/2"},
+			{id: 3, name: "lint", stage: "test", status: "failed", webURL: "https://gitlab.example.com/group/project/-/jobs/3", failureReason: "script_failure"},
+		},
+	}
+
+	api.On("GetJobTrace", "group/project", int64(3)).Return("\x1b[31;1mERROR: lint failed\x1b[0;m\n", nil).Once()
+	slackClient.On("UploadFile", mock.MatchedBy(func(params slack.UploadFileParameters) bool {
+		return params.Filename == "lint.log" &&
+			params.Content == "ERROR: lint failed" &&
+			params.Channel == "C123" &&
+			params.ThreadTimestamp == "1234.5678" &&
+			params.InitialComment == ":x: <https://gitlab.example.com/group/project/-/jobs/3|lint> (test): script failure"
+	})).Once().Return(nil, nil)
+	slackClient.On("SendBlockMessage", message, mock.MatchedBy(func(blocks []slack.Block) bool {
+		return strings.Contains(toJSON(blocks), "gitlab retry https://gitlab.example.com/group/project/-/jobs/3")
+	}), mock.AnythingOfType("slack.MsgOption")).Once().Return("")
+
+	cmd.postFailureDetails(message, parsedURL{project: "group/project", kind: urlTypePipeline, id: 1}, status)
+	api.AssertExpectations(t)
+
+	t.Run("not whitelisted project", func(t *testing.T) {
+		api.On("GetJobTrace", "group/other", int64(3)).Return("", nil).Once()
+
+		// no upload of empty traces and no retry buttons
+		cmd.postFailureDetails(message, parsedURL{project: "group/other", kind: urlTypePipeline, id: 1}, status)
+		api.AssertExpectations(t)
+	})
+}
@@ -3,12 +3,14 @@ package gitlab
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
 	"github.com/innogames/slack-bot/v2/bot/matcher"
 	"github.com/innogames/slack-bot/v2/bot/msg"
 	"github.com/innogames/slack-bot/v2/bot/util"
@@ -37,11 +39,16 @@ type urlType int
 const (
 	urlTypePipeline urlType = iota
 	urlTypeJob
+	urlTypeMergeRequest
 )
 
 func (t urlType) String() string {
-	if t == urlTypeJob {
+	switch t {
+	case urlTypeJob:
 		return "job"
+	case urlTypeMergeRequest:
+		return "merge request"
+	case urlTypePipeline:
 	}
 	return "pipeline"
 }
@@ -57,6 +64,14 @@ type gitlabAPI interface {
 	GetPipeline(pid any, pipeline int64) (*gitlab.Pipeline, error)
 	ListPipelineJobs(pid any, pipeline int64) ([]*gitlab.Job, error)
 	GetJob(pid any, jobID int64) (*gitlab.Job, error)
+	GetMergeRequest(pid any, mergeRequest int64) (*gitlab.MergeRequest, error)
+	CreatePipeline(pid any, ref string, variables map[string]string) (*gitlab.Pipeline, error)
+	RetryPipeline(pid any, pipeline int64) (*gitlab.Pipeline, error)
+	CancelPipeline(pid any, pipeline int64) (*gitlab.Pipeline, error)
+	RetryJob(pid any, jobID int64) (*gitlab.Job, error)
+	CancelJob(pid any, jobID int64) (*gitlab.Job, error)
+	PlayJob(pid any, jobID int64) (*gitlab.Job, error)
+	GetJobTrace(pid any, jobID int64) (string, error)
 }
 
 type realGitlabAPI struct {
@@ -90,10 +105,108 @@ func (g *realGitlabAPI) GetJob(pid any, jobID int64) (*gitlab.Job, error) {
 	return j, err
 }
 
+func (g *realGitlabAPI) GetMergeRequest(pid any, mergeRequest int64) (*gitlab.MergeRequest, error) {
+	mr, resp, err := g.client.MergeRequests.GetMergeRequest(pid, mergeRequest, &gitlab.GetMergeRequestsOptions{})
+	closeBody(resp)
+	return mr, err
+}
+
+func (g *realGitlabAPI) CreatePipeline(pid any, ref string, variables map[string]string) (*gitlab.Pipeline, error) {
+	pipelineVariables := make([]*gitlab.PipelineVariableOptions, 0, len(variables))
+	for key, value := range variables {
+		pipelineVariables = append(pipelineVariables, &gitlab.PipelineVariableOptions{
+			Key:   gitlab.Ptr(key),
+			Value: gitlab.Ptr(value),
+		})
+	}
+
+	p, resp, err := g.client.Pipelines.CreatePipeline(pid, &gitlab.CreatePipelineOptions{
+		Ref:       gitlab.Ptr(ref),
+		Variables: &pipelineVariables,
+	})
+	closeBody(resp)
+	return p, err
+}
+
+func (g *realGitlabAPI) RetryPipeline(pid any, pipeline int64) (*gitlab.Pipeline, error) {
+	p, resp, err := g.client.Pipelines.RetryPipelineBuild(pid, pipeline)
+	closeBody(resp)
+	return p, err
+}
+
+func (g *realGitlabAPI) CancelPipeline(pid any, pipeline int64) (*gitlab.Pipeline, error) {
+	p, resp, err := g.client.Pipelines.CancelPipelineBuild(pid, pipeline)
+	closeBody(resp)
+	return p, err
+}
+
+func (g *realGitlabAPI) RetryJob(pid any, jobID int64) (*gitlab.Job, error) {
+	j, resp, err := g.client.Jobs.RetryJob(pid, jobID)
+	closeBody(resp)
+	return j, err
+}
+
+func (g *realGitlabAPI) CancelJob(pid any, jobID int64) (*gitlab.Job, error) {
+	j, resp, err := g.client.Jobs.CancelJob(pid, jobID)
+	closeBody(resp)
+	return j, err
+}
+
+func (g *realGitlabAPI) PlayJob(pid any, jobID int64) (*gitlab.Job, error) {
+	j, resp, err := g.client.Jobs.PlayJob(pid, jobID, &gitlab.PlayJobOptions{})
+	closeBody(resp)
+	return j, err
+}
+
+// GetJobTrace returns the end of the job trace: traces can be huge, so only the last maxTraceSize bytes are kept in memory
+func (g *realGitlabAPI) GetJobTrace(pid any, jobID int64) (string, error) {
+	req, err := g.client.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("projects/%s/jobs/%d/trace", gitlab.PathEscape(fmt.Sprint(pid)), jobID),
+		nil,
+		nil,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	trace := &tailWriter{limit: maxTraceSize}
+	resp, err := g.client.Do(req, trace)
+	closeBody(resp)
+	if err != nil {
+		return "", err
+	}
+
+	return trace.String(), nil
+}
+
+func closeBody(resp *gitlab.Response) {
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+}
+
 type gitlabCommand struct {
 	bot.BaseCommand
 	api  gitlabAPI
 	host string
+
+	// whitelisted projects which can be started/retried/canceled via Slack
+	pipelines config.GitlabPipelines
+}
+
+// parseURL validates the host of the given URL and extracts the project and the pipeline/job/merge request ID
+func (c *gitlabCommand) parseURL(rawURL string) (parsedURL, error) {
+	parsedURLObj, err := url.Parse(rawURL)
+	if err != nil {
+		return parsedURL{}, errors.New("invalid URL")
+	}
+	configuredHost, _ := url.Parse(c.host)
+	if parsedURLObj.Host != configuredHost.Host {
+		return parsedURL{}, errors.New("URL does not match configured GitLab host")
+	}
+
+	return parseGitlabURL(rawURL)
 }
 
 type notifyCommand struct {
@@ -129,6 +242,11 @@ func (c *notifyCommand) watch(match matcher.Result, message msg.Message) {
 		return
 	}
 
+	c.startWatch(message, rawURL, parsed)
+}
+
+// startWatch posts the current status and watches the pipeline/job/merge request until it's finished
+func (c *gitlabCommand) startWatch(message msg.Message, rawURL string, parsed parsedURL) {
 	status, err := c.fetchStatus(parsed)
 	if err != nil {
 		c.SendMessage(message, fmt.Sprintf("Error fetching GitLab %s: %s", parsed.kind, err))
@@ -143,7 +261,12 @@ func (c *notifyCommand) watch(match matcher.Result, message msg.Message) {
 
 	title := fmt.Sprintf("Watching GitLab %s %s/%d", parsed.kind, parsed.project, parsed.id)
 	attachment := buildAttachment(title, status, rawURL)
-	msgTimestamp := c.SendMessage(message, "", slack.MsgOptionAttachments(attachment))
+	msgTimestamp := c.SendMessage(
+		message,
+		"",
+		slack.MsgOptionAttachments(attachment),
+		slack.MsgOptionBlocks(c.getActionBlocks(parsed, status, rawURL)...),
+	)
 
 	runningCommand := queue.AddRunningCommand(
 		message,
@@ -156,10 +279,46 @@ func (c *notifyCommand) watch(match matcher.Result, message msg.Message) {
 }
 
 type jobDetail struct {
-	name   string
-	stage  string
-	status string
-	webURL string
+	id            int64
+	name          string
+	stage         string
+	status        string
+	webURL        string
+	failureReason string
+}
+
+// getActionBlocks returns the buttons to cancel/retry the pipeline and to play manual jobs.
+// They are only available for the whitelisted projects
+func (c *gitlabCommand) getActionBlocks(parsed parsedURL, status statusInfo, rawURL string) []slack.Block {
+	if _, ok := c.pipelines.Get(parsed.project); !ok {
+		return nil
+	}
+
+	// the pipeline of a merge request
+	targetURL := rawURL
+	if status.pipelineURL != "" {
+		targetURL = status.pipelineURL
+	}
+
+	buttons := make([]slack.BlockElement, 0)
+	switch {
+	case !isTerminalStatus(status.state):
+		buttons = append(buttons, client.GetInteractionButton("cancel", "Cancel", "gitlab cancel "+targetURL, slack.StyleDanger))
+	case status.state == "failed" || status.state == "canceled":
+		buttons = append(buttons, client.GetInteractionButton("retry", "Retry", "gitlab retry "+targetURL))
+	}
+
+	for _, job := range status.manualJobs {
+		buttons = append(buttons, client.GetInteractionButton("play", "Play "+job.name, "gitlab play "+job.webURL, slack.StylePrimary))
+	}
+
+	if len(buttons) == 0 {
+		return nil
+	}
+
+	return []slack.Block{
+		slack.NewActionBlock("", buttons...),
+	}
 }
 
 type statusInfo struct {
@@ -170,32 +329,36 @@ type statusInfo struct {
 	jobsTotal  int         // total number of jobs
 	duration   time.Duration
 	ref        string
+
+	// the pipeline URL of a merge request
+	pipelineURL string
+
+	// jobs waiting for a manual action
+	manualJobs []jobDetail
 }
 
-func (c *notifyCommand) fetchStatus(parsed parsedURL) (statusInfo, error) {
+func (c *gitlabCommand) fetchStatus(parsed parsedURL) (statusInfo, error) {
 	switch parsed.kind {
 	case urlTypePipeline:
-		pipeline, err := c.api.GetPipeline(parsed.project, int64(parsed.id))
+		return c.fetchPipelineStatus(parsed.project, int64(parsed.id))
+
+	case urlTypeMergeRequest:
+		// always watch the latest pipeline of the merge request
+		mergeRequest, err := c.api.GetMergeRequest(parsed.project, int64(parsed.id))
 		if err != nil {
 			return statusInfo{}, err
 		}
-
-		info := statusInfo{
-			state:    pipeline.Status,
-			duration: time.Duration(pipeline.Duration) * time.Second,
-			ref:      pipeline.Ref,
+		if mergeRequest.HeadPipeline == nil {
+			return statusInfo{}, errors.New("merge request has no pipeline")
 		}
 
-		jobs, err := c.api.ListPipelineJobs(parsed.project, int64(parsed.id))
-		if err != nil {
-			return info, err
+		info, err := c.fetchPipelineStatus(parsed.project, mergeRequest.HeadPipeline.ID)
+		info.pipelineURL = mergeRequest.HeadPipeline.WebURL
+		if mergeRequest.Title != "" {
+			info.summary = strings.TrimPrefix(fmt.Sprintf("%s\n%s", mergeRequest.Title, info.summary), "\n")
 		}
-		info.summary = buildJobSummary(jobs)
-		info.jobDetails = collectNotableJobs(jobs)
-		info.jobsTotal = len(jobs)
-		info.jobsDone = countDoneJobs(jobs)
 
-		return info, nil
+		return info, err
 
 	case urlTypeJob:
 		job, err := c.api.GetJob(parsed.project, int64(parsed.id))
@@ -214,7 +377,32 @@ func (c *notifyCommand) fetchStatus(parsed parsedURL) (statusInfo, error) {
 	return statusInfo{}, errors.New("unknown URL type")
 }
 
-func (c *notifyCommand) pollUntilDone(
+func (c *gitlabCommand) fetchPipelineStatus(project string, pipelineID int64) (statusInfo, error) {
+	pipeline, err := c.api.GetPipeline(project, pipelineID)
+	if err != nil {
+		return statusInfo{}, err
+	}
+
+	info := statusInfo{
+		state:    pipeline.Status,
+		duration: time.Duration(pipeline.Duration) * time.Second,
+		ref:      pipeline.Ref,
+	}
+
+	jobs, err := c.api.ListPipelineJobs(project, pipelineID)
+	if err != nil {
+		return info, err
+	}
+	info.summary = buildJobSummary(jobs)
+	info.jobDetails = collectNotableJobs(jobs)
+	info.manualJobs = collectManualJobs(jobs)
+	info.jobsTotal = len(jobs)
+	info.jobsDone = countDoneJobs(jobs)
+
+	return info, nil
+}
+
+func (c *gitlabCommand) pollUntilDone(
 	message msg.Message,
 	parsed parsedURL,
 	rawURL string,
@@ -222,8 +410,6 @@ func (c *notifyCommand) pollUntilDone(
 	msgTimestamp string,
 	runningCommand *queue.RunningCommand,
 ) {
-	defer runningCommand.Done()
-
 	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
 
@@ -242,6 +428,7 @@ func (c *notifyCommand) pollUntilDone(
 			"",
 			slack.MsgOptionUpdate(msgTimestamp),
 			slack.MsgOptionAttachments(attachment),
+			slack.MsgOptionBlocks(c.getActionBlocks(parsed, status, rawURL)...),
 		)
 
 		if isTerminalStatus(status.state) {
@@ -252,8 +439,10 @@ func (c *notifyCommand) pollUntilDone(
 	c.RemoveReaction(iconRunning, message)
 	if status.state == "success" {
 		c.AddReaction(iconSuccess, message)
+		runningCommand.DoneWithResult(queue.ResultSuccess)
 	} else {
 		c.AddReaction(iconFailed, message)
+		runningCommand.DoneWithResult(queue.ResultFailure)
 	}
 
 	c.SendMessage(message, fmt.Sprintf(
@@ -266,6 +455,10 @@ func (c *notifyCommand) pollUntilDone(
 		rawURL,
 		util.FormatDuration(status.duration),
 	))
+
+	if status.state == "failed" {
+		c.postFailureDetails(message, parsed, status)
+	}
 }
 
 func buildAttachment(title string, status statusInfo, webURL string) slack.Attachment {
@@ -381,6 +574,24 @@ func collectNotableJobs(jobs []*gitlab.Job) []jobDetail {
 	var details []jobDetail
 	for _, job := range jobs {
 		if job.Status == "running" || job.Status == "failed" {
+			details = append(details, jobDetail{
+				id:            job.ID,
+				name:          job.Name,
+				stage:         job.Stage,
+				status:        job.Status,
+				webURL:        job.WebURL,
+				failureReason: job.FailureReason,
+			})
+		}
+	}
+	return details
+}
+
+// collectManualJobs returns the jobs which are waiting to be started manually
+func collectManualJobs(jobs []*gitlab.Job) []jobDetail {
+	var details []jobDetail
+	for _, job := range jobs {
+		if job.Status == "manual" {
 			details = append(details, jobDetail{
 				name:   job.Name,
 				stage:  job.Stage,
@@ -400,17 +611,17 @@ func formatJobDetails(details []jobDetail) string {
 
 	var lines []string
 	for _, d := range details {
-		icon := ":arrow_forward:"
 		if d.status == "failed" {
-			icon = ":x:"
+			lines = append(lines, formatFailedJob(d))
+			continue
 		}
-		lines = append(lines, fmt.Sprintf("%s <%s|%s> (%s)", icon, d.webURL, d.name, d.stage))
+		lines = append(lines, fmt.Sprintf(":arrow_forward: <%s|%s> (%s)", d.webURL, d.name, d.stage))
 	}
 	return strings.Join(lines, "\n")
 }
 
 // parseGitlabURL extracts the project path, resource type, and ID from a GitLab URL
-// Supports: /<project>/-/pipelines/<id> and /<project>/-/jobs/<id>
+// Supports: /<project>/-/pipelines/<id>, /<project>/-/jobs/<id> and /<project>/-/merge_requests/<id>
 func parseGitlabURL(rawURL string) (parsedURL, error) {
 	u, err := url.Parse(rawURL)
 	if err != nil {
@@ -425,6 +636,7 @@ func parseGitlabURL(rawURL string) (parsedURL, error) {
 	}{
 		{"/-/pipelines/", urlTypePipeline},
 		{"/-/jobs/", urlTypeJob},
+		{"/-/merge_requests/", urlTypeMergeRequest},
 	}
 
 	for _, t := range types {
@@ -449,17 +661,18 @@ func parseGitlabURL(rawURL string) (parsedURL, error) {
 		}, nil
 	}
 
-	return parsedURL{}, errors.New("URL must contain /-/pipelines/<id> or /-/jobs/<id>")
+	return parsedURL{}, errors.New("URL must contain /-/pipelines/<id>, /-/jobs/<id> or /-/merge_requests/<id>")
 }
 
 func (c *notifyCommand) GetHelp() []bot.Help {
 	return []bot.Help{
 		{
 			Command:     "gitlab notify <url>",
-			Description: "watch a GitLab pipeline or job and notify when it finishes",
+			Description: "watch a GitLab pipeline, job or the latest pipeline of a merge request and notify when it finishes",
 			Examples: []string{
 				"gitlab notify https://gitlab.example.com/my-group/my-project/-/pipelines/12345",
 				"gitlab notify https://gitlab.example.com/my-group/my-project/-/jobs/67890",
+				"gitlab notify https://gitlab.example.com/my-group/my-project/-/merge_requests/42",
 			},
 			Category: category,
 		},
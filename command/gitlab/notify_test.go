@@ -44,6 +44,67 @@ func (m *mockGitlabAPI) GetJob(pid any, jobID int64) (*gitlab.Job, error) {
 	return args.Get(0).(*gitlab.Job), args.Error(1)
 }
 
+func (m *mockGitlabAPI) GetMergeRequest(pid any, mergeRequest int64) (*gitlab.MergeRequest, error) {
+	args := m.Called(pid, mergeRequest)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gitlab.MergeRequest), args.Error(1)
+}
+
+func (m *mockGitlabAPI) CreatePipeline(pid any, ref string, variables map[string]string) (*gitlab.Pipeline, error) {
+	args := m.Called(pid, ref, variables)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gitlab.Pipeline), args.Error(1)
+}
+
+func (m *mockGitlabAPI) RetryPipeline(pid any, pipeline int64) (*gitlab.Pipeline, error) {
+	args := m.Called(pid, pipeline)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gitlab.Pipeline), args.Error(1)
+}
+
+func (m *mockGitlabAPI) CancelPipeline(pid any, pipeline int64) (*gitlab.Pipeline, error) {
+	args := m.Called(pid, pipeline)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gitlab.Pipeline), args.Error(1)
+}
+
+func (m *mockGitlabAPI) RetryJob(pid any, jobID int64) (*gitlab.Job, error) {
+	args := m.Called(pid, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gitlab.Job), args.Error(1)
+}
+
+func (m *mockGitlabAPI) CancelJob(pid any, jobID int64) (*gitlab.Job, error) {
+	args := m.Called(pid, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gitlab.Job), args.Error(1)
+}
+
+func (m *mockGitlabAPI) PlayJob(pid any, jobID int64) (*gitlab.Job, error) {
+	args := m.Called(pid, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gitlab.Job), args.Error(1)
+}
+
+func (m *mockGitlabAPI) GetJobTrace(pid any, jobID int64) (string, error) {
+	args := m.Called(pid, jobID)
+	return args.String(0), args.Error(1)
+}
+
 func getTestCommand() (*mocks.SlackClient, *mockGitlabAPI, bot.Commands) {
 	slackClient := &mocks.SlackClient{}
 	api := &mockGitlabAPI{}
@@ -105,8 +166,15 @@ func TestParseGitlabURL(t *testing.T) {
 			expectedID:      999,
 		},
 		{
-			name:        "invalid URL - no pipelines or jobs",
-			url:         "https://gitlab.example.com/group/project/-/merge_requests/1",
+			name:            "merge request URL",
+			url:             "https://gitlab.example.com/group/project/-/merge_requests/1",
+			expectedProject: "group/project",
+			expectedKind:    urlTypeMergeRequest,
+			expectedID:      1,
+		},
+		{
+			name:        "invalid URL - no pipelines, jobs or merge requests",
+			url:         "https://gitlab.example.com/group/project/-/issues/1",
 			expectError: true,
 		},
 		{
@@ -157,9 +225,9 @@ func TestNotifyCommand(t *testing.T) {
 		slackClient, _, commands := getTestCommand()
 
 		message := msg.Message{}
-		message.Text = "gitlab notify https://gitlab.example.com/group/project/-/merge_requests/1"
+		message.Text = "gitlab notify https://gitlab.example.com/group/project/-/issues/1"
 
-		mocks.AssertSlackMessage(slackClient, message, "Invalid GitLab URL: URL must contain /-/pipelines/<id> or /-/jobs/<id>")
+		mocks.AssertSlackMessage(slackClient, message, "Invalid GitLab URL: URL must contain /-/pipelines/<id>, /-/jobs/<id> or /-/merge_requests/<id>")
 		actual := commands.Run(message)
 		assert.True(t, actual)
 	})
@@ -292,4 +360,5 @@ func TestFormatJobDetailsEmpty(t *testing.T) {
 func TestURLTypeString(t *testing.T) {
 	assert.Equal(t, "pipeline", urlTypePipeline.String())
 	assert.Equal(t, "job", urlTypeJob.String())
+	assert.Equal(t, "merge request", urlTypeMergeRequest.String())
 }
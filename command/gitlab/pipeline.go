@@ -0,0 +1,293 @@
+package gitlab
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/innogames/slack-bot/v2/command/approval"
+	"github.com/innogames/slack-bot/v2/command/queue"
+	log "github.com/sirupsen/logrus"
+)
+
+type pipelineCommand struct {
+	gitlabCommand
+	approvals *approval.Store[pipelineApproval]
+}
+
+// pipelineApproval is a requested pipeline run, waiting for an approval
+type pipelineApproval struct {
+	project   string
+	ref       string
+	variables map[string]string
+}
+
+// newPipelineCommand starts, retries and cancels pipelines of the whitelisted projects
+func newPipelineCommand(base gitlabCommand, approvalTimeout time.Duration) bot.Command {
+	return &pipelineCommand{
+		gitlabCommand: base,
+		approvals:     approval.NewStore[pipelineApproval](approvalTimeout),
+	}
+}
+
+func (c *pipelineCommand) IsEnabled() bool {
+	return len(c.pipelines) > 0
+}
+
+func (c *pipelineCommand) GetMatcher() matcher.Matcher {
+	return matcher.NewGroupMatcher(
+		matcher.NewRegexpMatcher(`gitlab run pipeline (?P<project>\S+) (?P<ref>\S+)(?P<variables>.*)`, c.runPipeline),
+		matcher.NewRegexpMatcher(`gitlab retry (?P<url>https://\S+)`, c.retry),
+		matcher.NewRegexpMatcher(`gitlab cancel (?P<url>https://\S+)`, c.cancel),
+		matcher.NewRegexpMatcher(`gitlab play (?P<url>https://\S+)`, c.play),
+		matcher.NewRegexpMatcher(`gitlab approve (?P<id>\w+)`, c.approve),
+		matcher.NewRegexpMatcher(`gitlab reject (?P<id>\w+)`, c.reject),
+	)
+}
+
+func (c *pipelineCommand) runPipeline(match matcher.Result, message msg.Message) {
+	project := match.GetString("project")
+	ref := match.GetString("ref")
+
+	pipelineConfig, ok := c.pipelines.Get(project)
+	if !ok {
+		c.ReplyError(message, fmt.Errorf("project %s is not whitelisted", project))
+		return
+	}
+
+	if !pipelineConfig.IsAllowedRef(ref) {
+		c.ReplyError(message, fmt.Errorf("ref %s is not allowed for project %s", ref, project))
+		return
+	}
+
+	variables, err := parseVariables(match.GetString("variables"))
+	if err != nil {
+		c.ReplyError(message, err)
+		return
+	}
+	for name := range variables {
+		if !pipelineConfig.IsAllowedVariable(name) {
+			c.ReplyError(message, fmt.Errorf("variable %s is not allowed for project %s", name, project))
+			return
+		}
+	}
+
+	if pipelineConfig.NeedsApproval {
+		c.requestApproval(project, ref, variables, message)
+		return
+	}
+
+	if err = c.createPipeline(project, ref, variables, message); err != nil {
+		c.ReplyError(message, err)
+	}
+}
+
+// parseVariables parses pipeline variables like "FOO=bar DEBUG=1"
+func parseVariables(text string) (map[string]string, error) {
+	variables := make(map[string]string)
+	for _, field := range strings.Fields(text) {
+		name, value, found := strings.Cut(field, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid variable %s, use VAR=value", field)
+		}
+		variables[name] = value
+	}
+
+	return variables, nil
+}
+
+// createPipeline starts the pipeline and watches it till it's finished
+func (c *pipelineCommand) createPipeline(project string, ref string, variables map[string]string, message msg.Message) error {
+	pipeline, err := c.api.CreatePipeline(project, ref, variables)
+	if err != nil {
+		return fmt.Errorf("error while starting pipeline: %w", err)
+	}
+
+	log.Infof("GitLab pipeline %s/%d started by %s", project, pipeline.ID, message.GetUser())
+	c.startWatch(message, pipeline.WebURL, parsedURL{project: project, kind: urlTypePipeline, id: int(pipeline.ID)})
+
+	return nil
+}
+
+// requestApproval creates a pending approval and sends a DM with approve/reject buttons
+func (c *pipelineCommand) requestApproval(project string, ref string, variables map[string]string, message msg.Message) {
+	var variableText strings.Builder
+	for name, value := range variables {
+		fmt.Fprintf(&variableText, "\n- %s: `%s`", name, value)
+	}
+
+	description := fmt.Sprintf(
+		"GitLab pipeline of *%s* (ref `%s`) needs your approval before starting.\n\n*Variables:*%s",
+		project,
+		ref,
+		variableText.String(),
+	)
+	c.approvals.Request(c.BaseCommand, message, pipelineApproval{project, ref, variables}, description, "gitlab")
+
+	c.SendMessage(message, fmt.Sprintf("Pipeline of *%s* requires approval. Please check your direct messages.", project))
+}
+
+func (c *pipelineCommand) approve(match matcher.Result, message msg.Message) {
+	request := c.approvals.Take(match.GetString("id"))
+	if request == nil {
+		c.SendMessage(message, "Approval not found or expired. Please re-trigger the pipeline.")
+		return
+	}
+
+	pipeline := request.Data
+	log.Infof("GitLab pipeline of %s approved by user %s (approval: %s)", pipeline.project, message.GetUser(), request.ID)
+	c.SendMessage(message, fmt.Sprintf("Pipeline of *%s* approved, starting...", pipeline.project))
+
+	// use the original message context so results go to the original channel
+	if err := c.createPipeline(pipeline.project, pipeline.ref, pipeline.variables, request.Message); err != nil {
+		c.ReplyError(request.Message, err)
+		request.MarkDone(queue.ResultFailure)
+		return
+	}
+
+	// the watcher registered a new running command: chain our approval to the pipeline result
+	request.ChainResult()
+}
+
+func (c *pipelineCommand) reject(match matcher.Result, message msg.Message) {
+	request := c.approvals.Take(match.GetString("id"))
+	if request == nil {
+		c.SendMessage(message, "Approval not found or already handled.")
+		return
+	}
+
+	log.Infof("GitLab pipeline of %s rejected by user %s (approval: %s)", request.Data.project, message.GetUser(), request.ID)
+	c.SendMessage(message, fmt.Sprintf("Pipeline of *%s* rejected.", request.Data.project))
+	c.SendMessage(request.Message, fmt.Sprintf("Pipeline of *%s* was rejected.", request.Data.project))
+	request.MarkDone(queue.ResultFailure)
+}
+
+// getWhitelistedURL parses the given URL and checks if the project is whitelisted
+func (c *pipelineCommand) getWhitelistedURL(rawURL string) (parsedURL, error) {
+	parsed, err := c.parseURL(rawURL)
+	if err != nil {
+		return parsed, err
+	}
+
+	if _, ok := c.pipelines.Get(parsed.project); !ok {
+		return parsed, fmt.Errorf("project %s is not whitelisted", parsed.project)
+	}
+
+	return parsed, nil
+}
+
+func (c *pipelineCommand) retry(match matcher.Result, message msg.Message) {
+	parsed, err := c.getWhitelistedURL(match.GetString("url"))
+	if err != nil {
+		c.ReplyError(message, err)
+		return
+	}
+
+	switch parsed.kind {
+	case urlTypePipeline:
+		pipeline, err := c.api.RetryPipeline(parsed.project, int64(parsed.id))
+		if err != nil {
+			c.ReplyError(message, fmt.Errorf("error while retrying pipeline: %w", err))
+			return
+		}
+		c.startWatch(message, pipeline.WebURL, parsed)
+	case urlTypeJob:
+		job, err := c.api.RetryJob(parsed.project, int64(parsed.id))
+		if err != nil {
+			c.ReplyError(message, fmt.Errorf("error while retrying job: %w", err))
+			return
+		}
+		c.startWatch(message, job.WebURL, parsedURL{project: parsed.project, kind: urlTypeJob, id: int(job.ID)})
+	case urlTypeMergeRequest:
+		c.ReplyError(message, errors.New("please use the URL of the pipeline or job"))
+	}
+}
+
+func (c *pipelineCommand) cancel(match matcher.Result, message msg.Message) {
+	parsed, err := c.getWhitelistedURL(match.GetString("url"))
+	if err != nil {
+		c.ReplyError(message, err)
+		return
+	}
+
+	switch parsed.kind {
+	case urlTypePipeline:
+		_, err = c.api.CancelPipeline(parsed.project, int64(parsed.id))
+	case urlTypeJob:
+		_, err = c.api.CancelJob(parsed.project, int64(parsed.id))
+	case urlTypeMergeRequest:
+		err = errors.New("please use the URL of the pipeline or job")
+	}
+
+	if err != nil {
+		c.ReplyError(message, fmt.Errorf("error while canceling %s: %w", parsed.kind, err))
+		return
+	}
+
+	c.SendMessage(message, fmt.Sprintf("GitLab %s %s/%d got canceled", parsed.kind, parsed.project, parsed.id))
+}
+
+func (c *pipelineCommand) play(match matcher.Result, message msg.Message) {
+	parsed, err := c.getWhitelistedURL(match.GetString("url"))
+	if err != nil {
+		c.ReplyError(message, err)
+		return
+	}
+
+	if parsed.kind != urlTypeJob {
+		c.ReplyError(message, errors.New("only manual jobs can be played"))
+		return
+	}
+
+	job, err := c.api.PlayJob(parsed.project, int64(parsed.id))
+	if err != nil {
+		c.ReplyError(message, fmt.Errorf("error while playing job: %w", err))
+		return
+	}
+
+	c.startWatch(message, job.WebURL, parsedURL{project: parsed.project, kind: urlTypeJob, id: int(job.ID)})
+}
+
+// RunAsync periodically cleans up expired approvals
+func (c *pipelineCommand) RunAsync(ctx *util.ServerContext) {
+	c.approvals.RunCleanup(ctx)
+}
+
+func (c *pipelineCommand) GetHelp() []bot.Help {
+	projects := make([]string, 0, len(c.pipelines))
+	for project := range c.pipelines {
+		projects = append(projects, project)
+	}
+
+	examples := []string{
+		"gitlab retry https://gitlab.example.com/my-group/my-project/-/pipelines/12345",
+		"gitlab cancel https://gitlab.example.com/my-group/my-project/-/jobs/67890",
+		"gitlab play https://gitlab.example.com/my-group/my-project/-/jobs/67891",
+	}
+	if len(projects) > 0 {
+		examples = append([]string{
+			"gitlab run pipeline " + projects[0] + " main",
+			"gitlab run pipeline " + projects[0] + " main DEBUG=1",
+		}, examples...)
+	}
+
+	return []bot.Help{
+		{
+			Command:     "gitlab run pipeline <project> <ref> [VAR=value...]",
+			Description: "starts a pipeline of a whitelisted project and watches it till it's finished. Allowed projects: " + strings.Join(projects, ", "),
+			Examples:    examples[:min(2, len(examples))],
+			Category:    category,
+		},
+		{
+			Command:     "gitlab retry|cancel|play <url>",
+			Description: "retries/cancels a pipeline or job, or starts a manual job of a whitelisted project",
+			Examples:    examples[len(examples)-3:],
+			Category:    category,
+		},
+	}
+}
@@ -0,0 +1,314 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/command/queue"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func getTestPipelineCommand(t *testing.T) (*mocks.SlackClient, *mockGitlabAPI, *pipelineCommand, bot.Commands) {
+	t.Helper()
+
+	slackClient := mocks.NewSlackClient(t)
+	api := &mockGitlabAPI{}
+
+	base := gitlabCommand{
+		BaseCommand: bot.BaseCommand{SlackClient: slackClient},
+		api:         api,
+		host:        testGitlabHost,
+		pipelines: config.GitlabPipelines{
+			"group/project": {
+				Refs:      []string{"main"},
+				Variables: []string{"DEBUG"},
+			},
+			"group/prod": {
+				NeedsApproval: true,
+			},
+		},
+	}
+
+	cmd := newPipelineCommand(base, 5*time.Minute).(*pipelineCommand)
+	commands := bot.Commands{}
+	commands.AddCommand(cmd)
+
+	return slackClient, api, cmd, commands
+}
+
+func mockFinishedPipeline(api *mockGitlabAPI, project string, pipelineID int64) {
+	api.On("GetPipeline", project, pipelineID).Return(&gitlab.Pipeline{Status: "success", Ref: "main"}, nil)
+	api.On("ListPipelineJobs", project, pipelineID).Return([]*gitlab.Job{
+		{Status: "success", Name: "build", Stage: "build"},
+	}, nil)
+}
+
+func assertAlreadyFinished(slackClient *mocks.SlackClient, message msg.Message) {
+	slackClient.On("SendMessage", message, "", mock.MatchedBy(func(opt slack.MsgOption) bool {
+		return strings.Contains(mocks.GetAttachmentJSON(opt), "already finished")
+	})).Once().Return("")
+}
+
+func TestPipelineCommand(t *testing.T) {
+	t.Run("not enabled without pipelines", func(t *testing.T) {
+		cmd := newPipelineCommand(gitlabCommand{}, time.Minute).(*pipelineCommand)
+		assert.False(t, cmd.IsEnabled())
+	})
+
+	t.Run("project not whitelisted", func(t *testing.T) {
+		slackClient, _, _, commands := getTestPipelineCommand(t)
+
+		message := msg.Message{}
+		message.Text = "gitlab run pipeline group/other main"
+
+		mocks.AssertError(slackClient, message, "project group/other is not whitelisted")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("ref not allowed", func(t *testing.T) {
+		slackClient, _, _, commands := getTestPipelineCommand(t)
+
+		message := msg.Message{}
+		message.Text = "gitlab run pipeline group/project feature"
+
+		mocks.AssertError(slackClient, message, "ref feature is not allowed for project group/project")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("variable not allowed", func(t *testing.T) {
+		slackClient, _, _, commands := getTestPipelineCommand(t)
+
+		message := msg.Message{}
+		message.Text = "gitlab run pipeline group/project main SECRET=1"
+
+		mocks.AssertError(slackClient, message, "variable SECRET is not allowed for project group/project")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("invalid variable", func(t *testing.T) {
+		slackClient, _, _, commands := getTestPipelineCommand(t)
+
+		message := msg.Message{}
+		message.Text = "gitlab run pipeline group/project main DEBUG"
+
+		mocks.AssertError(slackClient, message, "invalid variable DEBUG, use VAR=value")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("run pipeline", func(t *testing.T) {
+		slackClient, api, _, commands := getTestPipelineCommand(t)
+
+		message := msg.Message{}
+		message.Text = "gitlab run pipeline Group/Project main DEBUG=1"
+
+		api.On("CreatePipeline", "Group/Project", "main", map[string]string{"DEBUG": "1"}).Return(&gitlab.Pipeline{
+			ID:     300,
+			WebURL: "https://gitlab.example.com/group/project/-/pipelines/300",
+		}, nil)
+		mockFinishedPipeline(api, "Group/Project", 300)
+		assertAlreadyFinished(slackClient, message)
+
+		assert.True(t, commands.Run(message))
+		api.AssertExpectations(t)
+	})
+
+	t.Run("approval", func(t *testing.T) {
+		slackClient, api, cmd, commands := getTestPipelineCommand(t)
+
+		message := msg.Message{}
+		message.Text = "gitlab run pipeline group/prod v1.0"
+		message.User = "U123"
+		message.Channel = "C123"
+
+		slackClient.On("SendBlockMessageToUser", "U123", mock.AnythingOfType("[]slack.Block")).Once().Return("")
+		mocks.AssertSlackMessage(slackClient, message, "Pipeline of *group/prod* requires approval. Please check your direct messages.")
+		assert.True(t, commands.Run(message))
+
+		runningCommand := queue.GetRunningCommand(message.GetUniqueKey())
+		require.NotNil(t, runningCommand)
+
+		var approvalID string
+		for _, id := range cmd.approvals.GetIDs() {
+			approvalID = id
+		}
+		require.NotEmpty(t, approvalID)
+
+		api.On("CreatePipeline", "group/prod", "v1.0", map[string]string{}).Return(&gitlab.Pipeline{
+			ID:     400,
+			WebURL: "https://gitlab.example.com/group/prod/-/pipelines/400",
+		}, nil)
+		mockFinishedPipeline(api, "group/prod", 400)
+		assertAlreadyFinished(slackClient, message)
+
+		approveMessage := msg.Message{}
+		approveMessage.Text = "gitlab approve " + approvalID
+		mocks.AssertSlackMessage(slackClient, approveMessage, "Pipeline of *group/prod* approved, starting...")
+		assert.True(t, commands.Run(approveMessage))
+
+		assert.Equal(t, queue.ResultSuccess, runningCommand.WaitForResult())
+
+		// the approval can only be used once
+		mocks.AssertSlackMessage(slackClient, approveMessage, "Approval not found or expired. Please re-trigger the pipeline.")
+		assert.True(t, commands.Run(approveMessage))
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		slackClient, _, cmd, commands := getTestPipelineCommand(t)
+
+		message := msg.Message{}
+		message.Text = "gitlab run pipeline group/prod main"
+		message.User = "U124"
+
+		slackClient.On("SendBlockMessageToUser", "U124", mock.AnythingOfType("[]slack.Block")).Once().Return("")
+		mocks.AssertSlackMessage(slackClient, message, "Pipeline of *group/prod* requires approval. Please check your direct messages.")
+		assert.True(t, commands.Run(message))
+
+		runningCommand := queue.GetRunningCommand(message.GetUniqueKey())
+		require.NotNil(t, runningCommand)
+
+		var approvalID string
+		for _, id := range cmd.approvals.GetIDs() {
+			approvalID = id
+		}
+
+		rejectMessage := msg.Message{}
+		rejectMessage.Text = "gitlab reject " + approvalID
+		mocks.AssertSlackMessage(slackClient, rejectMessage, "Pipeline of *group/prod* rejected.")
+		mocks.AssertSlackMessage(slackClient, message, "Pipeline of *group/prod* was rejected.")
+		assert.True(t, commands.Run(rejectMessage))
+
+		assert.Equal(t, queue.ResultFailure, runningCommand.WaitForResult())
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		slackClient, api, _, commands := getTestPipelineCommand(t)
+
+		message := msg.Message{}
+		message.Text = "gitlab cancel https://gitlab.example.com/group/project/-/jobs/12"
+
+		api.On("CancelJob", "group/project", int64(12)).Return(&gitlab.Job{ID: 12}, nil)
+		mocks.AssertSlackMessage(slackClient, message, "GitLab job group/project/12 got canceled")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("cancel not whitelisted", func(t *testing.T) {
+		slackClient, _, _, commands := getTestPipelineCommand(t)
+
+		message := msg.Message{}
+		message.Text = "gitlab cancel https://gitlab.example.com/group/other/-/pipelines/12"
+
+		mocks.AssertError(slackClient, message, "project group/other is not whitelisted")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("retry pipeline", func(t *testing.T) {
+		slackClient, api, _, commands := getTestPipelineCommand(t)
+
+		message := msg.Message{}
+		message.Text = "gitlab retry https://gitlab.example.com/group/project/-/pipelines/500"
+
+		api.On("RetryPipeline", "group/project", int64(500)).Return(&gitlab.Pipeline{
+			ID:     500,
+			WebURL: "https://gitlab.example.com/group/project/-/pipelines/500",
+		}, nil)
+		mockFinishedPipeline(api, "group/project", 500)
+		assertAlreadyFinished(slackClient, message)
+
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("play needs a job", func(t *testing.T) {
+		slackClient, _, _, commands := getTestPipelineCommand(t)
+
+		message := msg.Message{}
+		message.Text = "gitlab play https://gitlab.example.com/group/project/-/pipelines/500"
+
+		mocks.AssertError(slackClient, message, "only manual jobs can be played")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("help", func(t *testing.T) {
+		_, _, _, commands := getTestPipelineCommand(t)
+
+		assert.Len(t, commands.GetHelp(), 2)
+	})
+}
+
+func toJSON(blocks []slack.Block) string {
+	blocksJSON, _ := json.Marshal(blocks)
+	return string(blocksJSON)
+}
+
+func TestGetActionBlocks(t *testing.T) {
+	_, _, cmd, _ := getTestPipelineCommand(t)
+
+	whitelisted := parsedURL{project: "group/project", kind: urlTypePipeline, id: 1}
+	rawURL := "https://gitlab.example.com/group/project/-/pipelines/1"
+
+	t.Run("not whitelisted", func(t *testing.T) {
+		blocks := cmd.getActionBlocks(parsedURL{project: "group/other"}, statusInfo{state: "running"}, rawURL)
+		assert.Nil(t, blocks)
+	})
+
+	t.Run("running", func(t *testing.T) {
+		blocks := cmd.getActionBlocks(whitelisted, statusInfo{state: "running"}, rawURL)
+		require.Len(t, blocks, 1)
+		assert.Contains(t, toJSON(blocks), "gitlab cancel "+rawURL)
+	})
+
+	t.Run("failed with manual job", func(t *testing.T) {
+		status := statusInfo{
+			state:      "failed",
+			manualJobs: []jobDetail{{name: "deploy", webURL: "https://gitlab.example.com/group/project/-/jobs/2"}},
+		}
+		blocks := cmd.getActionBlocks(whitelisted, status, rawURL)
+		require.Len(t, blocks, 1)
+
+		blocksJSON := toJSON(blocks)
+		assert.Contains(t, blocksJSON, "gitlab retry "+rawURL)
+		assert.Contains(t, blocksJSON, "gitlab play https://gitlab.example.com/group/project/-/jobs/2")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		blocks := cmd.getActionBlocks(whitelisted, statusInfo{state: "success"}, rawURL)
+		assert.Nil(t, blocks)
+	})
+}
+
+func TestFetchMergeRequestStatus(t *testing.T) {
+	_, api, cmd, _ := getTestPipelineCommand(t)
+
+	parsed := parsedURL{project: "group/project", kind: urlTypeMergeRequest, id: 7}
+
+	t.Run("without pipeline", func(t *testing.T) {
+		api.On("GetMergeRequest", "group/project", int64(7)).Return(&gitlab.MergeRequest{}, nil).Once()
+
+		_, err := cmd.fetchStatus(parsed)
+		require.EqualError(t, err, "merge request has no pipeline")
+	})
+
+	t.Run("latest pipeline", func(t *testing.T) {
+		mergeRequest := &gitlab.MergeRequest{}
+		mergeRequest.Title = "Add feature"
+		mergeRequest.HeadPipeline = &gitlab.Pipeline{ID: 55, WebURL: "https://gitlab.example.com/group/project/-/pipelines/55"}
+
+		api.On("GetMergeRequest", "group/project", int64(7)).Return(mergeRequest, nil).Once()
+		mockFinishedPipeline(api, "group/project", 55)
+
+		status, err := cmd.fetchStatus(parsed)
+		require.NoError(t, err)
+		assert.Equal(t, "success", status.state)
+		assert.Equal(t, "https://gitlab.example.com/group/project/-/pipelines/55", status.pipelineURL)
+		assert.Equal(t, "Add feature\n1 success", status.summary)
+	})
+}
@@ -101,7 +101,12 @@ func (c *buildWatcherCommand) watch(match matcher.Result, message msg.Message) {
 	c.AddReaction(iconRunning, message)
 	go func() {
 		<-client.WatchBuild(build)
-		runningCommand.Done()
+		isGood := build.IsGood(ctx)
+		if isGood {
+			runningCommand.Done()
+		} else {
+			runningCommand.DoneWithResult(queue.ResultFailure)
+		}
 
 		c.SendMessage(
 			message,
@@ -111,7 +116,7 @@ func (c *buildWatcherCommand) watch(match matcher.Result, message msg.Message) {
 		)
 
 		c.RemoveReaction(iconRunning, message)
-		if build.IsGood(ctx) {
+		if isGood {
 			c.AddReaction(iconSuccess, message)
 		} else {
 			c.AddReaction(iconFailed, message)
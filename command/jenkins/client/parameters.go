@@ -32,17 +32,19 @@ func (p Parameters) String() string {
 
 // ParameterModifier are functions to mutate given Jenkins parameters
 // e.g. ensure the parameter is a real "boolean" value
-type ParameterModifier func(string) (string, error)
+type ParameterModifier func(parameter config.JobParameter, value string) (string, error)
 
 var parameterModifier = map[string]ParameterModifier{
-	"branch": vcs.GetMatchingBranch,
-	"lowerCase": func(input string) (string, error) {
+	"branch": func(parameter config.JobParameter, input string) (string, error) {
+		return vcs.GetMatchingBranchOfRepository(parameter.Repository, input)
+	},
+	"lowerCase": func(_ config.JobParameter, input string) (string, error) {
 		return strings.ToLower(input), nil
 	},
-	"upperCase": func(input string) (string, error) {
+	"upperCase": func(_ config.JobParameter, input string) (string, error) {
 		return strings.ToUpper(input), nil
 	},
-	"bool": func(value string) (string, error) {
+	"bool": func(_ config.JobParameter, value string) (string, error) {
 		switch value {
 		case "false", "FALSE", "0", "null", "", " ":
 			return "false", nil
@@ -78,7 +80,7 @@ func ParseParameters(jobConfig config.JobConfig, parameterString string, params
 		}
 
 		if modifier, ok := parameterModifier[parameterConfig.Type]; ok {
-			value, err = modifier(value)
+			value, err = modifier(parameterConfig, value)
 			if err != nil {
 				return err
 			}
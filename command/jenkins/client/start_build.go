@@ -47,7 +47,11 @@ func TriggerJenkinsJob(cfg config.JobConfig, jobName string, jobParams Parameter
 	go func() {
 		// wait until job is not running anymore and update progress every 30s
 		watchProgress(build, slackClient, message, msgTimestamp)
-		runningCommand.Done()
+		if build.Raw.Result == gojenkins.STATUS_SUCCESS {
+			runningCommand.Done()
+		} else {
+			runningCommand.DoneWithResult(queue.ResultFailure)
+		}
 
 		// update main message
 		attachment := GetAttachment(build, fmt.Sprintf(
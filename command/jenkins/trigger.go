@@ -1,6 +1,7 @@
 package jenkins
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
@@ -13,6 +14,8 @@ import (
 	"github.com/innogames/slack-bot/v2/bot/msg"
 	"github.com/innogames/slack-bot/v2/bot/util"
 	"github.com/innogames/slack-bot/v2/client"
+	"github.com/innogames/slack-bot/v2/client/vcs"
+	"github.com/innogames/slack-bot/v2/command/approval"
 	jenkinsClient "github.com/innogames/slack-bot/v2/command/jenkins/client"
 	"github.com/innogames/slack-bot/v2/command/queue"
 	log "github.com/sirupsen/logrus"
@@ -22,10 +25,16 @@ import (
 // command to trigger/start jenkins jobs
 type triggerCommand struct {
 	jenkinsCommand
-	jobs            map[string]triggerCommandData
-	cfg             config.JenkinsJobs
-	approvals       *approvalStore
-	approvalTimeout time.Duration
+	jobs      map[string]triggerCommandData
+	cfg       config.JenkinsJobs
+	approvals *approval.Store[jobApproval]
+}
+
+// jobApproval is a requested job run, waiting for an approval
+type jobApproval struct {
+	jobName   string
+	jobConfig config.JobConfig
+	params    jenkinsClient.Parameters
 }
 
 type triggerCommandData struct {
@@ -50,7 +59,7 @@ func newTriggerCommand(
 		}
 	}
 
-	return &triggerCommand{base, trigger, jobs, newApprovalStore(), approvalTimeout}
+	return &triggerCommand{base, trigger, jobs, approval.NewStore[jobApproval](approvalTimeout)}
 }
 
 func (c *triggerCommand) GetMatcher() matcher.Matcher {
@@ -94,7 +103,7 @@ func (c *triggerCommand) genericCall(match matcher.Result, message msg.Message)
 	finalParameters := make(jenkinsClient.Parameters)
 	err = jenkinsClient.ParseParameters(jobConfig.config, parameterString, finalParameters)
 	if err != nil {
-		c.ReplyError(message, err)
+		c.replyParameterError(message, err)
 		return
 	}
 
@@ -117,13 +126,13 @@ func (c *triggerCommand) configTrigger(ref msg.Ref, text string) bool {
 		parameters := jobConfig.trigger.ReplaceAllString(text, "")
 		jobParams := util.RegexpResultToParams(jobConfig.trigger, match)
 
+		message := ref.WithText(text)
 		err := jenkinsClient.ParseParameters(jobConfig.config, parameters, jobParams)
 		if err != nil {
-			c.ReplyError(ref, err)
+			c.replyParameterError(message, err)
 			return true
 		}
 
-		message := ref.WithText(text)
 		c.triggerOrRequestApproval(jobName, jobConfig.config, jobParams, message)
 
 		return true
@@ -132,6 +141,33 @@ func (c *triggerCommand) configTrigger(ref msg.Ref, text string) bool {
 	return false
 }
 
+// replyParameterError replies with the error. For ambiguous branches, buttons are shown to repeat the command with one of the matching branches.
+func (c *triggerCommand) replyParameterError(message msg.Message, err error) {
+	var ambiguousErr vcs.AmbiguousBranchError
+	if !errors.As(err, &ambiguousErr) {
+		c.ReplyError(message, err)
+		return
+	}
+
+	// the parameters are given at the end of the command, so we replace the last occurrence of the input
+	idx := strings.LastIndex(message.Text, ambiguousErr.Input)
+	if idx == -1 {
+		c.ReplyError(message, err)
+		return
+	}
+
+	buttons := make([]slack.BlockElement, 0, len(ambiguousErr.Branches))
+	for i, branch := range ambiguousErr.Branches {
+		command := message.Text[:idx] + branch + message.Text[idx+len(ambiguousErr.Input):]
+		buttons = append(buttons, client.GetInteractionButton(fmt.Sprintf("branch_%d", i), branch, command))
+	}
+
+	c.SendBlockMessage(message, []slack.Block{
+		client.GetTextBlock(fmt.Sprintf("Multiple branches are matching *%s*, did you mean:", ambiguousErr.Input)),
+		slack.NewActionBlock("", buttons...),
+	})
+}
+
 // triggerOrRequestApproval either triggers the job directly or requests approval first
 func (c *triggerCommand) triggerOrRequestApproval(jobName string, cfg config.JobConfig, params jenkinsClient.Parameters, message msg.Message) {
 	if cfg.NeedsApproval {
@@ -147,118 +183,61 @@ func (c *triggerCommand) triggerOrRequestApproval(jobName string, cfg config.Job
 
 // requestApproval creates a pending approval and sends a DM with approve/reject buttons
 func (c *triggerCommand) requestApproval(jobName string, cfg config.JobConfig, params jenkinsClient.Parameters, message msg.Message) {
-	id := generateApprovalID()
-	now := time.Now()
-
-	runningCmd := queue.AddRunningCommand(message, "")
-	approval := &pendingApproval{
-		id:             id,
-		jobName:        jobName,
-		jobConfig:      cfg,
-		params:         params,
-		message:        message,
-		createdAt:      now,
-		expiresAt:      now.Add(c.approvalTimeout),
-		runningCommand: runningCmd,
-	}
-	c.approvals.add(approval)
-
 	// build parameter summary
 	var paramText strings.Builder
 	for name, value := range params {
 		fmt.Fprintf(&paramText, "\n- %s: `%s`", name, value)
 	}
 
-	timeoutStr := util.FormatDuration(c.approvalTimeout)
-
-	blocks := []slack.Block{
-		client.GetTextBlock(":warning: *Approval Required*"),
-		client.GetTextBlock(fmt.Sprintf(
-			"Jenkins job *%s* needs your approval before starting.\n\n*Parameters:*%s",
-			jobName,
-			paramText.String(),
-		)),
-		client.GetContextBlock(fmt.Sprintf("This approval expires in %s.", timeoutStr)),
-		slack.NewActionBlock(
-			"",
-			client.GetInteractionButton("approve", "Approve", "jenkins approve "+id, slack.StylePrimary),
-			client.GetInteractionButton("reject", "Reject", "jenkins reject "+id, slack.StyleDanger),
-		),
-	}
+	description := fmt.Sprintf(
+		"Jenkins job *%s* needs your approval before starting.\n\n*Parameters:*%s",
+		jobName,
+		paramText.String(),
+	)
+	c.approvals.Request(c.BaseCommand, message, jobApproval{jobName, cfg, params}, description, "jenkins")
 
-	c.SendBlockMessageToUser(message.GetUser(), blocks)
 	c.SendMessage(message, fmt.Sprintf("Job *%s* requires approval. Please check your direct messages.", jobName))
 }
 
 func (c *triggerCommand) approveJob(match matcher.Result, message msg.Message) {
-	id := match.GetString("id")
-	approval := c.approvals.get(id)
-
-	if approval == nil {
+	request := c.approvals.Take(match.GetString("id"))
+	if request == nil {
 		c.SendMessage(message, "Approval not found or expired. Please re-trigger the job.")
 		return
 	}
 
-	c.approvals.remove(id)
-
-	log.Infof("Job %s approved by user %s (approval: %s)", approval.jobName, message.GetUser(), id)
-	c.SendMessage(message, fmt.Sprintf("Job *%s* approved, starting build...", approval.jobName))
+	job := request.Data
+	log.Infof("Job %s approved by user %s (approval: %s)", job.jobName, message.GetUser(), request.ID)
+	c.SendMessage(message, fmt.Sprintf("Job *%s* approved, starting build...", job.jobName))
 
 	// trigger the job using the original message context so results go to the original channel
-	err := jenkinsClient.TriggerJenkinsJob(approval.jobConfig, approval.jobName, approval.params, c.SlackClient, c.jenkins, approval.message)
+	err := jenkinsClient.TriggerJenkinsJob(job.jobConfig, job.jobName, job.params, c.SlackClient, c.jenkins, request.Message)
 	if err != nil {
-		c.ReplyError(approval.message, err)
-		approval.markDone()
+		c.ReplyError(request.Message, err)
+		request.MarkDone(queue.ResultFailure)
 		return
 	}
 
-	// TriggerJenkinsJob registered a new running command for the job (overwriting ours in the map).
-	// Chain our approval's Done() to the job's completion so "then" commands typed before approval
-	// still wait for the full job to finish, not just for the approval to be granted.
-	jobCmd := queue.GetRunningCommand(approval.message.GetUniqueKey())
-	if jobCmd != nil && jobCmd != approval.runningCommand {
-		go func() {
-			jobCmd.Wait()
-			approval.markDone()
-		}()
-	} else {
-		approval.markDone()
-	}
+	// TriggerJenkinsJob registered a new running command for the job
+	request.ChainResult()
 }
 
 func (c *triggerCommand) rejectJob(match matcher.Result, message msg.Message) {
-	id := match.GetString("id")
-	approval := c.approvals.get(id)
-
-	if approval == nil {
+	request := c.approvals.Take(match.GetString("id"))
+	if request == nil {
 		c.SendMessage(message, "Approval not found or already handled.")
 		return
 	}
 
-	c.approvals.remove(id)
-
-	log.Infof("Job %s rejected by user %s (approval: %s)", approval.jobName, message.GetUser(), id)
-	c.SendMessage(message, fmt.Sprintf("Job *%s* rejected.", approval.jobName))
-	c.SendMessage(approval.message, fmt.Sprintf("Job *%s* was rejected.", approval.jobName))
-	approval.markDone()
+	log.Infof("Job %s rejected by user %s (approval: %s)", request.Data.jobName, message.GetUser(), request.ID)
+	c.SendMessage(message, fmt.Sprintf("Job *%s* rejected.", request.Data.jobName))
+	c.SendMessage(request.Message, fmt.Sprintf("Job *%s* was rejected.", request.Data.jobName))
+	request.MarkDone(queue.ResultFailure)
 }
 
 // RunAsync periodically cleans up expired approvals
 func (c *triggerCommand) RunAsync(ctx *util.ServerContext) {
-	ctx.RegisterChild()
-	defer ctx.ChildDone()
-
-	ticker := time.NewTicker(time.Minute)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			c.approvals.cleanup()
-		case <-ctx.Done():
-			return
-		}
-	}
+	c.approvals.RunCleanup(ctx)
 }
 
 func (c *triggerCommand) GetHelp() []bot.Help {
@@ -9,6 +9,7 @@ import (
 	"github.com/innogames/slack-bot/v2/bot"
 	"github.com/innogames/slack-bot/v2/bot/config"
 	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/client/vcs"
 	"github.com/innogames/slack-bot/v2/command/queue"
 	"github.com/innogames/slack-bot/v2/mocks"
 	"github.com/stretchr/testify/assert"
@@ -270,12 +271,10 @@ func TestJenkinsTriggerApproval(t *testing.T) {
 		cmd2.Run(originalMessage)
 
 		// find the stored approval ID
-		trigger2.approvals.mu.Lock()
 		var approvalID string
-		for id := range trigger2.approvals.pending {
+		for _, id := range trigger2.approvals.GetIDs() {
 			approvalID = id
 		}
-		trigger2.approvals.mu.Unlock()
 		assert.NotEmpty(t, approvalID)
 
 		// now approve it
@@ -320,12 +319,10 @@ func TestJenkinsTriggerApproval(t *testing.T) {
 		cmd3.Run(originalMessage)
 
 		// find the approval ID
-		trigger3.approvals.mu.Lock()
 		var approvalID string
-		for id := range trigger3.approvals.pending {
+		for _, id := range trigger3.approvals.GetIDs() {
 			approvalID = id
 		}
-		trigger3.approvals.mu.Unlock()
 
 		// reject it
 		rejectMessage := msg.Message{}
@@ -367,12 +364,10 @@ func TestJenkinsTriggerApproval(t *testing.T) {
 		assert.NotNil(t, runningCmd)
 
 		// rejecting should release the running command
-		triggerR.approvals.mu.Lock()
 		var approvalID string
-		for id := range triggerR.approvals.pending {
+		for _, id := range triggerR.approvals.GetIDs() {
 			approvalID = id
 		}
-		triggerR.approvals.mu.Unlock()
 
 		rejectMessage := msg.Message{}
 		rejectMessage.Text = "jenkins reject " + approvalID
@@ -392,6 +387,9 @@ func TestJenkinsTriggerApproval(t *testing.T) {
 		case <-time.After(500 * time.Millisecond):
 			t.Fatal("running command was not released after reject")
 		}
+
+		// the rejected job is a failure for "then on failure" commands
+		assert.Equal(t, queue.ResultFailure, runningCmd.WaitForResult())
 	})
 
 	t.Run("approve expired approval", func(t *testing.T) {
@@ -417,12 +415,10 @@ func TestJenkinsTriggerApproval(t *testing.T) {
 		cmd4.Run(originalMessage)
 
 		// find the approval ID
-		trigger4.approvals.mu.Lock()
 		var approvalID string
-		for id := range trigger4.approvals.pending {
+		for _, id := range trigger4.approvals.GetIDs() {
 			approvalID = id
 		}
-		trigger4.approvals.mu.Unlock()
 
 		// wait for expiry
 		time.Sleep(5 * time.Millisecond)
@@ -437,3 +433,37 @@ func TestJenkinsTriggerApproval(t *testing.T) {
 		assert.True(t, actual)
 	})
 }
+
+func TestJenkinsTriggerAmbiguousBranch(t *testing.T) {
+	slackClient, _, base := getTestJenkinsCommand()
+
+	cfg := config.JenkinsJobs{
+		"BuildTests": {
+			Parameters: []config.JobParameter{
+				{Name: "BRANCH", Type: "branch"},
+			},
+		},
+	}
+	trigger := newTriggerCommand(base, cfg, 5*time.Minute).(*triggerCommand)
+
+	t.Run("did you mean buttons", func(t *testing.T) {
+		message := msg.Message{}
+		message.Text = "trigger job BuildTests PROJ-123"
+
+		mocks.AssertSlackBlocks(t, slackClient, message, `[{"type":"section","text":{"type":"mrkdwn","text":"Multiple branches are matching *PROJ-123*, did you mean:"}},{"type":"actions","elements":[{"type":"button","text":{"type":"plain_text","text":"feature/PROJ-123-new","emoji":true},"action_id":"branch_0","value":"trigger job BuildTests feature/PROJ-123-new"},{"type":"button","text":{"type":"plain_text","text":"bugfix/PROJ-123-fix","emoji":true},"action_id":"branch_1","value":"trigger job BuildTests bugfix/PROJ-123-fix"}]}]`)
+
+		trigger.replyParameterError(message, vcs.AmbiguousBranchError{
+			Input:    "PROJ-123",
+			Branches: []string{"feature/PROJ-123-new", "bugfix/PROJ-123-fix"},
+		})
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		message := msg.Message{}
+		message.Text = "trigger job BuildTests"
+
+		mocks.AssertError(slackClient, message, "please provide a branch name")
+
+		trigger.replyParameterError(message, errors.New("please provide a branch name"))
+	})
+}
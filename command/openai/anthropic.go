@@ -0,0 +1,208 @@
+package openai
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	anthropicHost = "https://api.anthropic.com"
+
+	// API docs: https://docs.anthropic.com/en/api/messages
+	anthropicMessagesURL = "/v1/messages"
+	anthropicVersion     = "2023-06-01"
+
+	// max_tokens is mandatory for the Anthropic API
+	anthropicDefaultMaxTokens = 4096
+)
+
+// anthropicProvider uses the Anthropic Messages API (Claude models)
+type anthropicProvider struct{}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float32            `json:"temperature,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"` // plain string or a list of anthropicContent
+}
+
+type anthropicContent struct {
+	Type   string                `json:"type"` // "text" or "image"
+	Text   string                `json:"text,omitempty"`
+	Source *anthropicImageSource `json:"source,omitempty"`
+}
+
+type anthropicImageSource struct {
+	Type      string `json:"type"` // "base64"
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+// anthropicResponse is used for the full response and for the single events of the event stream
+type anthropicResponse struct {
+	Type    string `json:"type"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+
+	// token usage: in the full response and the "message_delta" event. The "message_start" event contains the input tokens
+	Usage   anthropicUsage `json:"usage"`
+	Message struct {
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+func (p anthropicProvider) getURL(cfg Config, endpoint string) string {
+	return getHost(cfg, anthropicHost) + endpoint
+}
+
+func (p anthropicProvider) setHeaders(cfg Config, req *http.Request) {
+	req.Header.Set("x-api-key", cfg.APIKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+}
+
+func (p anthropicProvider) chat(cfg Config, inputMessages []ChatMessage, stream bool, messageUpdates chan<- string) (usage Usage, err error) {
+	request := anthropicRequest{
+		Model:       cfg.Model,
+		MaxTokens:   cfg.MaxTokens,
+		Temperature: cfg.Temperature,
+		Stream:      stream,
+	}
+	if request.MaxTokens == 0 {
+		request.MaxTokens = anthropicDefaultMaxTokens
+	}
+
+	// system messages are passed as separate field, all others have to be "user" or "assistant"
+	systemMessages := make([]string, 0)
+	for _, message := range inputMessages {
+		if message.Role == roleSystem {
+			systemMessages = append(systemMessages, message.Content)
+			continue
+		}
+		request.Messages = append(request.Messages, toAnthropicMessage(message))
+	}
+	request.System = strings.Join(systemMessages, "\n\n")
+
+	jsonData, _ := json.Marshal(request)
+	if cfg.LogTexts {
+		log.Println(string(jsonData))
+	}
+
+	resp, err := doRequest(cfg, anthropicMessagesURL, jsonData)
+	if err != nil {
+		return usage, err
+	}
+	defer resp.Body.Close()
+
+	if !stream || resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+
+		var response anthropicResponse
+		if err = json.Unmarshal(body, &response); err != nil {
+			log.Warnf("Anthropic Error %d: %s", resp.StatusCode, err)
+			return usage, fmt.Errorf("error %d: %w", resp.StatusCode, err)
+		}
+
+		if response.Error.Message != "" {
+			log.Warnf("Anthropic Error %d: %s", resp.StatusCode, response.Error.Message)
+			return usage, errors.New(response.Error.Message)
+		}
+
+		for _, content := range response.Content {
+			if content.Type == "text" && content.Text != "" {
+				messageUpdates <- content.Text
+			}
+		}
+		usage = Usage{InputTokens: response.Usage.InputTokens, OutputTokens: response.Usage.OutputTokens}
+		return usage, nil
+	}
+
+	// stream: the "data" of each "content_block_delta" event contains the next text delta
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		data, found := strings.CutPrefix(scanner.Text(), "data: ")
+		if !found {
+			continue
+		}
+
+		var event anthropicResponse
+		if err = json.Unmarshal([]byte(data), &event); err != nil {
+			log.Warnf("anthropic error in json: %s (json: %s)", err, data)
+			continue
+		}
+
+		switch event.Type {
+		case "message_start":
+			usage.InputTokens = event.Message.Usage.InputTokens
+		case "message_delta":
+			usage.OutputTokens = event.Usage.OutputTokens
+		case "content_block_delta":
+			messageUpdates <- event.Delta.Text
+		case "error":
+			return usage, errors.New(event.Error.Message)
+		case "message_stop":
+			return usage, nil
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		log.Warnf("anthropic stream scanner error: %s", err)
+		return usage, fmt.Errorf("stream error: %w", err)
+	}
+
+	return usage, nil
+}
+
+// toAnthropicMessage converts the message, images are passed as base64 "image" content blocks
+func toAnthropicMessage(message ChatMessage) anthropicMessage {
+	images := message.GetImages()
+	if len(images) == 0 {
+		return anthropicMessage{Role: message.Role, Content: message.GetText()}
+	}
+
+	content := []anthropicContent{{Type: "text", Text: message.GetText()}}
+	for _, image := range images {
+		mediaType, data, ok := parseDataURL(image)
+		if !ok {
+			continue
+		}
+		content = append(content, anthropicContent{
+			Type: "image",
+			Source: &anthropicImageSource{
+				Type:      "base64",
+				MediaType: mediaType,
+				Data:      data,
+			},
+		})
+	}
+
+	return anthropicMessage{Role: message.Role, Content: content}
+}
@@ -3,7 +3,10 @@ package openai
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -17,12 +20,23 @@ const (
 	apiCompletionURL = "/v1/chat/completions"
 
 	apiDalleGenerateImageURL = "/v1/images/generations"
+	apiDalleEditImageURL     = "/v1/images/edits"
+	apiDalleVariationURL     = "/v1/images/variations"
+
+	// API docs: https://learn.microsoft.com/en-us/azure/ai-services/openai/reference
+	defaultAzureAPIVersion = "2024-10-21"
 )
 
 const (
 	roleUser      = "user"
 	roleSystem    = "system"
 	roleAssistant = "assistant"
+	roleTool      = "tool"
+)
+
+const (
+	contentTypeText  = "text"
+	contentTypeImage = "image_url"
 )
 
 // we don't use our default clients.HttpClient as we need longer timeouts...
@@ -31,14 +45,24 @@ var httpClient = http.Client{
 }
 
 func doRequest(cfg Config, apiEndpoint string, data []byte) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, cfg.APIHost+apiEndpoint, bytes.NewBuffer(data))
+	return doRequestWithContentType(cfg, apiEndpoint, "application/json", bytes.NewBuffer(data))
+}
+
+// doRequestWithContentType sends any body, like multipart/form-data for the image edits
+func doRequestWithContentType(cfg Config, apiEndpoint string, contentType string, body io.Reader) (*http.Response, error) {
+	provider, err := getProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, provider.getURL(cfg, apiEndpoint), body)
 	if err != nil {
 		log.WithError(err).Error("OpenAI: Failed to create HTTP request")
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+cfg.APIKey)
+	req.Header.Set("Content-Type", contentType)
+	provider.setHeaders(cfg, req)
 
 	// Create a client with the configured timeout
 	client := &http.Client{
@@ -49,6 +73,7 @@ func doRequest(cfg Config, apiEndpoint string, data []byte) (*http.Response, err
 	if err != nil {
 		log.WithError(err).
 			WithField("endpoint", apiEndpoint).
+			WithField("provider", cfg.Provider).
 			WithField("timeout", cfg.APITimeout).
 			Error("OpenAI: API request failed (timeout or connection error)")
 		return nil, err
@@ -59,24 +84,155 @@ func doRequest(cfg Config, apiEndpoint string, data []byte) (*http.Response, err
 
 // ChatRequest API reference: https://platform.openai.com/docs/api-reference/chat
 type ChatRequest struct {
-	Model            string        `json:"model"`
-	Messages         []ChatMessage `json:"messages"`
-	Temperature      float32       `json:"temperature,omitempty"`
-	TopP             float32       `json:"top_p,omitempty"`
-	N                int           `json:"n,omitempty"`
-	Stop             []string      `json:"stop,omitempty"`
-	Stream           bool          `json:"stream,omitempty"`
-	MaxTokens        int           `json:"max_tokens,omitempty"`
-	PresencePenalty  float32       `json:"presence_penalty,omitempty"`
-	ReasoningEffort  string        `json:"reasoning_effort,omitempty"`
-	FrequencyPenalty float32       `json:"frequency_penalty,omitempty"`
-	User             string        `json:"user,omitempty"`
-	Seed             string        `json:"seed,omitempty"`
+	Model            string         `json:"model"`
+	Messages         []ChatMessage  `json:"messages"`
+	Temperature      float32        `json:"temperature,omitempty"`
+	TopP             float32        `json:"top_p,omitempty"`
+	N                int            `json:"n,omitempty"`
+	Stop             []string       `json:"stop,omitempty"`
+	Stream           bool           `json:"stream,omitempty"`
+	MaxTokens        int            `json:"max_tokens,omitempty"`
+	PresencePenalty  float32        `json:"presence_penalty,omitempty"`
+	ReasoningEffort  string         `json:"reasoning_effort,omitempty"`
+	FrequencyPenalty float32        `json:"frequency_penalty,omitempty"`
+	User             string         `json:"user,omitempty"`
+	Seed             string         `json:"seed,omitempty"`
+	Tools            []Tool         `json:"tools,omitempty"`
+	StreamOptions    *StreamOptions `json:"stream_options,omitempty"`
+}
+
+// StreamOptions with IncludeUsage, the last event of the stream contains the token usage
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type ChatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+
+	// optional multi-part content (text + images). If set, it's sent instead of the plain Content
+	Parts []ContentPart `json:"-"`
+}
+
+// ContentPart is a part of a multi-part message: "text" or "image_url"
+type ContentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL contains the image as "data:image/png;base64,..." URL
+type ImageURL struct {
+	URL string `json:"url"`
+}
+
+// chatMessageJSON is the wire format of the ChatMessage: the content is either a string or a list of ContentPart
+type chatMessageJSON struct {
+	Role       string          `json:"role"`
+	Content    json.RawMessage `json:"content"`
+	ToolCalls  []ToolCall      `json:"tool_calls,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+}
+
+func (m ChatMessage) MarshalJSON() ([]byte, error) {
+	var content any = m.Content
+	if len(m.Parts) > 0 {
+		content = m.Parts
+	}
+
+	rawContent, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(chatMessageJSON{
+		Role:       m.Role,
+		Content:    rawContent,
+		ToolCalls:  m.ToolCalls,
+		ToolCallID: m.ToolCallID,
+	})
+}
+
+func (m *ChatMessage) UnmarshalJSON(data []byte) error {
+	var message chatMessageJSON
+	if err := json.Unmarshal(data, &message); err != nil {
+		return err
+	}
+
+	*m = ChatMessage{
+		Role:       message.Role,
+		ToolCalls:  message.ToolCalls,
+		ToolCallID: message.ToolCallID,
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(message.Content), []byte("[")) {
+		if err := json.Unmarshal(message.Content, &m.Parts); err != nil {
+			return err
+		}
+		m.Content = m.GetText()
+
+		return nil
+	}
+
+	if len(message.Content) > 0 && string(message.Content) != "null" {
+		return json.Unmarshal(message.Content, &m.Content)
+	}
+
+	return nil
+}
+
+// GetText returns the text of the message, joining all text parts of a multi-part message
+func (m ChatMessage) GetText() string {
+	if len(m.Parts) == 0 {
+		return m.Content
+	}
+
+	texts := make([]string, 0, len(m.Parts))
+	for _, part := range m.Parts {
+		if part.Type == contentTypeText {
+			texts = append(texts, part.Text)
+		}
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+// GetImages returns the data URLs of all attached images
+func (m ChatMessage) GetImages() []string {
+	images := make([]string, 0)
+	for _, part := range m.Parts {
+		if part.Type == contentTypeImage && part.ImageURL != nil {
+			images = append(images, part.ImageURL.URL)
+		}
+	}
+
+	return images
+}
+
+// Tool API reference: https://platform.openai.com/docs/guides/function-calling
+type Tool struct {
+	Type     string       `json:"type"`
+	Function ToolFunction `json:"function"`
+}
+
+type ToolFunction struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Parameters  any    `json:"parameters,omitempty"`
+}
+
+// ToolCall is returned by the model, when it wants to call one of the given tools
+type ToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"`
+	Function ToolCallFunction `json:"function"`
+}
+
+type ToolCallFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
 type ChatResponse struct {
@@ -103,6 +259,13 @@ func (r ChatResponse) GetDelta() ChatMessage {
 	return r.Choices[0].Delta
 }
 
+func (r ChatResponse) GetUsage() Usage {
+	return Usage{
+		InputTokens:  r.Usage.PromptTokens,
+		OutputTokens: r.Usage.CompletionTokens,
+	}
+}
+
 func (r ChatResponse) GetError() error {
 	if r.Error.Message == "" {
 		return nil
@@ -163,7 +326,9 @@ type DalleResponse struct {
 	} `json:"error"`
 }
 
+// DalleResponseImage contains the URL of the image or the base64 encoded image (e.g. for "gpt-image-1")
 type DalleResponseImage struct {
 	URL           string `json:"url"`
+	B64JSON       string `json:"b64_json"`
 	RevisedPrompt string `json:"revised_prompt"`
 }
@@ -0,0 +1,153 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+// image types which are supported by the vision models
+var imageMimeTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+const pdfMimeType = "application/pdf"
+
+// attachments holds the content of the files attached to a message
+type attachments struct {
+	text    string
+	images  []ContentPart
+	skipped []string // file name + reason, e.g. "archive.zip (unsupported file type application/zip)"
+}
+
+// getFiles returns the files which were attached to the given message
+func getFiles(ref msg.Ref) []slack.File {
+	if message, ok := ref.(msg.Message); ok {
+		return message.Files
+	}
+
+	return nil
+}
+
+// loadAttachments downloads the attached files: text files and PDFs are added as text, images as vision content.
+// withText=false skips the text files, e.g. when the bot already added their content to the message.
+func (c *openaiCommand) loadAttachments(files []slack.File, withText bool) attachments {
+	var result attachments
+	var text strings.Builder
+
+	for _, file := range files {
+		isImage := slices.Contains(imageMimeTypes, file.Mimetype)
+		isPDF := file.Mimetype == pdfMimeType
+		isText := strings.HasPrefix(file.Mimetype, "text/")
+
+		switch {
+		case !withText && !isImage && !isPDF:
+			continue
+		case !isImage && !isPDF && !isText:
+			result.skipped = append(result.skipped, fmt.Sprintf("%s (unsupported file type %s)", file.Name, file.Mimetype))
+			continue
+		case c.cfg.MaxAttachmentSize > 0 && file.Size > c.cfg.MaxAttachmentSize:
+			result.skipped = append(result.skipped, fmt.Sprintf("%s (bigger than %s)", file.Name, util.FormatBytes(uint64(c.cfg.MaxAttachmentSize))))
+			continue
+		}
+
+		var buf bytes.Buffer
+		log.Infof("Downloading attachment %s", file.Name)
+
+		if err := c.GetFile(file.URLPrivate, &buf); err != nil {
+			log.Warnf("Failed to download attachment %s: %v", file.Name, err)
+			result.skipped = append(result.skipped, fmt.Sprintf("%s (download failed)", file.Name))
+			continue
+		}
+
+		switch {
+		case isImage:
+			result.images = append(result.images, ContentPart{
+				Type: contentTypeImage,
+				ImageURL: &ImageURL{
+					URL: "data:" + file.Mimetype + ";base64," + base64.StdEncoding.EncodeToString(buf.Bytes()),
+				},
+			})
+		case isPDF:
+			pdfText, err := util.ExtractPDFText(buf.Bytes())
+			if err != nil || pdfText == "" {
+				result.skipped = append(result.skipped, fmt.Sprintf("%s (no text found in PDF)", file.Name))
+				continue
+			}
+			fmt.Fprintf(&text, " <Attachment filename=\"%s\">%s</Attachment>", file.Name, pdfText)
+		default:
+			fmt.Fprintf(&text, " <Attachment filename=\"%s\">%s</Attachment>", file.Name, buf.String())
+		}
+	}
+
+	// let the model know about the skipped files, so it doesn't pretend to know them
+	for _, skipped := range result.skipped {
+		fmt.Fprintf(&text, " <Attachment filename=\"%s\" skipped=\"true\"/>", skipped)
+	}
+	result.text = text.String()
+
+	return result
+}
+
+// notifySkipped informs the user about attachments which are not passed to the model
+func (c *openaiCommand) notifySkipped(message msg.Ref, skipped []string) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	c.SendMessage(
+		message,
+		"Note: these attachments were skipped: "+strings.Join(skipped, ", "),
+		slack.MsgOptionTS(message.GetTimestamp()),
+	)
+}
+
+// newUserMessage creates a user message, using a multi-part content if images are attached
+func newUserMessage(text string, images []ContentPart) ChatMessage {
+	message := ChatMessage{
+		Role:    roleUser,
+		Content: text,
+	}
+
+	if len(images) > 0 {
+		message.Parts = append([]ContentPart{{Type: contentTypeText, Text: text}}, images...)
+	}
+
+	return message
+}
+
+// withoutImages replaces the images by a placeholder, so the base64 encoded images are not stored in the history
+func withoutImages(messages []ChatMessage) []ChatMessage {
+	stripped := slices.Clone(messages)
+	for i, message := range stripped {
+		if len(message.GetImages()) == 0 {
+			continue
+		}
+
+		text := message.GetText()
+		for range message.GetImages() {
+			text += " <Attachment type=\"image\" removed=\"true\"/>"
+		}
+		stripped[i].Content = text
+		stripped[i].Parts = nil
+	}
+
+	return stripped
+}
+
+// parseDataURL splits a "data:image/png;base64,..." URL into the media type and the base64 encoded data
+func parseDataURL(url string) (string, string, bool) {
+	header, data, found := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+	if !found || !strings.HasPrefix(url, "data:") {
+		return "", "", false
+	}
+
+	mediaType, isBase64 := strings.CutSuffix(header, ";base64")
+
+	return mediaType, data, isBase64
+}
@@ -0,0 +1,127 @@
+package openai
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachments(t *testing.T) {
+	t.Run("Multi-part message JSON", func(t *testing.T) {
+		message := newUserMessage("what's that?", []ContentPart{
+			{Type: contentTypeImage, ImageURL: &ImageURL{URL: "data:image/png;base64,UE5H"}},
+		})
+
+		data, err := json.Marshal(message)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"role":"user","content":[{"type":"text","text":"what's that?"},{"type":"image_url","image_url":{"url":"data:image/png;base64,UE5H"}}]}`, string(data))
+
+		// stored history is loaded again
+		var actual ChatMessage
+		require.NoError(t, json.Unmarshal(data, &actual))
+		assert.Equal(t, message, actual)
+		assert.Equal(t, []string{"data:image/png;base64,UE5H"}, actual.GetImages())
+
+		// plain text and null content
+		require.NoError(t, json.Unmarshal([]byte(`{"role":"assistant","content":"hello"}`), &actual))
+		assert.Equal(t, ChatMessage{Role: roleAssistant, Content: "hello"}, actual)
+		require.NoError(t, json.Unmarshal([]byte(`{"role":"assistant","content":null}`), &actual))
+		assert.Equal(t, ChatMessage{Role: roleAssistant}, actual)
+
+		data, err = json.Marshal(ChatMessage{Role: roleUser, Content: "hello"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"role":"user","content":"hello"}`, string(data))
+	})
+
+	t.Run("Parse data URL", func(t *testing.T) {
+		mediaType, data, ok := parseDataURL("data:image/jpeg;base64,abc=")
+		assert.True(t, ok)
+		assert.Equal(t, "image/jpeg", mediaType)
+		assert.Equal(t, "abc=", data)
+
+		_, _, ok = parseDataURL("https://example.com/image.png")
+		assert.False(t, ok)
+	})
+
+	t.Run("Provider formats", func(t *testing.T) {
+		message := newUserMessage("what's that?", []ContentPart{
+			{Type: contentTypeImage, ImageURL: &ImageURL{URL: "data:image/png;base64,UE5H"}},
+		})
+
+		data, err := json.Marshal(toAnthropicMessage(message))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"role":"user","content":[{"type":"text","text":"what's that?"},{"type":"image","source":{"type":"base64","media_type":"image/png","data":"UE5H"}}]}`, string(data))
+
+		data, err = json.Marshal(toAnthropicMessage(ChatMessage{Role: roleUser, Content: "hello"}))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"role":"user","content":"hello"}`, string(data))
+	})
+
+	t.Run("Load attachments", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		cfg := defaultConfig
+		cfg.MaxAttachmentSize = 1000
+		command := &openaiCommand{BaseCommand: bot.BaseCommand{SlackClient: slackClient}, cfg: cfg}
+
+		pdf := "%PDF-1.4\n1 0 obj\n<< /Length 30 >>\nstream\nBT (Build failed) Tj ET\nendstream\nendobj\n"
+		slackClient.On("GetFile", "https://files.slack.com/report.pdf", mock.Anything).
+			Run(func(args mock.Arguments) {
+				args.Get(1).(io.Writer).Write([]byte(pdf))
+			}).
+			Return(nil).Twice()
+		slackClient.On("GetFile", "https://files.slack.com/error.jpg", mock.Anything).
+			Run(func(args mock.Arguments) {
+				args.Get(1).(io.Writer).Write([]byte("JPG"))
+			}).
+			Return(nil).Twice()
+
+		files := []slack.File{
+			{Name: "report.pdf", Mimetype: pdfMimeType, Size: 100, URLPrivate: "https://files.slack.com/report.pdf"},
+			{Name: "error.jpg", Mimetype: "image/jpeg", Size: 100, URLPrivate: "https://files.slack.com/error.jpg"},
+			{Name: "huge.png", Mimetype: "image/png", Size: 2048, URLPrivate: "https://files.slack.com/huge.png"},
+			{Name: "video.mp4", Mimetype: "video/mp4", Size: 100},
+		}
+
+		actual := command.loadAttachments(files, true)
+		assert.Equal(t, ` <Attachment filename="report.pdf">Build failed</Attachment> <Attachment filename="huge.png (bigger than 1.0 kB)" skipped="true"/> <Attachment filename="video.mp4 (unsupported file type video/mp4)" skipped="true"/>`, actual.text)
+		assert.Equal(t, []string{"huge.png (bigger than 1.0 kB)", "video.mp4 (unsupported file type video/mp4)"}, actual.skipped)
+		require.Len(t, actual.images, 1)
+		assert.Equal(t, "data:image/jpeg;base64,SlBH", actual.images[0].ImageURL.URL)
+
+		// only PDFs and images of the current message: the text files are already part of the message
+		message := msg.Message{Files: append(files, slack.File{Name: "log.txt", Mimetype: "text/plain", Size: 100})}
+		message.Channel = "C123"
+		message.Timestamp = "1234"
+		mocks.AssertSlackMessage(slackClient, message, "Note: these attachments were skipped: huge.png (bigger than 1.0 kB)", mock.Anything)
+
+		text, images := command.loadMessageFiles(message)
+		assert.Equal(t, ` <Attachment filename="report.pdf">Build failed</Attachment> <Attachment filename="huge.png (bigger than 1.0 kB)" skipped="true"/>`, text)
+		assert.Len(t, images, 1)
+	})
+
+	t.Run("History without images", func(t *testing.T) {
+		messages := []ChatMessage{
+			{Role: roleSystem, Content: "be nice"},
+			newUserMessage("what's that?", []ContentPart{
+				{Type: contentTypeImage, ImageURL: &ImageURL{URL: "data:image/png;base64,UE5H"}},
+			}),
+		}
+
+		actual := withoutImages(messages)
+		assert.Equal(t, []ChatMessage{
+			{Role: roleSystem, Content: "be nice"},
+			{Role: roleUser, Content: `what's that? <Attachment type="image" removed="true"/>`},
+		}, actual)
+
+		// the images are still sent in the current request
+		assert.Len(t, messages[1].GetImages(), 1)
+	})
+}
@@ -11,91 +11,166 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// CallChatGPT sends the messages to the configured provider of the model and returns a chan of all message updates.
+// The token usage is recorded without a user, e.g. for template functions.
 func CallChatGPT(cfg Config, inputMessages []ChatMessage, stream bool) (<-chan string, error) {
+	return callChatGPT(cfg, inputMessages, stream, func(usage Usage, _ error) {
+		recordUsage(cfg.Usage, "", "", cfg.Model, usage.orEstimate(cfg.Model, inputMessages, ""))
+	})
+}
+
+// Complete sends the messages in a single non-streamed request and returns the whole answer. In contrast to CallChatGPT,
+// errors of the provider are returned and are not part of the answer. The token usage is recorded without a user.
+func Complete(cfg Config, inputMessages []ChatMessage) (string, error) {
+	answer, usage, err := completeChat(cfg, inputMessages)
+	if err != nil {
+		return "", err
+	}
+
+	recordUsage(cfg.Usage, "", "", cfg.Model, usage)
+
+	return answer, nil
+}
+
+// completeChat waits for the whole answer of a non-streamed request and returns it together with the (estimated) token usage
+func completeChat(cfg Config, inputMessages []ChatMessage) (string, Usage, error) {
+	var usage Usage
+	var callErr error
+	response, err := callChatGPT(cfg, inputMessages, false, func(u Usage, err error) {
+		usage = u
+		callErr = err
+	})
+	if err != nil {
+		return "", usage, err
+	}
+
+	var result strings.Builder
+	for part := range response {
+		result.WriteString(part)
+	}
+
+	// the error is also part of the response, but must not be used as answer
+	if callErr != nil {
+		return "", usage, callErr
+	}
+
+	return result.String(), usage.orEstimate(cfg.Model, inputMessages, result.String()), nil
+}
+
+// callChatGPT returns a chan of all message updates. onDone receives the token usage and the error of the provider, before the chan gets closed.
+// The error is also written into the chan, as it should be visible for the user.
+func callChatGPT(cfg Config, inputMessages []ChatMessage, stream bool, onDone func(Usage, error)) (<-chan string, error) {
+	cfg = cfg.ForModel(cfg.Model)
+
+	provider, err := getProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	messageUpdates := make(chan string, 2)
 
 	// return a chan of all message updates here and listen here in the background in the event stream
 	go func() {
 		defer close(messageUpdates)
 
-		jsonData, _ := json.Marshal(ChatRequest{
-			Model:           cfg.Model,
-			Temperature:     cfg.Temperature,
-			Seed:            cfg.Seed,
-			MaxTokens:       cfg.MaxTokens,
-			ReasoningEffort: cfg.ReasoningEffort,
-			Stream:          stream,
-			Messages:        inputMessages,
-		})
-
-		if cfg.LogTexts {
-			log.Println(string(jsonData))
-		}
-
-		resp, err := doRequest(cfg, apiCompletionURL, jsonData)
+		usage, err := provider.chat(cfg, inputMessages, stream, messageUpdates)
 		if err != nil {
-			log.WithError(err).
-				WithField("model", cfg.Model).
-				WithField("stream", stream).
-				Error("ChatGPT request failed")
 			messageUpdates <- err.Error()
-			return
 		}
-		defer resp.Body.Close()
+		onDone(usage, err)
+	}()
 
-		// some error occurred: we don't have an event stream but a single ChatResponse with an error
-		if !stream || resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
+	return messageUpdates, nil
+}
 
-			var chatResponse ChatResponse
-			err = json.Unmarshal(body, &chatResponse)
-			if err != nil {
-				log.Warnf("Openai Error %d: %s", resp.StatusCode, err)
+// chat uses the OpenAI chat completions API, see https://platform.openai.com/docs/api-reference/chat
+func (p openaiProvider) chat(cfg Config, inputMessages []ChatMessage, stream bool, messageUpdates chan<- string) (usage Usage, err error) {
+	request := ChatRequest{
+		Model:           cfg.Model,
+		Temperature:     cfg.Temperature,
+		Seed:            cfg.Seed,
+		MaxTokens:       cfg.MaxTokens,
+		ReasoningEffort: cfg.ReasoningEffort,
+		Stream:          stream,
+		Messages:        inputMessages,
+	}
+	if stream {
+		// the last event contains the token usage of the whole request
+		request.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+	jsonData, _ := json.Marshal(request)
 
-				messageUpdates <- fmt.Sprintf("Error %d: %s", resp.StatusCode, err)
-				return
-			}
+	if cfg.LogTexts {
+		log.Println(string(jsonData))
+	}
 
-			if err = chatResponse.GetError(); err != nil {
-				log.Warn("Openai Error: ", err, chatResponse, body)
-				messageUpdates <- err.Error()
-				return
-			}
+	resp, err := doRequest(cfg, apiCompletionURL, jsonData)
+	if err != nil {
+		log.WithError(err).
+			WithField("model", cfg.Model).
+			WithField("stream", stream).
+			Error("ChatGPT request failed")
+		return usage, err
+	}
+	defer resp.Body.Close()
 
-			if message := chatResponse.GetMessage().Content; message != "" {
-				messageUpdates <- message
-			}
-		} else {
-			// stream: each line contains a delta of the message, so one new token
-			fileScanner := bufio.NewScanner(resp.Body)
-			fileScanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
-			fileScanner.Split(bufio.ScanLines)
-			for fileScanner.Scan() {
-				line := fileScanner.Text()
-				if _, deltaJSON, found := strings.Cut(line, "data: "); found {
-					if deltaJSON == "[DONE]" {
-						// end of event stream
-						return
-					}
-
-					var delta ChatResponse
-					err = json.Unmarshal([]byte(deltaJSON), &delta)
-					if err != nil {
-						log.Warnf("openai error in json: %s (json: %s)", err, deltaJSON)
-						continue
-					}
-
-					deltaContent := delta.GetDelta().Content
-					// Send content if available, or send empty string for role-only deltas to trigger reaction changes
-					messageUpdates <- deltaContent
+	// some error occurred: we don't have an event stream but a single ChatResponse with an error
+	if !stream || resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+
+		var chatResponse ChatResponse
+		err = json.Unmarshal(body, &chatResponse)
+		if err != nil {
+			log.Warnf("Openai Error %d: %s", resp.StatusCode, err)
+
+			return usage, fmt.Errorf("error %d: %w", resp.StatusCode, err)
+		}
+
+		if err = chatResponse.GetError(); err != nil {
+			log.Warn("Openai Error: ", err, chatResponse, body)
+			return usage, err
+		}
+
+		if message := chatResponse.GetMessage().Content; message != "" {
+			messageUpdates <- message
+		}
+		usage = chatResponse.GetUsage()
+	} else {
+		// stream: each line contains a delta of the message, so one new token
+		fileScanner := bufio.NewScanner(resp.Body)
+		fileScanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		fileScanner.Split(bufio.ScanLines)
+		for fileScanner.Scan() {
+			line := fileScanner.Text()
+			if _, deltaJSON, found := strings.Cut(line, "data: "); found {
+				if deltaJSON == "[DONE]" {
+					// end of event stream
+					return usage, nil
 				}
-			}
-			if err := fileScanner.Err(); err != nil {
-				log.Warnf("openai stream scanner error: %s", err)
-				messageUpdates <- fmt.Sprintf("stream error: %s", err)
+
+				var delta ChatResponse
+				err = json.Unmarshal([]byte(deltaJSON), &delta)
+				if err != nil {
+					log.Warnf("openai error in json: %s (json: %s)", err, deltaJSON)
+					continue
+				}
+
+				// the usage is sent in an extra event without choices
+				if len(delta.Choices) == 0 {
+					usage = delta.GetUsage()
+					continue
+				}
+
+				deltaContent := delta.GetDelta().Content
+				// Send content if available, or send empty string for role-only deltas to trigger reaction changes
+				messageUpdates <- deltaContent
 			}
 		}
-	}()
+		if err = fileScanner.Err(); err != nil {
+			log.Warnf("openai stream scanner error: %s", err)
+			return usage, fmt.Errorf("stream error: %w", err)
+		}
+	}
 
-	return messageUpdates, nil
+	return usage, nil
 }
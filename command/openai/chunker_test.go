@@ -28,7 +28,7 @@ func TestMessageChunking(t *testing.T) {
 			apiCompletionURL,
 			[]testRequest{
 				{
-					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"user","content":"give me a long response"}],"stream":true}`,
+					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"user","content":"give me a long response"}],"stream":true,"stream_options":{"include_usage":true}}`,
 					fmt.Sprintf(`data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"role":"assistant"},"index":0,"finish_reason":null}]}
 
 data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"content":"%s"},"index":0,"finish_reason":null}]}
@@ -51,7 +51,7 @@ data: [DONE]`, longPart1, longPart2),
 		cfg := &config.Config{}
 		cfg.Set("openai", openaiCfg)
 
-		commands := GetCommands(base, cfg)
+		commands := GetCommands(base, cfg, nil)
 
 		message := msg.Message{}
 		message.Text = "openai give me a long response"
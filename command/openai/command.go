@@ -1,7 +1,6 @@
 package openai
 
 import (
-	"bytes"
 	"fmt"
 	"regexp"
 	"slices"
@@ -26,19 +25,30 @@ const (
 
 var linkRe = regexp.MustCompile(`<?https://(.*?)\.slack\.com/archives/(?P<channel>\w+)/p(?P<timestamp>\d{16})>?`)
 
-// GetCommands if enable, register the openai commands
-func GetCommands(base bot.BaseCommand, config *config.Config) bot.Commands {
+// GetCommands if enable, register the openai commands.
+// The toolCommands are the bot commands which can be executed by the model, if "tools" are enabled.
+// Their output is captured, so they have to use the OutputCapture of the base command as client.
+func GetCommands(base bot.BaseCommand, config *config.Config, toolCommands *bot.Commands) bot.Commands {
 	var commands bot.Commands
 
-	cfg := loadConfig(config)
+	cfg := LoadConfig(config)
 	if !cfg.IsEnabled() {
 		return commands
 	}
 
+	registerMetrics.Do(func() {
+		stats.RegisterCollector(requestsCounter)
+		stats.RegisterCollector(tokensCounter)
+		stats.RegisterCollector(costCounter)
+	})
+
 	commands.AddCommand(
 		&openaiCommand{
-			base,
-			cfg,
+			BaseCommand: base,
+			cfg:         cfg,
+			tools:       newToolRunner(cfg.Tools, base, toolCommands),
+			knowledge:   newKnowledgeBase(cfg, base.SlackClient),
+			adminUsers:  config.AdminUsers,
 		},
 	)
 
@@ -47,19 +57,39 @@ func GetCommands(base bot.BaseCommand, config *config.Config) bot.Commands {
 
 type openaiCommand struct {
 	bot.BaseCommand
-	cfg Config
+	cfg        Config
+	tools      *toolRunner    // nil if tool calling is disabled
+	knowledge  *knowledgeBase // nil if the knowledge base is disabled
+	adminUsers config.UserList
 }
 
 func (c *openaiCommand) GetMatcher() matcher.Matcher {
 	matchers := []matcher.Matcher{
+		matcher.NewAdminMatcher(
+			c.adminUsers,
+			c.SlackClient,
+			matcher.NewRegexpMatcher(`openai usage( (?P<period>[0-9]+[a-z]+))?`, c.usageReport),
+		),
+		matcher.NewRegexpMatcher(`summarize thread( (?P<link>\S+))?`, c.summarizeThread),
+		matcher.NewRegexpMatcher(`digest (<#(?P<channelID>\w+)(\|[^>]*)?>|#?(?P<channel>[\w\-]+))( (?P<period>[0-9]+[a-z]+))?`, c.digest),
 		matcher.NewPrefixMatcher("openai", c.newConversation),
 		matcher.NewPrefixMatcher("chatgpt", c.newConversation),
 		matcher.NewPrefixMatcher("dalle", c.dalleGenerateImage),
 		matcher.NewPrefixMatcher("dall-e", c.dalleGenerateImage),
 		matcher.NewPrefixMatcher("generate image", c.dalleGenerateImage),
+		matcher.NewPrefixMatcher("edit image", c.dalleEditImage),
+		matcher.NewPrefixMatcher("image variations", c.dalleImageVariations),
 		matcher.WildcardMatcher(c.reply),
 	}
 
+	if c.knowledge != nil {
+		matchers = append(matchers, matcher.NewAdminMatcher(
+			c.adminUsers,
+			c.SlackClient,
+			matcher.NewTextMatcher("reindex knowledge", c.reindexKnowledge),
+		))
+	}
+
 	// if configured evaluate the given command text as openai request
 	if c.cfg.UseAsFallback {
 		matchers = append(matchers, matcher.WildcardMatcher(c.startConversation))
@@ -78,12 +108,21 @@ func (c *openaiCommand) startConversation(message msg.Ref, text string) bool {
 	// Parse hashtags and get cleaned text
 	cleanText, hashtagOptions := ParseHashtags(text)
 
+	var threadIdentifier string
+	if message.GetThread() != "" {
+		threadIdentifier = getIdentifier(message.GetChannel(), message.GetThread())
+	}
+	if err := c.resolvePersona(&hashtagOptions, message.GetChannel(), threadIdentifier); err != nil {
+		c.ReplyError(message, err)
+		return true
+	}
+
 	messageHistory := make([]ChatMessage, 0)
 
-	if c.cfg.InitialSystemMessage != "" {
+	if systemMessage := c.cfg.WithPersona(hashtagOptions.Persona).InitialSystemMessage; systemMessage != "" {
 		messageHistory = append(messageHistory, ChatMessage{
 			Role:    roleSystem,
-			Content: c.cfg.InitialSystemMessage,
+			Content: systemMessage,
 		})
 	}
 
@@ -115,6 +154,8 @@ func (c *openaiCommand) startConversation(message msg.Ref, text string) bool {
 	}
 
 	var storageIdentifier string
+	var fileText string
+	var images []ContentPart
 	switch {
 	case message.GetThread() != "":
 		// "openai" was triggered within a existing thread. -> fetch the whole thread history as context
@@ -129,19 +170,14 @@ func (c *openaiCommand) startConversation(message msg.Ref, text string) bool {
 			Content: "This is a Slack bot receiving a slack thread s context, using slack user ids as identifiers. Please use user mentions in the format <@U123456>",
 		})
 
-		for _, threadMessage := range threadMessages {
-			content := threadMessage.Text
-			if len(threadMessage.Files) > 0 {
-				content += c.loadTextAttachments(threadMessage.Files)
-			}
-			messageHistory = append(messageHistory, ChatMessage{
-				Role:    roleUser,
-				Content: fmt.Sprintf("User <@%s> wrote: %s", threadMessage.User, content),
-			})
-		}
+		// the current message is part of the thread, including its attachments
+		threadHistory, skipped := c.getThreadHistory(threadMessages)
+		messageHistory = append(messageHistory, threadHistory...)
+		c.notifySkipped(message, skipped)
+
 		storageIdentifier = getIdentifier(message.GetChannel(), message.GetThread())
 		if c.cfg.LogTexts {
-			log.Infof("openai thread context: %s", messageHistory)
+			log.Infof("openai thread context: %v", messageHistory)
 		}
 	case linkRe.MatchString(cleanText):
 		// a link to another thread was posted -> use this messages as context
@@ -158,27 +194,52 @@ func (c *openaiCommand) startConversation(message msg.Ref, text string) bool {
 			return true
 		}
 
-		for _, threadMessage := range threadMessages {
-			content := threadMessage.Text
-			if len(threadMessage.Files) > 0 {
-				content += c.loadTextAttachments(threadMessage.Files)
-			}
-			messageHistory = append(messageHistory, ChatMessage{
-				Role:    roleUser,
-				Content: fmt.Sprintf("User <@%s> wrote: %s", threadMessage.User, content),
-			})
-		}
+		threadHistory, skipped := c.getThreadHistory(threadMessages)
+		messageHistory = append(messageHistory, threadHistory...)
+		c.notifySkipped(message, skipped)
 
+		fileText, images = c.loadMessageFiles(message)
+		cleanText += fileText
 		storageIdentifier = getIdentifier(message.GetChannel(), message.GetTimestamp())
 	default:
 		// start a new thread with a fresh history
+		fileText, images = c.loadMessageFiles(message)
+		cleanText += fileText
 		storageIdentifier = getIdentifier(message.GetChannel(), message.GetTimestamp())
 	}
 
-	c.callAndStore(messageHistory, storageIdentifier, message, cleanText, hashtagOptions)
+	c.callAndStore(messageHistory, storageIdentifier, message, cleanText, hashtagOptions, images)
 	return true
 }
 
+// getThreadHistory converts the thread messages (including their attachments) into user messages
+func (c *openaiCommand) getThreadHistory(threadMessages []slack.Message) ([]ChatMessage, []string) {
+	history := make([]ChatMessage, 0, len(threadMessages))
+	skipped := make([]string, 0)
+
+	for _, threadMessage := range threadMessages {
+		content := threadMessage.Text
+		var images []ContentPart
+		if len(threadMessage.Files) > 0 {
+			attached := c.loadAttachments(threadMessage.Files, true)
+			content += attached.text
+			images = attached.images
+			skipped = append(skipped, attached.skipped...)
+		}
+		history = append(history, newUserMessage(fmt.Sprintf("User <@%s> wrote: %s", threadMessage.User, content), images))
+	}
+
+	return history, skipped
+}
+
+// loadMessageFiles loads the PDFs and images which are attached to the current message. Text files are already part of the message text.
+func (c *openaiCommand) loadMessageFiles(message msg.Ref) (string, []ContentPart) {
+	attached := c.loadAttachments(getFiles(message), false)
+	c.notifySkipped(message, attached.skipped)
+
+	return attached.text, attached.images
+}
+
 // bot function which is called when the user replied in a openai/chatgpt thread
 func (c *openaiCommand) reply(message msg.Ref, text string) bool {
 	if message.GetThread() == "" {
@@ -199,27 +260,36 @@ func (c *openaiCommand) reply(message msg.Ref, text string) bool {
 		return false
 	}
 
+	if err = c.resolvePersona(&hashtagOptions, message.GetChannel(), identifier); err != nil {
+		c.ReplyError(message, err)
+		return true
+	}
+
 	// Call the API and send the last messages as history to give a proper context
-	c.callAndStore(messages, identifier, message, cleanText, hashtagOptions)
+	fileText, images := c.loadMessageFiles(message)
+	c.callAndStore(messages, identifier, message, cleanText+fileText, hashtagOptions, images)
 
 	return true
 }
 
 // call the GPT-3 API, sends the response to the user, and stores the updated chat history.
-func (c *openaiCommand) callAndStore(messages []ChatMessage, storageIdentifier string, message msg.Ref, inputText string, options HashtagOptions) {
-	// Append the actual user input to the message list.
-	messages = append(messages, ChatMessage{
-		Role:    roleUser,
-		Content: inputText,
-	})
+func (c *openaiCommand) callAndStore(messages []ChatMessage, storageIdentifier string, message msg.Ref, inputText string, options HashtagOptions, images []ContentPart) {
+	if budgetMessage := checkBudget(c.cfg.Usage, message.GetUser(), time.Now()); budgetMessage != "" {
+		c.SendMessage(message, budgetMessage, slack.MsgOptionTS(message.GetTimestamp()))
+		return
+	}
 
-	// Create a custom config based on hashtag options
-	customCfg := c.cfg
+	// Append the actual user input (and the attached images) to the message list.
+	messages = append(messages, newUserMessage(inputText, images))
 
-	// Apply model override if specified
+	// Create a custom config based on the persona and hashtag options
+	customCfg := c.cfg.WithPersona(options.Persona)
+
+	// Apply model override if specified, the model might be served by another provider
 	if options.Model != "" {
 		customCfg.Model = options.Model
 	}
+	customCfg = customCfg.ForModel(customCfg.Model)
 
 	// Apply reasoning effort if specified
 	if options.ReasoningEffort == "none" {
@@ -229,19 +299,11 @@ func (c *openaiCommand) callAndStore(messages []ChatMessage, storageIdentifier s
 		customCfg.ReasoningEffort = options.ReasoningEffort
 	}
 
-	messages, inputTokens, truncatedMessages := truncateMessages(customCfg.Model, messages)
-	if truncatedMessages > 0 {
-		// Build message options based on NoThread setting
-		msgOptions := []slack.MsgOption{}
-		if !options.NoThread || message.GetThread() != "" {
-			// Normal behavior: reply in thread
-			msgOptions = append(msgOptions, slack.MsgOptionTS(message.GetTimestamp()))
-		}
-		c.SendMessage(
-			message,
-			fmt.Sprintf("Note: The token length of %d for model %s exceeded! %d messages were not sent", getMaxTokensForModel(customCfg.Model), customCfg.Model, truncatedMessages),
-			msgOptions...,
-		)
+	// Build message options based on NoThread setting
+	msgOptions := []slack.MsgOption{}
+	if !options.NoThread || message.GetThread() != "" {
+		// Normal behavior: reply in thread
+		msgOptions = append(msgOptions, slack.MsgOptionTS(message.GetTimestamp()))
 	}
 
 	// wait for the full event stream in the background to not block other user requests
@@ -261,23 +323,67 @@ func (c *openaiCommand) callAndStore(messages []ChatMessage, storageIdentifier s
 
 		startTime := time.Now()
 
+		// add the matching excerpts of the knowledge base as context right before the question
+		var knowledgeChunks []knowledgeChunk
+		var knowledgeMessage ChatMessage
+		if c.knowledge != nil && inputText != "" {
+			chunks, err := c.knowledge.search(inputText)
+			if err != nil {
+				log.Warnf("openai knowledge base search failed: %s", err)
+			} else if len(chunks) > 0 {
+				knowledgeChunks = chunks
+				knowledgeMessage = knowledgeContext(knowledgeChunks)
+				messages = slices.Insert(messages, len(messages)-1, knowledgeMessage)
+			}
+		}
+
+		maxTokens := customCfg.GetContextLimit(customCfg.Model)
+		var droppedMessages []ChatMessage
+		messages, droppedMessages, _ = truncateMessages(customCfg.Model, maxTokens, messages)
+
+		// the dropped messages are summarized instead of silently discarding them
+		if len(droppedMessages) > 0 {
+			result := "summarized"
+			summarizedMessages, err := c.summarizeHistory(customCfg, message, messages, droppedMessages, maxTokens/historySummaryRatio)
+			if err != nil {
+				log.Warnf("Error while summarizing the openai history: %s", err)
+				result = "not sent"
+			} else {
+				messages = summarizedMessages
+			}
+
+			c.SendMessage(
+				message,
+				fmt.Sprintf("Note: The token length of %d for model %s exceeded! %d older messages were %s", maxTokens, customCfg.Model, len(droppedMessages), result),
+				msgOptions...,
+			)
+		}
+
 		// Determine if streaming should be used based on hashtag option
 		useStreaming := !options.NoStreaming
 
 		// Use customCfg instead of c.cfg
-		response, err := CallChatGPT(customCfg, messages, useStreaming)
+		var response <-chan string
+		var usage Usage
+		var err error
+
+		if c.tools != nil && customCfg.isOpenAICompatible() {
+			response, usage, err = c.callWithTools(customCfg, messages, message)
+		} else {
+			response, err = callChatGPT(customCfg, messages, useStreaming, func(callUsage Usage, _ error) {
+				usage = callUsage
+			})
+		}
 		if err != nil {
+			if usage != (Usage{}) {
+				// the tokens of the already executed tool calls
+				recordUsage(c.cfg.Usage, message.GetUser(), message.GetChannel(), customCfg.Model, usage)
+			}
 			c.ReplyError(message, fmt.Errorf("openai error: %w", err))
 			return
 		}
 
 		// Create a dummy message which gets updated every X seconds
-		// Build message options based on NoThread setting
-		msgOptions := []slack.MsgOption{}
-		if !options.NoThread || message.GetThread() != "" {
-			// Normal behavior: reply in thread
-			msgOptions = append(msgOptions, slack.MsgOptionTS(message.GetTimestamp()))
-		}
 		replyRef := c.SendMessage(
 			message,
 			":bulb: thinking...",
@@ -291,7 +397,6 @@ func (c *openaiCommand) callAndStore(messages []ChatMessage, storageIdentifier s
 
 		var lastUpdate time.Time
 		var dirty bool
-		var outputTokens int
 		for delta := range response {
 			// On first token received (including empty deltas), switch from bulb (thinking) to speech_balloon (streaming response)
 			if !speechBalloonAdded {
@@ -303,7 +408,6 @@ func (c *openaiCommand) callAndStore(messages []ChatMessage, storageIdentifier s
 			// Only append non-empty content to avoid issues with role-only deltas
 			if delta != "" {
 				chunker.appendContent(delta)
-				outputTokens++
 				dirty = true
 				if chunker.getTotalLength() > 0 && lastUpdate.Add(c.cfg.UpdateInterval).Before(time.Now()) {
 					lastUpdate = time.Now()
@@ -313,11 +417,30 @@ func (c *openaiCommand) callAndStore(messages []ChatMessage, storageIdentifier s
 			}
 		}
 
+		// cite the used sources of the knowledge base
+		if len(knowledgeChunks) > 0 {
+			chunker.appendContent(knowledgeSources(knowledgeChunks))
+			dirty = true
+		}
+
 		// update with the final message to make sure everything is formatted properly
 		if dirty {
 			chunker.updateMessages()
 		}
 
+		// record the used tokens, estimated if the API didn't return them
+		usage = usage.orEstimate(customCfg.Model, messages, chunker.getFullText())
+		recordUsage(c.cfg.Usage, message.GetUser(), message.GetChannel(), customCfg.Model, usage)
+		inputTokens := usage.InputTokens
+		outputTokens := usage.OutputTokens
+
+		// the knowledge base context is only relevant for the current question, it's searched again for the next one
+		if len(knowledgeChunks) > 0 {
+			messages = slices.DeleteFunc(messages, func(chatMessage ChatMessage) bool {
+				return chatMessage.Role == roleSystem && chatMessage.Content == knowledgeMessage.Content
+			})
+		}
+
 		// Store the last X chat history entries for further questions
 		messages = append(messages, ChatMessage{
 			Role:    roleAssistant,
@@ -329,10 +452,13 @@ func (c *openaiCommand) callAndStore(messages []ChatMessage, storageIdentifier s
 			messages = messages[len(messages)-c.cfg.HistorySize:]
 		}
 
-		err = storage.Write(storageKey, storageIdentifier, messages)
+		err = storage.Write(storageKey, storageIdentifier, withoutImages(messages))
 		if err != nil {
 			log.Warnf("Error while storing openai history: %s", err)
 		}
+		if options.Persona != "" {
+			_ = storage.Write(personaStorageKey, storageIdentifier, options.Persona)
+		}
 
 		// log some stats in the end
 		stats.IncreaseOne("openai_calls")
@@ -400,17 +526,29 @@ func getIdentifier(channel string, threadTS string) string {
 	return strings.ReplaceAll(identifier, ".", "_")
 }
 
-// GetTemplateFunction makes "chatgpt" available as template function for custom commands
+// GetTemplateFunction makes "chatgpt" available as template function for custom commands.
+// Optionally a persona can be passed: {{ openai "summarize the incident" "oncall" }}
 func (c *openaiCommand) GetTemplateFunction() template.FuncMap {
 	return template.FuncMap{
-		"openai": func(input string) string {
-			message := []ChatMessage{
-				{
-					Role:    roleUser,
-					Content: input,
-				},
+		"openai": func(input string, persona ...string) string {
+			cfg := c.cfg
+			message := make([]ChatMessage, 0, 2)
+			if len(persona) > 0 {
+				if _, ok := cfg.GetPersona(persona[0]); !ok {
+					return fmt.Sprintf("unknown persona %s", persona[0])
+				}
+
+				cfg = cfg.WithPersona(persona[0])
+				message = append(message, ChatMessage{
+					Role:    roleSystem,
+					Content: cfg.InitialSystemMessage,
+				})
 			}
-			responses, err := CallChatGPT(c.cfg, message, false)
+			message = append(message, ChatMessage{
+				Role:    roleUser,
+				Content: input,
+			})
+			responses, err := CallChatGPT(cfg, message, false)
 			if err != nil {
 				return err.Error()
 			}
@@ -421,26 +559,13 @@ func (c *openaiCommand) GetTemplateFunction() template.FuncMap {
 	}
 }
 
-// loadTextAttachments downloads text file attachments and returns them formatted
+// loadTextAttachments returns the content of the attached text files and PDFs, images are ignored
 func (c *openaiCommand) loadTextAttachments(files []slack.File) string {
-	var result strings.Builder
-	for _, file := range files {
-		if !strings.HasPrefix(file.Mimetype, "text/") {
-			log.Infof("Skipping attachment %s: mimetype is %s", file.Name, file.Mimetype)
-			continue
-		}
-
-		var buf bytes.Buffer
-		log.Infof("Downloading attachment %s", file.Name)
-
-		if err := c.GetFile(file.URLPrivate, &buf); err != nil {
-			log.Warnf("Failed to download attachment %s: %v", file.Name, err)
-			continue
-		}
+	files = slices.DeleteFunc(slices.Clone(files), func(file slack.File) bool {
+		return slices.Contains(imageMimeTypes, file.Mimetype)
+	})
 
-		fmt.Fprintf(&result, " <Attachment filename=\"%s\">%s</Attachment>", file.Name, buf.String())
-	}
-	return result.String()
+	return c.loadAttachments(files, true).text
 }
 
 // getChannelHistory fetches the last N messages from a channel (including thread messages and text attachments)
@@ -455,9 +580,14 @@ func (c *openaiCommand) getChannelHistory(channel string, count int) ([]slack.Me
 		return nil, err
 	}
 
+	return c.addThreadReplies(channel, response.Messages), nil
+}
+
+// addThreadReplies adds the thread replies and text attachments to the channel messages (newest first) and returns them in chronological order
+func (c *openaiCommand) addThreadReplies(channel string, messages []slack.Message) []slack.Message {
 	// Collect main channel messages and their thread replies
 	allMessages := make([]slack.Message, 0)
-	for _, message := range response.Messages {
+	for _, message := range messages {
 		if len(message.Files) > 0 {
 			message.Text += c.loadTextAttachments(message.Files)
 		}
@@ -491,14 +621,14 @@ func (c *openaiCommand) getChannelHistory(channel string, count int) ([]slack.Me
 	// Reverse to get chronological order (API returns newest first)
 	slices.Reverse(allMessages)
 
-	return allMessages, nil
+	return allMessages
 }
 
 func (c *openaiCommand) GetHelp() []bot.Help {
-	return []bot.Help{
+	help := []bot.Help{
 		{
 			Command:     "openai <question>",
-			Description: "Starts a chatgpt/openai conversation in a new thread. Text file attachments are automatically included in the context. Supports hashtags for advanced options: \n- #model-<name> (e.g., #model-gpt-4o), \n- #high-thinking/#medium-thinking/#low-thinking/#no-thinking for reasoning control\n- #message-history or #message-history-<N> to include recent channel messages as context\n- #no-streaming to disable streaming and get the full response at once\n- #no-thread to reply directly without creating a thread\n- #debug to show debug information about the request",
+			Description: "Starts a chatgpt/openai conversation in a new thread. Attached text files, PDFs and images are automatically included in the context. Supports hashtags for advanced options: \n- #model-<name> (e.g., #model-gpt-4o), \n- #high-thinking/#medium-thinking/#low-thinking/#no-thinking for reasoning control\n- #message-history or #message-history-<N> to include recent channel messages as context\n- #no-streaming to disable streaming and get the full response at once\n- #no-thread to reply directly without creating a thread\n- #persona-<name> to use a configured persona\n- #debug to show debug information about the request",
 			Category:    category,
 			Examples: []string{
 				"openai why is the sky blue?",
@@ -512,14 +642,73 @@ func (c *openaiCommand) GetHelp() []bot.Help {
 		},
 		{
 			Command:     "dalle <prompt>",
-			Description: "Generates an image with Dall-E",
+			Description: "Generates an image with Dall-E. Supports hashtags to override the settings: #size-<size> (e.g. #size-1792x1024), #quality-<quality> (e.g. #quality-hd) and #count-<n> (max 10, dall-e-3 only creates one image)",
 			Category:    category,
 			Examples: []string{
 				"dalle high resolution image of a sunset, painted by a robot",
 				"dall-e high resolution image of a sunset, painted by a robot",
+				"dalle #size-1792x1024 #quality-hd a panorama of the alps",
+			},
+		},
+		{
+			Command:     "edit image <prompt>",
+			Description: "Edits the attached image as described. An additional attached image with \"mask\" in its name defines the area to edit (transparent pixels)",
+			Category:    category,
+			Examples: []string{
+				"edit image add a red hat to the cat",
+				"edit image #count-2 replace the background with a beach",
+			},
+		},
+		{
+			Command:     "image variations",
+			Description: "Creates variations of the attached image",
+			Category:    category,
+			Examples: []string{
+				"image variations",
+				"image variations #count-3 #size-512x512",
+			},
+		},
+		{
+			Command:     "summarize thread [link]",
+			Description: "Summarizes a thread with the decisions, open questions and action items. Without a link, the current thread is summarized",
+			Category:    category,
+			Examples: []string{
+				"summarize thread https://myworkspace.slack.com/archives/C12345678/p1234567890123456",
+				"summarize thread",
+			},
+		},
+		{
+			Command:     "digest #channel [period]",
+			Description: "Summarizes all messages of a channel of the last 24h or the given period. Can be scheduled via crons to post a daily digest into another channel",
+			Category:    category,
+			Examples: []string{
+				"digest #dev",
+				"digest #dev 7d",
+			},
+		},
+		{
+			Command:     "openai usage [period]",
+			Description: "Shows the used tokens and estimated costs per user, channel and model (admin only)",
+			Category:    category,
+			Examples: []string{
+				"openai usage",
+				"openai usage 7d",
 			},
 		},
 	}
+
+	if c.knowledge != nil {
+		help = append(help, bot.Help{
+			Command:     "reindex knowledge",
+			Description: "Rebuilds the index of the knowledge base which is used to answer questions (admin only)",
+			Category:    category,
+			Examples: []string{
+				"reindex knowledge",
+			},
+		})
+	}
+
+	return help
 }
 
 // help category to group all AI command
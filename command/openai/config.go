@@ -1,6 +1,7 @@
 package openai
 
 import (
+	"strings"
 	"time"
 
 	"github.com/innogames/slack-bot/v2/bot/config"
@@ -8,8 +9,11 @@ import (
 
 // Config configuration: API key to do API calls
 type Config struct {
+	// LLM provider: "openai" (default), "azure", "anthropic" or "ollama"
+	Provider string `mapstructure:"provider"`
+
 	APIKey               string  `mapstructure:"api_key"`
-	APIHost              string  `mapstructure:"api_host"`
+	APIHost              string  `mapstructure:"api_host"` // default depends on the provider, required for "azure"
 	InitialSystemMessage string  `mapstructure:"initial_system_message"`
 	Model                string  `mapstructure:"model"`
 	Temperature          float32 `mapstructure:"temperature"`
@@ -17,6 +21,22 @@ type Config struct {
 	MaxTokens            int     `mapstructure:"max_tokens"`
 	ReasoningEffort      string  `mapstructure:"reasoning_effort"` // "minimum, "low", "medium", "high" or empty for default
 
+	// Azure OpenAI only: name of the deployment (default: the model) and the API version
+	Deployment string `mapstructure:"deployment"`
+	APIVersion string `mapstructure:"api_version"`
+
+	// additional providers which are used for the listed models, e.g. when using the #model-<name> hashtag
+	Providers []ProviderConfig `mapstructure:"providers"`
+
+	// named personas with their own system message and model settings, selectable via #persona-<name>
+	Personas map[string]Persona `mapstructure:"personas"`
+
+	// default persona per channel: channel id or name -> persona name
+	ChannelPersonas map[string]string `mapstructure:"channel_personas"`
+
+	// context window per model, overrides the built-in limits
+	ContextLimits []ContextLimit `mapstructure:"context_limits"`
+
 	// number of thread messages stored which are used as a context for further requests
 	HistorySize int `mapstructure:"history_size"`
 
@@ -29,6 +49,9 @@ type Config struct {
 	// timeout for API requests to OpenAI
 	APITimeout time.Duration `mapstructure:"api_timeout"`
 
+	// max size in bytes of attached files (images, PDFs, text files) which are passed to the model
+	MaxAttachmentSize int `mapstructure:"max_attachment_size"`
+
 	// log all input+output text to the logger. This could include personal information, therefore disabled by default!
 	LogTexts bool `mapstructure:"log_texts"`
 
@@ -37,28 +60,143 @@ type Config struct {
 	DalleImageSize      string `mapstructure:"dalle_image_size"`
 	DalleNumberOfImages int    `mapstructure:"dalle_number_of_images"`
 	DalleQuality        string `mapstructure:"dalle_quality"`
+	DalleEditModel      string `mapstructure:"dalle_edit_model"` // used for "edit image", e.g. "dall-e-2" or "gpt-image-1"
+
+	// expose whitelisted bot commands as tools which can be called by the model
+	Tools ToolsConfig `mapstructure:"tools"`
+
+	// answer questions with the help of local documents, like runbooks
+	KnowledgeBase KnowledgeBaseConfig `mapstructure:"knowledge_base"`
+
+	// prices of the models and budgets per user
+	Usage UsageConfig `mapstructure:"usage"`
+}
+
+// ToolsConfig defines which bot commands can be executed by the model, e.g. "pool list" or "jira"
+type ToolsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// command prefixes which are executed directly
+	Commands []string `mapstructure:"commands"`
+
+	// command prefixes which are only executed after the user confirmed them via a button
+	DangerousCommands []string `mapstructure:"dangerous_commands"`
+}
+
+// IsEnabled checks if tool calling is active and at least one command is whitelisted
+func (c ToolsConfig) IsEnabled() bool {
+	return c.Enabled && len(c.Commands)+len(c.DangerousCommands) > 0
+}
+
+// ContextLimit is the max number of input tokens of a model. The model is a prefix, the longest matching one is used.
+type ContextLimit struct {
+	Model  string `mapstructure:"model"`
+	Tokens int    `mapstructure:"tokens"`
 }
 
-// IsEnabled checks if token is set
+// GetContextLimit returns the max number of input tokens of the model, the configured limit or the built-in one
+func (c Config) GetContextLimit(model string) int {
+	var limit ContextLimit
+	for _, current := range c.ContextLimits {
+		if strings.HasPrefix(model, current.Model) && len(current.Model) >= len(limit.Model) && current.Tokens > 0 {
+			limit = current
+		}
+	}
+
+	if limit.Tokens > 0 {
+		return limit.Tokens
+	}
+
+	return getMaxTokensForModel(model)
+}
+
+// ProviderConfig defines an additional LLM provider, which is used for the given models
+type ProviderConfig struct {
+	Provider   string `mapstructure:"provider"`
+	APIKey     string `mapstructure:"api_key"`
+	APIHost    string `mapstructure:"api_host"`
+	Deployment string `mapstructure:"deployment"`
+	APIVersion string `mapstructure:"api_version"`
+
+	// model names, a trailing "*" matches all models with the prefix, like "claude-*"
+	Models []string `mapstructure:"models"`
+}
+
+func (p ProviderConfig) hasModel(model string) bool {
+	for _, pattern := range p.Models {
+		if prefix, found := strings.CutSuffix(pattern, "*"); found {
+			if strings.HasPrefix(model, prefix) {
+				return true
+			}
+		} else if pattern == model {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsEnabled checks if token is set. A local Ollama doesn't need a token.
 func (c *Config) IsEnabled() bool {
-	return c.APIKey != ""
+	return c.APIKey != "" || strings.EqualFold(c.Provider, providerOllama)
+}
+
+// ForModel returns the config for the given model, using the matching provider of the "providers" list
+func (c Config) ForModel(model string) Config {
+	c.Model = model
+
+	for _, provider := range c.Providers {
+		if provider.hasModel(model) {
+			c.Provider = provider.Provider
+			c.APIKey = provider.APIKey
+			c.APIHost = provider.APIHost
+			c.Deployment = provider.Deployment
+			c.APIVersion = provider.APIVersion
+			break
+		}
+	}
+
+	return c
+}
+
+// isOpenAICompatible checks if the provider uses the OpenAI wire format, which is needed for tools and Dall-E
+func (c Config) isOpenAICompatible() bool {
+	switch strings.ToLower(c.Provider) {
+	case "", providerOpenAI, providerAzure:
+		return true
+	default:
+		return false
+	}
 }
 
 var defaultConfig = Config{
-	APIHost:              apiHost,
 	Model:                "gpt-5.2", // aka model behind ChatGPT
 	UpdateInterval:       time.Second * 1,
 	APITimeout:           time.Second * 120,
 	HistorySize:          25,
+	MaxAttachmentSize:    5 * 1024 * 1024,
 	InitialSystemMessage: "You are a helpful Slack bot. By default, keep your answer short and truthful",
 
 	// default dall-e config
 	DalleModel:          "dall-e-3",
 	DalleImageSize:      "1024x1024",
 	DalleNumberOfImages: 1,
+	DalleEditModel:      "dall-e-2",
+
+	KnowledgeBase: KnowledgeBaseConfig{
+		EmbeddingModel: "text-embedding-3-small",
+		ChunkSize:      1500,
+		TopK:           3,
+		MinScore:       0.3,
+	},
+
+	Usage: UsageConfig{
+		Prices: defaultPrices,
+	},
 }
 
-func loadConfig(config *config.Config) Config {
+// LoadConfig loads the "openai" config section, using the defaults for missing values
+func LoadConfig(config *config.Config) Config {
 	cfg := defaultConfig
 	_ = config.LoadCustom("openai", &cfg)
 
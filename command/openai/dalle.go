@@ -1,11 +1,21 @@
 package openai
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/innogames/slack-bot/v2/bot/matcher"
@@ -17,19 +27,148 @@ import (
 	"github.com/slack-go/slack"
 )
 
+// variations are only supported by dall-e-2
+const dalleVariationModel = "dall-e-2"
+
+// max number of images per request: dall-e-3 only creates one image, the other models up to 10
+const (
+	maxImageCount       = 10
+	maxDalle3ImageCount = 1
+)
+
+// escapes the file name in the Content-Disposition header, like in mime/multipart
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// hashtags to override the image settings per request, like "dalle #size-1024x1792 #quality-hd #count-2 a cat"
+var (
+	imageSizeRe    = regexp.MustCompile(`#size-(\d+x\d+|auto)`)
+	imageQualityRe = regexp.MustCompile(`#quality-(\w+)`)
+	imageCountRe   = regexp.MustCompile(`#count-(\d+)`)
+)
+
+// parseImageHashtags applies the #size-<size>, #quality-<quality> and #count-<n> hashtags to the config.
+// It returns the text without them and the used hashtags, to keep them for a new request.
+func parseImageHashtags(text string, cfg Config) (string, string, Config) {
+	hashtags := make([]string, 0)
+
+	if matches := imageSizeRe.FindStringSubmatch(text); matches != nil {
+		cfg.DalleImageSize = matches[1]
+		hashtags = append(hashtags, matches[0])
+		text = imageSizeRe.ReplaceAllString(text, "")
+	}
+
+	if matches := imageQualityRe.FindStringSubmatch(text); matches != nil {
+		cfg.DalleQuality = matches[1]
+		hashtags = append(hashtags, matches[0])
+		text = imageQualityRe.ReplaceAllString(text, "")
+	}
+
+	if matches := imageCountRe.FindStringSubmatch(text); matches != nil {
+		if count, err := strconv.Atoi(matches[1]); err == nil && count > 0 {
+			cfg.DalleNumberOfImages = min(count, maxImageCount)
+			hashtags = append(hashtags, "#count-"+strconv.Itoa(cfg.DalleNumberOfImages))
+		}
+		text = imageCountRe.ReplaceAllString(text, "")
+	}
+
+	return strings.Join(strings.Fields(text), " "), strings.Join(hashtags, " "), cfg
+}
+
+// getImageCount returns the number of images to create, limited to the max number of the model
+func getImageCount(model string, count int) int {
+	limit := maxImageCount
+	if strings.HasPrefix(model, "dall-e-3") {
+		limit = maxDalle3ImageCount
+	}
+
+	return max(min(count, limit), 1)
+}
+
 // bot function to generate images with Dall-E
 func (c *openaiCommand) dalleGenerateImage(match matcher.Result, message msg.Message) {
-	// run the generation in the background and as it could take some time
+	prompt, hashtags, cfg := parseImageHashtags(match.GetString(util.FullMatch), c.cfg)
+
+	c.processImages(message, cfg.DalleModel, imagePrompt{prompt, hashtags}, func() ([]DalleResponseImage, error) {
+		return generateImages(cfg, prompt)
+	})
+}
+
+// bot function to edit an attached image, an attached image with "mask" in the name is used as mask
+func (c *openaiCommand) dalleEditImage(match matcher.Result, message msg.Message) {
+	prompt, _, cfg := parseImageHashtags(match.GetString(util.FullMatch), c.cfg)
+	if prompt == "" {
+		c.ReplyError(message, errors.New("please describe how the image should be edited"))
+		return
+	}
+
+	images, err := c.loadImageFiles(message)
+	if err != nil {
+		c.ReplyError(message, err)
+		return
+	}
+
+	var mask *imageFile
+	maskIdx := slices.IndexFunc(images, func(image imageFile) bool {
+		return strings.Contains(strings.ToLower(image.name), "mask")
+	})
+	if maskIdx != -1 && len(images) > 1 {
+		maskImage := images[maskIdx]
+		mask = &maskImage
+		images = slices.Delete(images, maskIdx, maskIdx+1)
+	}
+
+	c.processImages(message, cfg.DalleEditModel, imagePrompt{}, func() ([]DalleResponseImage, error) {
+		return editImage(cfg, prompt, images[0], mask)
+	})
+}
+
+// bot function to create variations of an attached image
+func (c *openaiCommand) dalleImageVariations(match matcher.Result, message msg.Message) {
+	_, _, cfg := parseImageHashtags(match.GetString(util.FullMatch), c.cfg)
+
+	images, err := c.loadImageFiles(message)
+	if err != nil {
+		c.ReplyError(message, err)
+		return
+	}
+
+	c.processImages(message, dalleVariationModel, imagePrompt{}, func() ([]DalleResponseImage, error) {
+		return createImageVariations(cfg, images[0])
+	})
+}
+
+// imagePrompt is the prompt of a generated image with the hashtags of the request, used for the "New" buttons
+type imagePrompt struct {
+	text     string
+	hashtags string
+}
+
+// getCommand returns the command to generate a new image with the given prompt and the same hashtags
+func (p imagePrompt) getCommand(prompt string) string {
+	if p.hashtags == "" {
+		return "dall-e " + prompt
+	}
+
+	return "dall-e " + p.hashtags + " " + prompt
+}
+
+// processImages runs the image request in the background, as it could take some time, and uploads the result into the thread
+func (c *openaiCommand) processImages(message msg.Message, model string, prompt imagePrompt, request func() ([]DalleResponseImage, error)) {
+	if budgetMessage := checkBudget(c.cfg.Usage, message.GetUser(), time.Now()); budgetMessage != "" {
+		c.SendMessage(message, budgetMessage, slack.MsgOptionTS(message.GetTimestamp()))
+		return
+	}
+
 	c.AddReaction(":coffee:", message)
 
 	go func() {
-		prompt := match.GetString(util.FullMatch)
-		images, err := generateImages(c.cfg, prompt)
+		images, err := request()
 		c.RemoveReaction(":coffee:", message)
 		if err != nil {
 			c.ReplyError(message, err)
 			return
 		}
+		recordUsage(c.cfg.Usage, message.GetUser(), message.GetChannel(), model, Usage{Images: len(images)})
 
 		// add 📤 emoji to indicate that the image is being uploaded which can take some time via slack
 		c.AddReaction(":outbox_tray:", message)
@@ -49,34 +188,61 @@ func (c *openaiCommand) dalleGenerateImage(match matcher.Result, message msg.Mes
 	}()
 }
 
-func (c *openaiCommand) sendImageInSlack(image DalleResponseImage, message msg.Message, prompt string) error {
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, image.URL, nil)
-	if err != nil {
-		return err
+// sendImageInSlack uploads the image into the thread. With a given prompt, buttons to generate new images are attached.
+func (c *openaiCommand) sendImageInSlack(image DalleResponseImage, message msg.Message, prompt imagePrompt) error {
+	var reader io.Reader
+	var fileSize int
+	if image.B64JSON != "" {
+		data, err := base64.StdEncoding.DecodeString(image.B64JSON)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+		fileSize = len(data)
+	} else {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, image.URL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := httpClient.Do(req) // #nosec G704
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		reader = resp.Body
+		fileSize = int(resp.ContentLength)
 	}
-	resp, err := httpClient.Do(req) // #nosec G704
-	if err != nil {
-		return err
+
+	var comment string
+	if image.RevisedPrompt != "" {
+		comment = "Dall-e prompt: " + image.RevisedPrompt
 	}
-	defer resp.Body.Close()
 
-	_, err = c.UploadFile(slack.UploadFileParameters{
+	_, err := c.UploadFile(slack.UploadFileParameters{
 		Filename:        "dalle.png",
-		FileSize:        int(resp.ContentLength),
-		Reader:          resp.Body,
+		FileSize:        fileSize,
+		Reader:          reader,
 		Channel:         message.Channel,
 		ThreadTimestamp: message.Timestamp,
-		InitialComment:  "Dall-e prompt: " + image.RevisedPrompt,
+		InitialComment:  comment,
 	})
 
+	if prompt.text == "" {
+		return err
+	}
+
+	buttons := []slack.BlockElement{
+		client.GetInteractionButton("dalle_original", "New (original prompt)", prompt.getCommand(prompt.text)),
+	}
+	if image.RevisedPrompt != "" {
+		buttons = append(buttons, client.GetInteractionButton("dalle_advanced", "New (advanced prompt)", prompt.getCommand(image.RevisedPrompt)))
+	}
+
 	c.SendBlockMessage(
 		message,
 		[]slack.Block{
-			slack.NewActionBlock(
-				"",
-				client.GetInteractionButton("dalle_original", "New (original prompt)", "dall-e "+prompt),
-				client.GetInteractionButton("dalle_advanced", "New (advanced prompt)", "dall-e "+image.RevisedPrompt),
-			),
+			slack.NewActionBlock("", buttons...),
 		},
 		slack.MsgOptionTS(message.Timestamp),
 	)
@@ -84,11 +250,53 @@ func (c *openaiCommand) sendImageInSlack(image DalleResponseImage, message msg.M
 	return err
 }
 
+// imageFile is an image which was attached to a Slack message
+type imageFile struct {
+	name     string
+	mimeType string
+	data     []byte
+}
+
+// loadImageFiles downloads the images which are attached to the message
+func (c *openaiCommand) loadImageFiles(message msg.Message) ([]imageFile, error) {
+	images := make([]imageFile, 0)
+	for _, file := range message.Files {
+		if !slices.Contains(imageMimeTypes, file.Mimetype) {
+			continue
+		}
+
+		if c.cfg.MaxAttachmentSize > 0 && file.Size > c.cfg.MaxAttachmentSize {
+			return nil, fmt.Errorf("the image %s is bigger than %s", file.Name, util.FormatBytes(uint64(c.cfg.MaxAttachmentSize)))
+		}
+
+		var buf bytes.Buffer
+		if err := c.GetFile(file.URLPrivate, &buf); err != nil {
+			return nil, fmt.Errorf("failed to download the image %s: %w", file.Name, err)
+		}
+
+		images = append(images, imageFile{
+			name:     file.Name,
+			mimeType: file.Mimetype,
+			data:     buf.Bytes(),
+		})
+	}
+
+	if len(images) == 0 {
+		return nil, errors.New("please attach an image to the message")
+	}
+
+	return images, nil
+}
+
 func generateImages(cfg Config, prompt string) ([]DalleResponseImage, error) {
+	if !cfg.isOpenAICompatible() {
+		return nil, fmt.Errorf("image generation is not supported by provider %s", cfg.Provider)
+	}
+
 	jsonData, _ := json.Marshal(DalleRequest{
 		Model:   cfg.DalleModel,
 		Size:    cfg.DalleImageSize,
-		N:       cfg.DalleNumberOfImages,
+		N:       getImageCount(cfg.DalleModel, cfg.DalleNumberOfImages),
 		Quality: cfg.DalleQuality,
 		Prompt:  prompt,
 	})
@@ -103,8 +311,104 @@ func generateImages(cfg Config, prompt string) ([]DalleResponseImage, error) {
 	}
 	defer resp.Body.Close()
 
+	images, err := parseImageResponse(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithField("model", cfg.DalleModel).
+		Infof("Dall-E image generation took %s", time.Since(start))
+
+	return images, nil
+}
+
+// editImage changes the image as described in the prompt, only the transparent areas of the mask are edited
+func editImage(cfg Config, prompt string, image imageFile, mask *imageFile) ([]DalleResponseImage, error) {
+	fields := map[string]string{
+		"model":  cfg.DalleEditModel,
+		"prompt": prompt,
+		"n":      strconv.Itoa(getImageCount(cfg.DalleEditModel, cfg.DalleNumberOfImages)),
+		"size":   cfg.DalleImageSize,
+	}
+	if cfg.DalleQuality != "" {
+		fields["quality"] = cfg.DalleQuality
+	}
+
+	files := map[string]imageFile{"image": image}
+	if mask != nil {
+		files["mask"] = *mask
+	}
+
+	return sendImageForm(cfg, apiDalleEditImageURL, fields, files)
+}
+
+// createImageVariations creates new images which are similar to the given one
+func createImageVariations(cfg Config, image imageFile) ([]DalleResponseImage, error) {
+	fields := map[string]string{
+		"model": dalleVariationModel,
+		"n":     strconv.Itoa(getImageCount(dalleVariationModel, cfg.DalleNumberOfImages)),
+		"size":  cfg.DalleImageSize,
+	}
+
+	return sendImageForm(cfg, apiDalleVariationURL, fields, map[string]imageFile{"image": image})
+}
+
+// sendImageForm sends the fields and images as multipart/form-data, as needed by the edits and variations endpoints
+func sendImageForm(cfg Config, apiEndpoint string, fields map[string]string, files map[string]imageFile) ([]DalleResponseImage, error) {
+	if !cfg.isOpenAICompatible() {
+		return nil, fmt.Errorf("image editing is not supported by provider %s", cfg.Provider)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		if err := writer.WriteField(name, fields[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, name, quoteEscaper.Replace(files[name].name)))
+		header.Set("Content-Type", files[name].mimeType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = part.Write(files[name].data); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := doRequestWithContentType(cfg, apiEndpoint, writer.FormDataContentType(), &body)
+	if err != nil {
+		log.WithError(err).
+			WithField("endpoint", apiEndpoint).
+			Error("Dall-E image request failed")
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	images, err := parseImageResponse(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithField("model", fields["model"]).
+		Infof("Dall-E image request %s took %s", apiEndpoint, time.Since(start))
+
+	return images, nil
+}
+
+// parseImageResponse returns the generated images or the error message of the API
+func parseImageResponse(body io.Reader) ([]DalleResponseImage, error) {
 	var response DalleResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
+	err := json.NewDecoder(body).Decode(&response)
 	if err != nil {
 		return nil, err
 	}
@@ -113,8 +417,6 @@ func generateImages(cfg Config, prompt string) ([]DalleResponseImage, error) {
 		return nil, errors.New(response.Error.Message)
 	}
 
-	log.WithField("model", cfg.DalleModel).
-		Infof("Dall-E image generation took %s", time.Since(start))
 	stats.Increase("openai_dalle_images", len(response.Data))
 
 	return response.Data, nil
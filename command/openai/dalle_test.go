@@ -1,7 +1,10 @@
 package openai
 
 import (
+	"encoding/base64"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -9,10 +12,12 @@ import (
 	"github.com/innogames/slack-bot/v2/bot/config"
 	"github.com/innogames/slack-bot/v2/bot/msg"
 	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/bot/util"
 	"github.com/innogames/slack-bot/v2/mocks"
 	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDalle(t *testing.T) {
@@ -46,7 +51,7 @@ func TestDalle(t *testing.T) {
 
 		defer ts.Close()
 
-		commands := GetCommands(base, cfg)
+		commands := GetCommands(base, cfg, nil)
 
 		message := msg.Message{}
 		message.Text = "dalle a nice cat"
@@ -66,7 +71,7 @@ func TestDalle(t *testing.T) {
 			apiDalleGenerateImageURL,
 			[]testRequest{
 				{
-					`{"model":"dall-e-3","prompt":"a nice cat","n":1,"size":"1024x1024"}`,
+					`{"model":"dall-e-3","prompt":"a nice cat","n":1,"size":"1792x1024"}`,
 					`	{
 						  "created": 1700233554,
 						  "data": [
@@ -91,20 +96,25 @@ func TestDalle(t *testing.T) {
 
 		defer ts.Close()
 
-		commands := GetCommands(base, cfg)
+		commands := GetCommands(base, cfg, nil)
 
+		// dall-e-3 only creates one image per request
 		message := msg.Message{}
-		message.Text = "dalle a nice cat"
+		message.Text = "dalle #size-1792x1024 #count-3 a nice cat"
 
 		mocks.AssertReaction(slackClient, ":coffee:", message)
 		mocks.AssertRemoveReaction(slackClient, ":coffee:", message)
 		mocks.AssertReaction(slackClient, ":outbox_tray:", message)
-		mocks.AssertRemoveReaction(slackClient, ":outbox_tray:", message)
+		// removing the outbox reaction is the last step of the upload in the background
+		uploaded := make(chan struct{})
+		slackClient.On("RemoveReaction", util.Reaction(":outbox_tray:"), message).Once().Run(func(mock.Arguments) {
+			close(uploaded)
+		})
 		mocks.AssertSlackBlocks(
 			t,
 			slackClient,
 			message,
-			`[{"type":"actions","elements":[{"type":"button","text":{"type":"plain_text","text":"New (original prompt)","emoji":true},"action_id":"dalle_original","value":"dall-e a nice cat"},{"type":"button","text":{"type":"plain_text","text":"New (advanced prompt)","emoji":true},"action_id":"dalle_advanced","value":"dall-e revised prompt 1234"}]}]`,
+			`[{"type":"actions","elements":[{"type":"button","text":{"type":"plain_text","text":"New (original prompt)","emoji":true},"action_id":"dalle_original","value":"dall-e #size-1792x1024 #count-3 a nice cat"},{"type":"button","text":{"type":"plain_text","text":"New (advanced prompt)","emoji":true},"action_id":"dalle_advanced","value":"dall-e #size-1792x1024 #count-3 revised prompt 1234"}]}]`,
 		)
 
 		slackClient.On(
@@ -116,7 +126,202 @@ func TestDalle(t *testing.T) {
 		).Return(nil, nil).Once()
 
 		actual := commands.Run(message)
-		time.Sleep(time.Millisecond * 100)
 		assert.True(t, actual)
+
+		select {
+		case <-uploaded:
+		case <-time.After(5 * time.Second):
+			t.Fatal("image was not uploaded")
+		}
+	})
+}
+
+func TestDalleEdits(t *testing.T) {
+	storage.InitStorage("")
+
+	t.Run("Parse hashtags", func(t *testing.T) {
+		prompt, hashtags, cfg := parseImageHashtags("#size-1792x1024 a nice #quality-hd cat #count-2", defaultConfig)
+		assert.Equal(t, "a nice cat", prompt)
+		assert.Equal(t, "#size-1792x1024 #quality-hd #count-2", hashtags)
+		assert.Equal(t, "1792x1024", cfg.DalleImageSize)
+		assert.Equal(t, "hd", cfg.DalleQuality)
+		assert.Equal(t, 2, cfg.DalleNumberOfImages)
+
+		prompt, hashtags, cfg = parseImageHashtags("a nice cat #count-0", defaultConfig)
+		assert.Equal(t, "a nice cat", prompt)
+		assert.Empty(t, hashtags)
+		assert.Equal(t, defaultConfig.DalleImageSize, cfg.DalleImageSize)
+		assert.Empty(t, cfg.DalleQuality)
+		assert.Equal(t, 1, cfg.DalleNumberOfImages)
+
+		prompt, hashtags, cfg = parseImageHashtags("a nice cat #count-1000", defaultConfig)
+		assert.Equal(t, "a nice cat", prompt)
+		assert.Equal(t, "#count-10", hashtags)
+		assert.Equal(t, 10, cfg.DalleNumberOfImages)
+	})
+
+	t.Run("Image count", func(t *testing.T) {
+		assert.Equal(t, 1, getImageCount("dall-e-3", 4))
+		assert.Equal(t, 4, getImageCount("dall-e-2", 4))
+		assert.Equal(t, 10, getImageCount("gpt-image-1", 20))
+		assert.Equal(t, 1, getImageCount("dall-e-2", 0))
+	})
+
+	// the fake API checks the multipart form and returns base64 encoded images
+	var requests []*http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseMultipartForm(1024*1024))
+		requests = append(requests, req)
+
+		res.Write([]byte(`{"data":[{"b64_json":"` + base64.StdEncoding.EncodeToString([]byte("PNG")) + `"}]}`))
+	}))
+	defer ts.Close()
+
+	openaiCfg := defaultConfig
+	openaiCfg.APIKey = "0815pass"
+	openaiCfg.APIHost = ts.URL
+	cfg := &config.Config{}
+	cfg.Set("openai", openaiCfg)
+
+	readFormFile := func(req *http.Request, name string) string {
+		file, _, err := req.FormFile(name)
+		require.NoError(t, err)
+		defer file.Close()
+
+		content, _ := io.ReadAll(file)
+		return string(content)
+	}
+
+	mockFile := func(slackClient *mocks.SlackClient, url string, content string) {
+		slackClient.On("GetFile", url, mock.Anything).
+			Run(func(args mock.Arguments) {
+				args.Get(1).(io.Writer).Write([]byte(content))
+			}).
+			Return(nil).Once()
+	}
+
+	waitForUpload := func(slackClient *mocks.SlackClient, message msg.Message) chan struct{} {
+		uploaded := make(chan struct{})
+		slackClient.On("UploadFile", mock.MatchedBy(func(params slack.UploadFileParameters) bool {
+			content, _ := io.ReadAll(params.Reader)
+			return params.Filename == "dalle.png" && params.Channel == "C1234" && params.FileSize == 3 && string(content) == "PNG"
+		})).Return(nil, nil).Once()
+		mocks.AssertReaction(slackClient, ":coffee:", message)
+		mocks.AssertRemoveReaction(slackClient, ":coffee:", message)
+		mocks.AssertReaction(slackClient, ":outbox_tray:", message)
+		slackClient.On("RemoveReaction", util.Reaction(":outbox_tray:"), message).Once().Run(func(mock.Arguments) {
+			close(uploaded)
+		})
+
+		return uploaded
+	}
+
+	t.Run("Edit image with mask", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		commands := GetCommands(bot.BaseCommand{SlackClient: slackClient}, cfg, nil)
+		requests = nil
+
+		message := msg.Message{}
+		message.Text = "edit image #size-512x512 add a red hat"
+		message.Channel = "C1234"
+		message.Files = []slack.File{
+			{Name: "mask.png", Mimetype: "image/png", URLPrivate: "https://files.slack.com/mask.png"},
+			{Name: "cat.png", Mimetype: "image/png", URLPrivate: "https://files.slack.com/cat.png"},
+			{Name: "notes.txt", Mimetype: "text/plain", URLPrivate: "https://files.slack.com/notes.txt"},
+		}
+
+		mockFile(slackClient, "https://files.slack.com/mask.png", "MASK")
+		mockFile(slackClient, "https://files.slack.com/cat.png", "CAT")
+		uploaded := waitForUpload(slackClient, message)
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+		<-uploaded
+
+		require.Len(t, requests, 1)
+		assert.Equal(t, apiDalleEditImageURL, requests[0].URL.Path)
+		assert.Equal(t, "dall-e-2", requests[0].FormValue("model"))
+		assert.Equal(t, "add a red hat", requests[0].FormValue("prompt"))
+		assert.Equal(t, "512x512", requests[0].FormValue("size"))
+		assert.Equal(t, "1", requests[0].FormValue("n"))
+		assert.Empty(t, requests[0].FormValue("quality"))
+		assert.Equal(t, "CAT", readFormFile(requests[0], "image"))
+		assert.Equal(t, "MASK", readFormFile(requests[0], "mask"))
+	})
+
+	t.Run("Image variations", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		commands := GetCommands(bot.BaseCommand{SlackClient: slackClient}, cfg, nil)
+		requests = nil
+
+		message := msg.Message{}
+		message.Text = "image variations #count-2"
+		message.Channel = "C1234"
+		message.User = "UVARIATION"
+		message.Files = []slack.File{
+			{Name: "cat.jpg", Mimetype: "image/jpeg", URLPrivate: "https://files.slack.com/cat.jpg"},
+		}
+
+		mockFile(slackClient, "https://files.slack.com/cat.jpg", "CAT")
+		uploaded := waitForUpload(slackClient, message)
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+		<-uploaded
+
+		require.Len(t, requests, 1)
+		assert.Equal(t, apiDalleVariationURL, requests[0].URL.Path)
+		assert.Equal(t, "dall-e-2", requests[0].FormValue("model"))
+		assert.Equal(t, "2", requests[0].FormValue("n"))
+		assert.Equal(t, "CAT", readFormFile(requests[0], "image"))
+
+		// the created image is part of the usage of the user
+		assert.InDelta(t, 0.02, getUserCost("UVARIATION", time.Now(), time.Now()), 0.0001)
+	})
+
+	t.Run("Budget", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		budgetCfg := openaiCfg
+		budgetCfg.Usage.DailyBudget = 0.01
+		botCfg := &config.Config{}
+		botCfg.Set("openai", budgetCfg)
+		commands := GetCommands(bot.BaseCommand{SlackClient: slackClient}, botCfg, nil)
+
+		recordUsage(budgetCfg.Usage, "UBUDGET", "C1234", "dall-e-3", Usage{Images: 1})
+
+		message := msg.Message{}
+		message.Text = "edit image add a hat"
+		message.Channel = "C1234"
+		message.User = "UBUDGET"
+		message.Files = []slack.File{
+			{Name: "cat.png", Mimetype: "image/png", URLPrivate: "https://files.slack.com/cat.png"},
+		}
+
+		mockFile(slackClient, "https://files.slack.com/cat.png", "CAT")
+		mocks.AssertSlackMessage(slackClient, message, "Sorry, you used up your daily AI budget of $0.01. Please try again tomorrow!", mock.Anything)
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		commands := GetCommands(bot.BaseCommand{SlackClient: slackClient}, cfg, nil)
+
+		message := msg.Message{}
+		message.Text = "image variations"
+		mocks.AssertError(slackClient, message, "please attach an image to the message")
+		assert.True(t, commands.Run(message))
+
+		message.Text = "edit image #count-2"
+		mocks.AssertError(slackClient, message, "please describe how the image should be edited")
+		assert.True(t, commands.Run(message))
+
+		message.Text = "edit image add a hat"
+		message.Files = []slack.File{
+			{Name: "huge.png", Mimetype: "image/png", Size: 10 * 1024 * 1024},
+		}
+		mocks.AssertError(slackClient, message, "the image huge.png is bigger than 5.2 MB")
+		assert.True(t, commands.Run(message))
 	})
 }
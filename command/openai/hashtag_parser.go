@@ -9,7 +9,8 @@ import (
 // Pre-compiled regexes for hashtag parsing
 var (
 	messageHistoryWithCountRe = regexp.MustCompile(`#message-history-(\d+)`)
-	modelRe                   = regexp.MustCompile(`#model-([\w.-]+)`)
+	modelRe                   = regexp.MustCompile(`#model-([\w.-]+(?::[\w.-]+)?)`)
+	personaRe                 = regexp.MustCompile(`#persona-([\w-]+)`)
 )
 
 // removeHashtag removes a hashtag from the text if present and returns whether it was found
@@ -25,6 +26,7 @@ func removeHashtag(text *string, hashtag string) bool {
 type HashtagOptions struct {
 	ReasoningEffort string // "minimal", "medium", "high", or ""
 	Model           string // override model, empty means use config default
+	Persona         string // name of the persona, empty means the default persona of the channel
 	MessageHistory  int    // number of channel messages to include, 0 means disabled
 	NoStreaming     bool   // disable streaming responses, get full response at once
 	NoThread        bool   // disable thread replies, reply directly to the message instead
@@ -39,7 +41,8 @@ func ParseHashtags(text string) (cleanText string, options HashtagOptions) {
 	// 1. #message-history-<number>
 	// 2. #message-history
 	// 3. #model-<name>
-	// 4. #high-thinking, #medium-thinking, #minimal-thinking, #no-thinking
+	// 4. #persona-<name>
+	// 5. #high-thinking, #medium-thinking, #minimal-thinking, #no-thinking
 
 	// Parse message-history with number: #message-history-20
 	if matches := messageHistoryWithCountRe.FindStringSubmatch(text); len(matches) > 1 {
@@ -58,6 +61,12 @@ func ParseHashtags(text string) (cleanText string, options HashtagOptions) {
 		text = modelRe.ReplaceAllString(text, "")
 	}
 
+	// Parse persona: #persona-oncall
+	if matches := personaRe.FindStringSubmatch(text); len(matches) > 1 {
+		options.Persona = matches[1]
+		text = personaRe.ReplaceAllString(text, "")
+	}
+
 	// Parse reasoning effort
 	reasoningMap := map[string]string{
 		"#high-thinking":    "high",
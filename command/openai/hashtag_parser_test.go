@@ -15,6 +15,7 @@ func TestParseHashtags(t *testing.T) {
 		expectedStreaming bool
 		expectedNoThread  bool
 		expectedDebug     bool
+		expectedPersona   string
 	}{
 		{
 			name:          "No hashtags",
@@ -28,6 +29,12 @@ func TestParseHashtags(t *testing.T) {
 			expectedText:  "What is Go?",
 			expectedModel: "gpt-4o",
 		},
+		{
+			name:          "Model with tag",
+			input:         "#model-llama3.1:8b What is Go?",
+			expectedText:  "What is Go?",
+			expectedModel: "llama3.1:8b",
+		},
 		{
 			name:           "High thinking",
 			input:          "#high-thinking Explain quantum computing",
@@ -132,6 +139,13 @@ func TestParseHashtags(t *testing.T) {
 			expectedNoThread:  true,
 			expectedDebug:     true,
 		},
+		{
+			name:              "Persona",
+			input:             "#persona-on-call #no-streaming Why is the build red?",
+			expectedText:      "Why is the build red?",
+			expectedPersona:   "on-call",
+			expectedStreaming: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -159,6 +173,9 @@ func TestParseHashtags(t *testing.T) {
 			if options.Debug != tt.expectedDebug {
 				t.Errorf("Expected Debug %v, got %v", tt.expectedDebug, options.Debug)
 			}
+			if options.Persona != tt.expectedPersona {
+				t.Errorf("Expected Persona '%s', got '%s'", tt.expectedPersona, options.Persona)
+			}
 		})
 	}
 }
@@ -0,0 +1,458 @@
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/innogames/slack-bot/v2/client"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// API docs: https://platform.openai.com/docs/api-reference/embeddings
+	apiEmbeddingsURL = "/v1/embeddings"
+
+	// the index is stored as one entry, as it's always loaded completely
+	knowledgeStorageKey   = "chatgpt_knowledge"
+	knowledgeStorageIndex = "index"
+
+	// max number of texts which are embedded with one API call
+	embeddingBatchSize = 100
+)
+
+// file types of the knowledge directory which are indexed
+var knowledgeFileExtensions = []string{".md", ".markdown", ".txt"}
+
+// KnowledgeBaseConfig defines the documents which are used to answer questions, like runbooks
+type KnowledgeBaseConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// directory with Markdown/text files, all subdirectories are included
+	Directory string `mapstructure:"directory"`
+
+	// pinned messages of these channels (id or name) are indexed as well
+	PinnedChannels []string `mapstructure:"pinned_channels"`
+
+	EmbeddingModel string  `mapstructure:"embedding_model"`
+	ChunkSize      int     `mapstructure:"chunk_size"` // max characters per indexed chunk
+	TopK           int     `mapstructure:"top_k"`      // number of chunks which are added to a question
+	MinScore       float64 `mapstructure:"min_score"`  // min cosine similarity of a relevant chunk
+}
+
+// IsEnabled checks if the knowledge base is active and has at least one source
+func (c KnowledgeBaseConfig) IsEnabled() bool {
+	return c.Enabled && (c.Directory != "" || len(c.PinnedChannels) > 0)
+}
+
+// knowledgeChunk is a part of a document with its embedding vector
+type knowledgeChunk struct {
+	Source    string    `json:"source"`
+	Text      string    `json:"text"`
+	Embedding []float64 `json:"embedding"`
+}
+
+// knowledgeDocument is a text which gets indexed, like a file or a pinned message
+type knowledgeDocument struct {
+	source string
+	text   string
+}
+
+// EmbeddingRequest API reference: https://platform.openai.com/docs/api-reference/embeddings/create
+type EmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// EmbeddingResponse contains one embedding per input text
+type EmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// knowledgeBase holds the embedding index of the configured documents
+type knowledgeBase struct {
+	cfg         Config
+	slackClient client.SlackClient
+
+	// only one index build at a time, searches in the already loaded index are not blocked by it
+	indexMu sync.Mutex
+
+	mu     sync.RWMutex
+	chunks []knowledgeChunk
+	loaded bool
+}
+
+func newKnowledgeBase(cfg Config, slackClient client.SlackClient) *knowledgeBase {
+	if !cfg.KnowledgeBase.IsEnabled() {
+		return nil
+	}
+
+	return &knowledgeBase{
+		cfg:         cfg,
+		slackClient: slackClient,
+	}
+}
+
+// search returns the most relevant chunks for the question
+func (k *knowledgeBase) search(question string) ([]knowledgeChunk, error) {
+	chunks, err := k.getChunks()
+	if err != nil {
+		return nil, err
+	}
+
+	embeddings, err := k.embed([]string{question})
+	if err != nil {
+		return nil, err
+	}
+
+	type scoredChunk struct {
+		chunk knowledgeChunk
+		score float64
+	}
+	scored := make([]scoredChunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		score := cosineSimilarity(embeddings[0], chunk.Embedding)
+		if score >= k.cfg.KnowledgeBase.MinScore {
+			scored = append(scored, scoredChunk{chunk, score})
+		}
+	}
+	slices.SortStableFunc(scored, func(a, b scoredChunk) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	result := make([]knowledgeChunk, 0, k.cfg.KnowledgeBase.TopK)
+	for i := 0; i < len(scored) && i < k.cfg.KnowledgeBase.TopK; i++ {
+		result = append(result, scored[i].chunk)
+	}
+
+	return result, nil
+}
+
+// getChunks returns the indexed chunks. The stored index is loaded (or built, if there is none yet) on first usage.
+func (k *knowledgeBase) getChunks() ([]knowledgeChunk, error) {
+	if chunks, loaded := k.getLoadedChunks(); loaded {
+		return chunks, nil
+	}
+
+	k.indexMu.Lock()
+	defer k.indexMu.Unlock()
+
+	// the index might have been loaded while waiting for the lock
+	if chunks, loaded := k.getLoadedChunks(); loaded {
+		return chunks, nil
+	}
+
+	var chunks []knowledgeChunk
+	if err := storage.Read(knowledgeStorageKey, knowledgeStorageIndex, &chunks); err != nil {
+		log.Info("openai knowledge base is not indexed yet, building the index")
+		if chunks, err = k.buildIndex(); err != nil {
+			return nil, err
+		}
+	}
+	k.setChunks(chunks)
+
+	return chunks, nil
+}
+
+// reindex rebuilds the index and returns the number of indexed chunks
+func (k *knowledgeBase) reindex() (int, error) {
+	k.indexMu.Lock()
+	defer k.indexMu.Unlock()
+
+	chunks, err := k.buildIndex()
+	if err != nil {
+		return 0, err
+	}
+	k.setChunks(chunks)
+
+	return len(chunks), nil
+}
+
+func (k *knowledgeBase) getLoadedChunks() ([]knowledgeChunk, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.chunks, k.loaded
+}
+
+func (k *knowledgeBase) setChunks(chunks []knowledgeChunk) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.chunks = chunks
+	k.loaded = true
+}
+
+// buildIndex loads all documents, creates the embeddings of their chunks and stores the new index
+func (k *knowledgeBase) buildIndex() ([]knowledgeChunk, error) {
+	if k.cfg.KnowledgeBase.ChunkSize <= 0 {
+		return nil, fmt.Errorf("invalid knowledge base chunk_size: %d", k.cfg.KnowledgeBase.ChunkSize)
+	}
+
+	documents, err := k.loadDocuments()
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]knowledgeChunk, 0)
+	for _, document := range documents {
+		for _, text := range splitChunks(document.text, k.cfg.KnowledgeBase.ChunkSize) {
+			chunks = append(chunks, knowledgeChunk{Source: document.source, Text: text})
+		}
+	}
+
+	for start := 0; start < len(chunks); start += embeddingBatchSize {
+		batch := chunks[start:min(start+embeddingBatchSize, len(chunks))]
+		texts := make([]string, 0, len(batch))
+		for _, chunk := range batch {
+			texts = append(texts, chunk.Text)
+		}
+
+		embeddings, err := k.embed(texts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range batch {
+			batch[i].Embedding = embeddings[i]
+		}
+	}
+
+	if err = storage.Write(knowledgeStorageKey, knowledgeStorageIndex, chunks); err != nil {
+		return nil, err
+	}
+
+	log.Infof("openai knowledge base: indexed %d chunks of %d documents", len(chunks), len(documents))
+
+	return chunks, nil
+}
+
+// loadDocuments reads all files of the knowledge directory and the pinned messages of the configured channels
+func (k *knowledgeBase) loadDocuments() ([]knowledgeDocument, error) {
+	documents := make([]knowledgeDocument, 0)
+
+	if directory := k.cfg.KnowledgeBase.Directory; directory != "" {
+		err := filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() || !slices.Contains(knowledgeFileExtensions, strings.ToLower(filepath.Ext(path))) {
+				return nil
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			source, _ := filepath.Rel(directory, path)
+			documents = append(documents, knowledgeDocument{source: source, text: string(content)})
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("can't read knowledge directory: %w", err)
+		}
+	}
+
+	for _, channel := range k.cfg.KnowledgeBase.PinnedChannels {
+		channelID, channelName := client.GetChannelIDAndName(channel)
+		if channelID == "" {
+			return nil, fmt.Errorf("unknown channel %s", channel)
+		}
+
+		items, _, err := k.slackClient.ListPins(channelID)
+		if err != nil {
+			return nil, fmt.Errorf("can't load pinned messages of %s: %w", channel, err)
+		}
+
+		for _, item := range items {
+			if item.Message == nil || item.Message.Text == "" {
+				continue
+			}
+
+			source := "pinned message in #" + channelName
+			if item.Message.Permalink != "" {
+				source = fmt.Sprintf("<%s|%s>", item.Message.Permalink, source)
+			}
+			documents = append(documents, knowledgeDocument{source: source, text: item.Message.Text})
+		}
+	}
+
+	return documents, nil
+}
+
+// embed creates the embedding vectors of the given texts
+func (k *knowledgeBase) embed(texts []string) ([][]float64, error) {
+	cfg := k.cfg.ForModel(k.cfg.KnowledgeBase.EmbeddingModel)
+	if !cfg.isOpenAICompatible() {
+		return nil, fmt.Errorf("embeddings are not supported by provider %s", cfg.Provider)
+	}
+
+	jsonData, _ := json.Marshal(EmbeddingRequest{
+		Model: cfg.Model,
+		Input: texts,
+	})
+
+	resp, err := doRequest(cfg, apiEmbeddingsURL, jsonData)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response EmbeddingResponse
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	if response.Error.Message != "" {
+		return nil, errors.New(response.Error.Message)
+	}
+	if len(response.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(response.Data))
+	}
+
+	embeddings := make([][]float64, len(texts))
+	for _, data := range response.Data {
+		if data.Index < 0 || data.Index >= len(texts) {
+			return nil, fmt.Errorf("invalid embedding index %d", data.Index)
+		}
+		embeddings[data.Index] = data.Embedding
+	}
+
+	return embeddings, nil
+}
+
+// splitChunks splits the text into paragraph based chunks with a max size. A Markdown headline starts a new chunk.
+func splitChunks(text string, size int) []string {
+	chunks := make([]string, 0)
+	var current strings.Builder
+
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current.Reset()
+	}
+
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+
+		if strings.HasPrefix(paragraph, "#") || current.Len()+len(paragraph) > size {
+			flush()
+		}
+
+		// too long paragraphs are split by words
+		for len(paragraph) > size {
+			cut := strings.LastIndexAny(paragraph[:size], " \n")
+			if cut <= 0 {
+				// no word boundary: don't cut within a multibyte character, but take at least one
+				cut = size
+				for cut > 0 && !utf8.RuneStart(paragraph[cut]) {
+					cut--
+				}
+				if cut == 0 {
+					_, cut = utf8.DecodeRuneInString(paragraph)
+				}
+			}
+			current.WriteString(paragraph[:cut])
+			flush()
+			paragraph = strings.TrimSpace(paragraph[cut:])
+		}
+
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(paragraph)
+	}
+	flush()
+
+	return chunks
+}
+
+func cosineSimilarity(a []float64, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// knowledgeContext builds the system message with the relevant chunks, numbered for the citations
+func knowledgeContext(chunks []knowledgeChunk) ChatMessage {
+	var text strings.Builder
+	text.WriteString("Use the following excerpts of our knowledge base to answer the question, if they are relevant. " +
+		"Cite the used excerpts with their number, like [1]. Don't make up information which is not part of them.")
+
+	for i, chunk := range chunks {
+		fmt.Fprintf(&text, "\n\n[%d] %s:\n%s", i+1, chunk.Source, chunk.Text)
+	}
+
+	return ChatMessage{
+		Role:    roleSystem,
+		Content: text.String(),
+	}
+}
+
+// knowledgeSources lists the sources of the chunks, which is added to the answer
+func knowledgeSources(chunks []knowledgeChunk) string {
+	sources := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		sources = append(sources, fmt.Sprintf("[%d] %s", i+1, chunk.Source))
+	}
+
+	return "\n\n_Sources: " + strings.Join(sources, ", ") + "_"
+}
+
+// bot function to rebuild the knowledge base index, e.g. after the runbooks got updated
+func (c *openaiCommand) reindexKnowledge(_ matcher.Result, message msg.Message) {
+	c.AddReaction(":hourglass:", message)
+	defer c.RemoveReaction(":hourglass:", message)
+
+	start := time.Now()
+	chunks, err := c.knowledge.reindex()
+	if err != nil {
+		c.ReplyError(message, fmt.Errorf("can't reindex knowledge base: %w", err))
+		return
+	}
+
+	c.SendMessage(message, fmt.Sprintf("Indexed %d chunks of the knowledge base in %s", chunks, util.FormatDuration(time.Since(start))))
+}
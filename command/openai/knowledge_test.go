@@ -0,0 +1,261 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/innogames/slack-bot/v2/command/queue"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// startEmbeddingServer returns fake embeddings: one dimension per keyword
+func startEmbeddingServer(t *testing.T, keywords ...string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, apiEmbeddingsURL, req.URL.Path)
+
+		var request EmbeddingRequest
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		assert.Equal(t, "text-embedding-3-small", request.Model)
+
+		data := make([]string, 0, len(request.Input))
+		for i, input := range request.Input {
+			embedding := make([]string, 0, len(keywords))
+			for _, keyword := range keywords {
+				embedding = append(embedding, fmt.Sprint(strings.Count(strings.ToLower(input), keyword)))
+			}
+			data = append(data, fmt.Sprintf(`{"index":%d,"embedding":[%s]}`, i, strings.Join(embedding, ",")))
+		}
+
+		res.Write([]byte(`{"data":[` + strings.Join(data, ",") + `]}`))
+	}))
+}
+
+func TestKnowledgeBase(t *testing.T) {
+	storage.InitStorage("")
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "runbooks"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "runbooks", "deploy.md"), []byte("# Deploy\n\nTrigger the deploy job to deploy a branch."), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "database.txt"), []byte("Restart the database with 'db restart'."), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "image.png"), []byte("deploy database"), 0o600))
+
+	client.AllChannels = map[string]string{"C1234": "ops"}
+	client.AllUsers = config.UserMap{"UADMIN": "admin"}
+
+	ts := startEmbeddingServer(t, "deploy", "database")
+	defer ts.Close()
+
+	cfg := defaultConfig
+	cfg.APIKey = "0815pass"
+	cfg.APIHost = ts.URL
+	cfg.KnowledgeBase.Enabled = true
+	cfg.KnowledgeBase.Directory = dir
+	cfg.KnowledgeBase.PinnedChannels = []string{"#ops"}
+
+	t.Run("Split chunks", func(t *testing.T) {
+		text := "# Intro\n\nfirst paragraph\n\nsecond paragraph\n\n## Details\n\n" + strings.Repeat("word ", 10)
+
+		assert.Equal(t, []string{
+			"# Intro\n\nfirst paragraph",
+			"second paragraph",
+			"## Details",
+			"word word word word word word",
+			"word word word word",
+		}, splitChunks(text, 30))
+
+		// long words are cut on character boundaries
+		assert.Equal(t, []string{"äö", "üä", "öü"}, splitChunks("äöüäöü", 5))
+		assert.Equal(t, []string{"ä", "ö"}, splitChunks("äö", 0))
+	})
+
+	t.Run("Cosine similarity", func(t *testing.T) {
+		assert.InDelta(t, 1.0, cosineSimilarity([]float64{1, 2}, []float64{2, 4}), 0.0001)
+		assert.InDelta(t, 0.0, cosineSimilarity([]float64{1, 0}, []float64{0, 1}), 0.0001)
+		assert.InDelta(t, 0.0, cosineSimilarity([]float64{0, 0}, []float64{0, 1}), 0.0001)
+		assert.InDelta(t, 0.0, cosineSimilarity([]float64{1}, []float64{0, 1}), 0.0001)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		slackClient.On("ListPins", "C1234").Return([]slack.Item{
+			{Type: slack.TYPE_MESSAGE, Message: &slack.Message{Msg: slack.Msg{Text: "database password is in the vault", Permalink: "https://slack.com/p123"}}},
+			{Type: slack.TYPE_FILE},
+		}, nil, nil).Once()
+
+		knowledge := newKnowledgeBase(cfg, slackClient)
+
+		// the index is built on first usage
+		chunks, err := knowledge.search("How can I deploy?")
+		require.NoError(t, err)
+		require.Len(t, chunks, 1)
+		assert.Equal(t, "runbooks/deploy.md", chunks[0].Source)
+
+		chunks, err = knowledge.search("Where is the database?")
+		require.NoError(t, err)
+		require.Len(t, chunks, 2)
+		assert.Equal(t, "database.txt", chunks[0].Source)
+		assert.Equal(t, "<https://slack.com/p123|pinned message in #ops>", chunks[1].Source)
+		assert.Equal(t, "\n\n_Sources: [1] database.txt, [2] <https://slack.com/p123|pinned message in #ops>_", knowledgeSources(chunks))
+
+		chunks, err = knowledge.search("hello")
+		require.NoError(t, err)
+		assert.Empty(t, chunks)
+
+		// the stored index is used after a restart
+		var stored []knowledgeChunk
+		require.NoError(t, storage.Read(knowledgeStorageKey, knowledgeStorageIndex, &stored))
+		assert.Len(t, stored, 3)
+
+		knowledge = newKnowledgeBase(cfg, slackClient)
+		chunks, err = knowledge.search("How can I deploy?")
+		require.NoError(t, err)
+		assert.Len(t, chunks, 1)
+	})
+
+	t.Run("Reindex knowledge", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+
+		botCfg := &config.Config{}
+		botCfg.AdminUsers = config.UserList{"UADMIN"}
+		botCfg.Set("openai", cfg)
+		commands := GetCommands(base, botCfg, nil)
+
+		message := msg.Message{}
+		message.Text = "reindex knowledge"
+		message.User = "UADMIN"
+
+		slackClient.On("ListPins", "C1234").Return(nil, nil, nil).Once()
+		mocks.AssertReaction(slackClient, ":hourglass:", message)
+		mocks.AssertRemoveReaction(slackClient, ":hourglass:", message)
+		slackClient.On("SendMessage", message, mock.MatchedBy(func(text string) bool {
+			return strings.HasPrefix(text, "Indexed 2 chunks of the knowledge base in ")
+		})).Return("").Once()
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+
+		// no admin
+		message.User = "U1234"
+		mocks.AssertReaction(slackClient, "❌", message)
+		mocks.AssertError(slackClient, message, "sorry, you are no admin and not allowed to execute this command")
+
+		actual = commands.Run(message)
+		assert.True(t, actual)
+	})
+
+	t.Run("Reindex with invalid chunk size", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+
+		invalidCfg := cfg
+		invalidCfg.KnowledgeBase.ChunkSize = 0
+
+		botCfg := &config.Config{}
+		botCfg.AdminUsers = config.UserList{"UADMIN"}
+		botCfg.Set("openai", invalidCfg)
+		commands := GetCommands(base, botCfg, nil)
+
+		message := msg.Message{}
+		message.Text = "reindex knowledge"
+		message.User = "UADMIN"
+
+		mocks.AssertReaction(slackClient, ":hourglass:", message)
+		mocks.AssertRemoveReaction(slackClient, ":hourglass:", message)
+		mocks.AssertError(slackClient, message, "can't reindex knowledge base: invalid knowledge base chunk_size: 0")
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+	})
+
+	t.Run("Context of a question", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+
+		requests := make(chan ChatRequest, 1)
+		mux := http.NewServeMux()
+		mux.Handle(apiEmbeddingsURL, ts.Config.Handler)
+		mux.HandleFunc(apiCompletionURL, func(res http.ResponseWriter, req *http.Request) {
+			var request ChatRequest
+			assert.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+			requests <- request
+
+			res.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"Use the deploy job [1]\"},\"index\":0}]}\n\ndata: [DONE]"))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		questionCfg := cfg
+		questionCfg.APIHost = server.URL
+		questionCfg.Model = "gpt-4o"
+
+		botCfg := &config.Config{}
+		botCfg.Set("openai", questionCfg)
+		commands := GetCommands(base, botCfg, nil)
+
+		message := msg.Message{}
+		message.Text = "openai How can I deploy?"
+		message.Channel = "C1234"
+		message.Timestamp = "1234"
+		ref := message.MessageRef
+
+		mocks.AssertReaction(slackClient, ":bulb:", ref)
+		mocks.AssertReaction(slackClient, ":speech_balloon:", ref)
+		mocks.AssertRemoveReaction(slackClient, ":bulb:", ref)
+		mocks.AssertRemoveReaction(slackClient, ":speech_balloon:", ref)
+		mocks.AssertSlackMessage(slackClient, ref, ":bulb: thinking...", mock.Anything)
+		mocks.AssertSlackMessage(slackClient, ref, "Use the deploy job [1]", mock.Anything, mock.Anything)
+		mocks.AssertSlackMessage(slackClient, ref, "Use the deploy job [1]\n\n_Sources: [1] runbooks/deploy.md_", mock.Anything, mock.Anything)
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+
+		// the excerpts are sent right before the question
+		request := <-requests
+		require.Len(t, request.Messages, 3)
+		assert.Contains(t, request.Messages[1].Content, "[1] runbooks/deploy.md:\n# Deploy\n\nTrigger the deploy job")
+		assert.Equal(t, "How can I deploy?", request.Messages[2].Content)
+
+		// ...but they are not part of the stored history
+		var history []ChatMessage
+		assert.Eventually(t, func() bool {
+			return storage.Read(storageKey, getIdentifier("C1234", "1234"), &history) == nil
+		}, time.Second, 5*time.Millisecond)
+		queue.WaitTillHavingNoQueuedMessage()
+
+		assert.Equal(t, []ChatMessage{
+			{Role: roleSystem, Content: cfg.InitialSystemMessage},
+			{Role: roleUser, Content: "How can I deploy?"},
+			{Role: roleAssistant, Content: "Use the deploy job [1]\n\n_Sources: [1] runbooks/deploy.md_"},
+		}, history)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		disabled := cfg
+		disabled.KnowledgeBase.Enabled = false
+		assert.Nil(t, newKnowledgeBase(disabled, nil))
+
+		disabled.KnowledgeBase.Enabled = true
+		disabled.KnowledgeBase.Directory = ""
+		disabled.KnowledgeBase.PinnedChannels = nil
+		assert.Nil(t, newKnowledgeBase(disabled, nil))
+	})
+}
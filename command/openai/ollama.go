@@ -0,0 +1,130 @@
+package openai
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	ollamaHost = "http://localhost:11434"
+
+	// API docs: https://github.com/ollama/ollama/blob/main/docs/api.md#generate-a-chat-completion
+	ollamaChatURL = "/api/chat"
+)
+
+// ollamaProvider uses the native API of a (local) Ollama server
+type ollamaProvider struct{}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  ollamaOptions   `json:"options,omitzero"`
+}
+
+type ollamaMessage struct {
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  []string `json:"images,omitempty"` // base64 encoded images
+}
+
+type ollamaOptions struct {
+	Temperature float32 `json:"temperature,omitempty"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+}
+
+// ollamaResponse is used for the full response and for each line of the streamed response
+type ollamaResponse struct {
+	Message ollamaMessage `json:"message"`
+	Done    bool          `json:"done"`
+	Error   string        `json:"error"`
+
+	// token usage, only set in the last response
+	PromptEvalCount int `json:"prompt_eval_count"`
+	EvalCount       int `json:"eval_count"`
+}
+
+func (p ollamaProvider) getURL(cfg Config, endpoint string) string {
+	return getHost(cfg, ollamaHost) + endpoint
+}
+
+func (p ollamaProvider) setHeaders(cfg Config, req *http.Request) {
+	// only needed when the server is behind an authenticating proxy
+	if cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.APIKey)
+	}
+}
+
+func (p ollamaProvider) chat(cfg Config, inputMessages []ChatMessage, stream bool, messageUpdates chan<- string) (usage Usage, err error) {
+	messages := make([]ollamaMessage, 0, len(inputMessages))
+	for _, message := range inputMessages {
+		images := make([]string, 0)
+		for _, image := range message.GetImages() {
+			if _, data, ok := parseDataURL(image); ok {
+				images = append(images, data)
+			}
+		}
+		messages = append(messages, ollamaMessage{
+			Role:    message.Role,
+			Content: message.GetText(),
+			Images:  images,
+		})
+	}
+
+	jsonData, _ := json.Marshal(ollamaRequest{
+		Model:    cfg.Model,
+		Messages: messages,
+		Stream:   stream,
+		Options: ollamaOptions{
+			Temperature: cfg.Temperature,
+			NumPredict:  cfg.MaxTokens,
+		},
+	})
+	if cfg.LogTexts {
+		log.Println(string(jsonData))
+	}
+
+	resp, err := doRequest(cfg, ollamaChatURL, jsonData)
+	if err != nil {
+		return usage, err
+	}
+	defer resp.Body.Close()
+
+	// the response (also errors) is a single JSON object or one JSON object per line when streaming
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var response ollamaResponse
+		if err = json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			log.Warnf("Ollama Error %d: %s", resp.StatusCode, err)
+			return usage, fmt.Errorf("error %d: %w", resp.StatusCode, err)
+		}
+
+		if response.Error != "" {
+			log.Warnf("Ollama Error %d: %s", resp.StatusCode, response.Error)
+			return usage, errors.New(response.Error)
+		}
+
+		messageUpdates <- response.Message.Content
+		if response.Done {
+			usage = Usage{InputTokens: response.PromptEvalCount, OutputTokens: response.EvalCount}
+			return usage, nil
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		log.Warnf("ollama stream scanner error: %s", err)
+		return usage, fmt.Errorf("stream error: %w", err)
+	}
+
+	return usage, nil
+}
@@ -2,6 +2,7 @@ package openai
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -69,7 +70,7 @@ func TestOpenai(t *testing.T) {
 
 	t.Run("Openai is not active", func(t *testing.T) {
 		cfg := &config.Config{}
-		commands := GetCommands(base, cfg)
+		commands := GetCommands(base, cfg, nil)
 		assert.Equal(t, 0, commands.Count())
 	})
 
@@ -79,7 +80,7 @@ func TestOpenai(t *testing.T) {
 			apiCompletionURL,
 			[]testRequest{
 				{
-					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"user","content":"whats 1+1?"}],"stream":true}`,
+					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"user","content":"whats 1+1?"}],"stream":true,"stream_options":{"include_usage":true}}`,
 					`data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"role":"assistant"},"index":0,"finish_reason":null}]}
 
 data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"content":"The answer "},"index":0,"finish_reason":null}]}
@@ -92,7 +93,7 @@ data: [DONE]`,
 					http.StatusOK,
 				},
 				{
-					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"user","content":"whats 1+1?"},{"role":"assistant","content":"The answer is 2"},{"role":"user","content":"whats 2+1?"}],"stream":true}`,
+					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"user","content":"whats 1+1?"},{"role":"assistant","content":"The answer is 2"},{"role":"user","content":"whats 2+1?"}],"stream":true,"stream_options":{"include_usage":true}}`,
 					`data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"role":"assistant"},"index":0,"finish_reason":null}]}
 
 data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"content":"The answer "},"index":0,"finish_reason":null}]}
@@ -112,11 +113,11 @@ data: [DONE]`,
 		cfg := &config.Config{}
 		cfg.Set("openai", openaiCfg)
 
-		commands := GetCommands(base, cfg)
+		commands := GetCommands(base, cfg, nil)
 		assert.Equal(t, 1, commands.Count())
 
 		help := commands.GetHelp()
-		assert.Len(t, help, 2)
+		assert.Len(t, help, 7)
 
 		message := msg.Message{}
 		message.Text = "openai whats 1+1?"
@@ -172,7 +173,7 @@ data: [DONE]`,
 			apiCompletionURL,
 			[]testRequest{
 				{
-					`{"model":"gpt-4o","messages":[{"role":"user","content":"whats 1+1?"}],"stream":true}`,
+					`{"model":"gpt-4o","messages":[{"role":"user","content":"whats 1+1?"}],"stream":true,"stream_options":{"include_usage":true}}`,
 					`{
 					  "error": {
 						"code": "invalid_api_key",
@@ -188,7 +189,7 @@ data: [DONE]`,
 		openaiCfg.InitialSystemMessage = ""
 		cfg := &config.Config{}
 		cfg.Set("openai", openaiCfg)
-		commands := GetCommands(base, cfg)
+		commands := GetCommands(base, cfg, nil)
 
 		message := msg.Message{}
 		message.Text = "openai whats 1+1?"
@@ -215,7 +216,7 @@ data: [DONE]`,
 
 			[]testRequest{
 				{
-					`{"model":"gpt-4o","messages":[{"role":"user","content":"whats 1+1?"}],"stream":true}`,
+					`{"model":"gpt-4o","messages":[{"role":"user","content":"whats 1+1?"}],"stream":true,"stream_options":{"include_usage":true}}`,
 					`{
 					  "error": {
 						"code": "invalid_api_key",
@@ -232,7 +233,7 @@ data: [DONE]`,
 		openaiCfg.InitialSystemMessage = ""
 		cfg := &config.Config{}
 		cfg.Set("openai", openaiCfg)
-		commands := GetCommands(base, cfg)
+		commands := GetCommands(base, cfg, nil)
 
 		message := msg.Message{}
 		message.Text = "whats 1+1?"
@@ -280,7 +281,7 @@ data: [DONE]`,
 		)
 		defer ts.Close()
 
-		command := openaiCommand{base, openaiCfg}
+		command := openaiCommand{BaseCommand: base, cfg: openaiCfg}
 
 		util.RegisterFunctions(command.GetTemplateFunction())
 		tpl, err := util.CompileTemplate(`{{ openai "whats 1+1?"}}`)
@@ -298,7 +299,7 @@ data: [DONE]`,
 			apiCompletionURL,
 			[]testRequest{
 				{
-					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"system","content":"This is a Slack bot receiving a slack thread s context, using slack user ids as identifiers. Please use user mentions in the format \u003c@U123456\u003e"},{"role":"user","content":"User \u003c@U1234\u003e wrote: thread message 1"},{"role":"user","content":"whats 1+1?"}],"stream":true}`,
+					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"system","content":"This is a Slack bot receiving a slack thread s context, using slack user ids as identifiers. Please use user mentions in the format \u003c@U123456\u003e"},{"role":"user","content":"User \u003c@U1234\u003e wrote: thread message 1"},{"role":"user","content":"whats 1+1?"}],"stream":true,"stream_options":{"include_usage":true}}`,
 					`data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"role":"assistant"},"index":0,"finish_reason":null}]}
 
 data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"content":"Jolo!"},"index":0,"finish_reason":null}]}
@@ -316,7 +317,7 @@ data: [DONE]`,
 		cfg := &config.Config{}
 		cfg.Set("openai", openaiCfg)
 
-		commands := GetCommands(base, cfg)
+		commands := GetCommands(base, cfg, nil)
 
 		message := msg.Message{}
 		message.Text = "openai whats 1+1?"
@@ -357,8 +358,14 @@ data: [DONE]`,
 			t,
 			apiCompletionURL,
 			[]testRequest{
+				// the thread doesn't fit into the context, so it gets summarized first
 				{
-					`{"model":"dummy-test","messages":[{"role":"user","content":"User \u003c@U1234\u003e wrote: i had a great weekend"},{"role":"user","content":"summarize this thread "}],"stream":true}`,
+					`{"model":"dummy-test","messages":[{"role":"system","content":` + jsonString(fmt.Sprintf(historySummarySystemMessage, 7)) + `},{"role":"user","content":"user: User \u003c@U1234\u003e wrote: i had a great weekend\nuser: User \u003c@U1234\u003e wrote: Lorem ipsum dolor sit amet, consetetur sadipscing elitr, sed diam nonumy eirm\n...(truncated)"}]}`,
+					completionResponse("great weekend, lorem ipsum"),
+					http.StatusOK,
+				},
+				{
+					`{"model":"dummy-test","messages":[{"role":"system","content":"Summary of the earlier conversation:\ngreat weekend, lorem ipsum"},{"role":"user","content":"summarize this thread "}],"stream":true,"stream_options":{"include_usage":true}}`,
 					`data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"role":"assistant"},"index":0,"finish_reason":null}]}
 
 data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"content":"Jolo!"},"index":0,"finish_reason":null}]}
@@ -377,7 +384,7 @@ data: [DONE]`,
 		cfg := &config.Config{}
 		cfg.Set("openai", openaiCfg)
 
-		commands := GetCommands(base, cfg)
+		commands := GetCommands(base, cfg, nil)
 
 		message := msg.Message{}
 		message.Text = "openai summarize this thread <https://foobar.slack.com/archives/chan1234/p1694166741200139>"
@@ -405,7 +412,7 @@ data: [DONE]`,
 		mocks.AssertReaction(slackClient, ":speech_balloon:", message.MessageRef)
 		mocks.AssertRemoveReaction(slackClient, ":bulb:", message.MessageRef)
 		mocks.AssertRemoveReaction(slackClient, ":speech_balloon:", message.MessageRef)
-		mocks.AssertSlackMessage(slackClient, message.MessageRef, "Note: The token length of 100 for model dummy-test exceeded! 1 messages were not sent", mock.Anything)
+		mocks.AssertSlackMessage(slackClient, message.MessageRef, "Note: The token length of 100 for model dummy-test exceeded! 2 older messages were summarized", mock.Anything)
 		mocks.AssertSlackMessage(slackClient, message.MessageRef, ":bulb: thinking...", mock.Anything)
 		mocks.AssertSlackMessage(slackClient, message.MessageRef, "Jolo!", mock.Anything, mock.Anything)
 
@@ -447,7 +454,7 @@ data: [DONE]`,
 		cfg := &config.Config{}
 		cfg.Set("openai", openaiCfg)
 
-		commands := GetCommands(base, cfg)
+		commands := GetCommands(base, cfg, nil)
 
 		message := msg.Message{}
 		message.Text = "openai #no-streaming whats 1+1?"
@@ -473,7 +480,7 @@ data: [DONE]`,
 			apiCompletionURL,
 			[]testRequest{
 				{
-					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"user","content":"quick question"}],"stream":true}`,
+					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"user","content":"quick question"}],"stream":true,"stream_options":{"include_usage":true}}`,
 					`data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"role":"assistant"},"index":0,"finish_reason":null}]}
 
 data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"content":"Sure, what is it?"},"index":0,"finish_reason":null}]}
@@ -490,7 +497,7 @@ data: [DONE]`,
 		cfg := &config.Config{}
 		cfg.Set("openai", openaiCfg)
 
-		commands := GetCommands(base, cfg)
+		commands := GetCommands(base, cfg, nil)
 
 		message := msg.Message{}
 		message.Text = "openai #no-thread quick question"
@@ -518,7 +525,7 @@ data: [DONE]`,
 			[]testRequest{
 				{
 					// The attachment content should be included in the message
-					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"system","content":"This is a Slack bot receiving a slack thread s context, using slack user ids as identifiers. Please use user mentions in the format \u003c@U123456\u003e"},{"role":"user","content":"User \u003c@U1234\u003e wrote: check this file \u003cAttachment filename=\"data.csv\"\u003ecol1,col2\nval1,val2\n\u003c/Attachment\u003e"},{"role":"user","content":"summarize the attachment"}],"stream":true}`,
+					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"system","content":"This is a Slack bot receiving a slack thread s context, using slack user ids as identifiers. Please use user mentions in the format \u003c@U123456\u003e"},{"role":"user","content":"User \u003c@U1234\u003e wrote: check this file \u003cAttachment filename=\"data.csv\"\u003ecol1,col2\nval1,val2\n\u003c/Attachment\u003e"},{"role":"user","content":"summarize the attachment"}],"stream":true,"stream_options":{"include_usage":true}}`,
 					`data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"role":"assistant"},"index":0,"finish_reason":null}]}
 
 data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"content":"The file contains two columns."},"index":0,"finish_reason":null}]}
@@ -535,7 +542,7 @@ data: [DONE]`,
 		cfg := &config.Config{}
 		cfg.Set("openai", openaiCfg)
 
-		commands := GetCommands(base, cfg)
+		commands := GetCommands(base, cfg, nil)
 
 		message := msg.Message{}
 		message.Text = "openai summarize the attachment"
@@ -584,17 +591,17 @@ data: [DONE]`,
 		assert.True(t, actual)
 	})
 
-	t.Run("Test non-text attachment is skipped", func(t *testing.T) {
+	t.Run("Test image attachment is sent as image content", func(t *testing.T) {
 		openaiCfg, ts := startTestServer(
 			t,
 			apiCompletionURL,
 			[]testRequest{
 				{
-					// The image attachment should NOT be included (only text is shown)
-					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"system","content":"This is a Slack bot receiving a slack thread s context, using slack user ids as identifiers. Please use user mentions in the format \u003c@U123456\u003e"},{"role":"user","content":"User \u003c@U1234\u003e wrote: here is an image"},{"role":"user","content":"what do you see?"}],"stream":true}`,
+					// The image is sent as base64 content part, the unsupported file is mentioned as skipped
+					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"system","content":"This is a Slack bot receiving a slack thread s context, using slack user ids as identifiers. Please use user mentions in the format \u003c@U123456\u003e"},{"role":"user","content":[{"type":"text","text":"User \u003c@U1234\u003e wrote: here is an image \u003cAttachment filename=\"archive.zip (unsupported file type application/zip)\" skipped=\"true\"/\u003e"},{"type":"image_url","image_url":{"url":"data:image/png;base64,UE5H"}}]},{"role":"user","content":"what do you see?"}],"stream":true,"stream_options":{"include_usage":true}}`,
 					`data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"role":"assistant"},"index":0,"finish_reason":null}]}
 
-data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"content":"I cannot see images."},"index":0,"finish_reason":null}]}
+data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"content":"I see a stack trace."},"index":0,"finish_reason":null}]}
 
 data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{},"index":0,"finish_reason":"stop"}]}
 
@@ -608,7 +615,7 @@ data: [DONE]`,
 		cfg := &config.Config{}
 		cfg.Set("openai", openaiCfg)
 
-		commands := GetCommands(base, cfg)
+		commands := GetCommands(base, cfg, nil)
 
 		message := msg.Message{}
 		message.Text = "openai what do you see?"
@@ -617,7 +624,7 @@ data: [DONE]`,
 		message.Timestamp = "5679"
 		ref := message.MessageRef
 
-		// Create a thread message with a non-text file attachment (image)
+		// Create a thread message with an image and an unsupported file
 		threadMessage := slack.Message{}
 		threadMessage.User = "U1234"
 		threadMessage.Text = "here is an image"
@@ -627,6 +634,10 @@ data: [DONE]`,
 				Mimetype:   "image/png",
 				URLPrivate: "https://files.slack.com/files-pri/T123/photo.png",
 			},
+			{
+				Name:     "archive.zip",
+				Mimetype: "application/zip",
+			},
 		}
 		threadMessages := []slack.Message{threadMessage}
 
@@ -636,15 +647,20 @@ data: [DONE]`,
 			Timestamp: "5679",
 		}
 		slackClient.On("GetThreadMessages", threadRef).Once().Return(threadMessages, nil)
+		slackClient.On("GetFile", "https://files.slack.com/files-pri/T123/photo.png", mock.Anything).
+			Run(func(args mock.Arguments) {
+				writer := args.Get(1).(io.Writer)
+				writer.Write([]byte("PNG"))
+			}).
+			Return(nil).Once()
 
-		// GetFile should NOT be called for image files
-
+		mocks.AssertSlackMessage(slackClient, ref, "Note: these attachments were skipped: archive.zip (unsupported file type application/zip)", mock.Anything)
 		mocks.AssertReaction(slackClient, ":bulb:", ref)
 		mocks.AssertReaction(slackClient, ":speech_balloon:", ref)
 		mocks.AssertRemoveReaction(slackClient, ":bulb:", ref)
 		mocks.AssertRemoveReaction(slackClient, ":speech_balloon:", ref)
 		mocks.AssertSlackMessage(slackClient, ref, ":bulb: thinking...", mock.Anything)
-		mocks.AssertSlackMessage(slackClient, ref, "I cannot see images.", mock.Anything, mock.Anything)
+		mocks.AssertSlackMessage(slackClient, ref, "I see a stack trace.", mock.Anything, mock.Anything)
 
 		actual := commands.Run(message)
 		queue.WaitTillHavingNoQueuedMessage()
@@ -657,7 +673,7 @@ data: [DONE]`,
 			apiCompletionURL,
 			[]testRequest{
 				{
-					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"system","content":"This is a Slack bot receiving a slack thread s context, using slack user ids as identifiers. Please use user mentions in the format \u003c@U123456\u003e"},{"role":"user","content":"User \u003c@U1234\u003e wrote: previous message"},{"role":"user","content":"another question"}],"stream":true}`,
+					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"system","content":"This is a Slack bot receiving a slack thread s context, using slack user ids as identifiers. Please use user mentions in the format \u003c@U123456\u003e"},{"role":"user","content":"User \u003c@U1234\u003e wrote: previous message"},{"role":"user","content":"another question"}],"stream":true,"stream_options":{"include_usage":true}}`,
 					`data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"role":"assistant"},"index":0,"finish_reason":null}]}
 
 data: {"id":"chatcmpl-6tuxebSPdmd2IJpb8GrZXHiYXON6r","object":"chat.completion.chunk","created":1678785018,"model":"gpt-4o-0301","choices":[{"delta":{"content":"Yes, I can help"},"index":0,"finish_reason":null}]}
@@ -674,7 +690,7 @@ data: [DONE]`,
 		cfg := &config.Config{}
 		cfg.Set("openai", openaiCfg)
 
-		commands := GetCommands(base, cfg)
+		commands := GetCommands(base, cfg, nil)
 
 		message := msg.Message{}
 		message.Text = "openai #no-thread another question"
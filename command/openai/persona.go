@@ -0,0 +1,125 @@
+package openai
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/client"
+)
+
+// storage key to remember the selected persona of a thread for further replies
+const personaStorageKey = "chatgpt_persona"
+
+// Persona is a named set of a system prompt and model settings, e.g. a "terse incident assistant" for an oncall channel
+type Persona struct {
+	SystemMessage   string  `mapstructure:"system_message"`
+	Model           string  `mapstructure:"model"`
+	Temperature     float32 `mapstructure:"temperature"`
+	ReasoningEffort string  `mapstructure:"reasoning_effort"`
+
+	// if set, only these hashtags can be used, like "model", "thinking", "message-history", "no-streaming", "no-thread" or "debug"
+	AllowedHashtags []string `mapstructure:"allowed_hashtags"`
+}
+
+// isHashtagAllowed checks if the hashtag (without "#" and parameters) can be used with this persona
+func (p Persona) isHashtagAllowed(hashtag string) bool {
+	return len(p.AllowedHashtags) == 0 || slices.Contains(p.AllowedHashtags, hashtag)
+}
+
+// filterHashtags resets all options which are not allowed for this persona
+func (p Persona) filterHashtags(options *HashtagOptions) {
+	if !p.isHashtagAllowed("model") {
+		options.Model = ""
+	}
+	if !p.isHashtagAllowed("thinking") {
+		options.ReasoningEffort = ""
+	}
+	if !p.isHashtagAllowed("message-history") {
+		options.MessageHistory = 0
+	}
+	if !p.isHashtagAllowed("no-streaming") {
+		options.NoStreaming = false
+	}
+	if !p.isHashtagAllowed("no-thread") {
+		options.NoThread = false
+	}
+	if !p.isHashtagAllowed("debug") {
+		options.Debug = false
+	}
+}
+
+// GetPersona returns the persona by its (case-insensitive) name
+func (c Config) GetPersona(name string) (Persona, bool) {
+	for personaName, persona := range c.Personas {
+		if strings.EqualFold(personaName, name) {
+			return persona, true
+		}
+	}
+
+	return Persona{}, false
+}
+
+// GetChannelPersona returns the name of the default persona of the channel, given by id or name
+func (c Config) GetChannelPersona(channel string) string {
+	for identifier, persona := range c.ChannelPersonas {
+		if strings.EqualFold(identifier, channel) {
+			return persona
+		}
+
+		if channelID, _ := client.GetChannelIDAndName(identifier); channelID != "" && channelID == channel {
+			return persona
+		}
+	}
+
+	return ""
+}
+
+// WithPersona applies the model settings of the persona to the config
+func (c Config) WithPersona(name string) Config {
+	persona, ok := c.GetPersona(name)
+	if !ok {
+		return c
+	}
+
+	if persona.SystemMessage != "" {
+		c.InitialSystemMessage = persona.SystemMessage
+	}
+	if persona.Model != "" {
+		c.Model = persona.Model
+	}
+	if persona.Temperature != 0 {
+		c.Temperature = persona.Temperature
+	}
+	if persona.ReasoningEffort != "" {
+		c.ReasoningEffort = persona.ReasoningEffort
+	}
+
+	return c
+}
+
+// resolvePersona selects the persona via the #persona-<name> hashtag, the persona of the thread or the default persona of the channel.
+// The options are limited to the allowed hashtags of the persona.
+func (c *openaiCommand) resolvePersona(options *HashtagOptions, channel string, threadIdentifier string) error {
+	name := options.Persona
+	if name == "" && threadIdentifier != "" {
+		_ = storage.Read(personaStorageKey, threadIdentifier, &name)
+	}
+	if name == "" {
+		name = c.cfg.GetChannelPersona(channel)
+	}
+	if name == "" {
+		return nil
+	}
+
+	persona, ok := c.cfg.GetPersona(name)
+	if !ok {
+		return fmt.Errorf("unknown persona %s", name)
+	}
+
+	options.Persona = name
+	persona.filterHashtags(options)
+
+	return nil
+}
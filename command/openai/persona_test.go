@@ -0,0 +1,128 @@
+package openai
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersona(t *testing.T) {
+	storage.InitStorage("")
+
+	cfg := defaultConfig
+	cfg.Model = "gpt-4o"
+	cfg.APIKey = "0815pass"
+	cfg.Personas = map[string]Persona{
+		"oncall": {
+			SystemMessage:   "You are a terse incident assistant",
+			Model:           "gpt-4o-mini",
+			Temperature:     0.2,
+			AllowedHashtags: []string{"no-streaming"},
+		},
+		"reviewer": {
+			SystemMessage: "You review code",
+		},
+	}
+	cfg.ChannelPersonas = map[string]string{
+		"c1234": "oncall",
+	}
+
+	t.Run("Apply persona to config", func(t *testing.T) {
+		actual := cfg.WithPersona("OnCall")
+		assert.Equal(t, "You are a terse incident assistant", actual.InitialSystemMessage)
+		assert.Equal(t, "gpt-4o-mini", actual.Model)
+		assert.InDelta(t, 0.2, actual.Temperature, 0.001)
+
+		actual = cfg.WithPersona("reviewer")
+		assert.Equal(t, "You review code", actual.InitialSystemMessage)
+		assert.Equal(t, "gpt-4o", actual.Model)
+
+		actual = cfg.WithPersona("unknown")
+		assert.Equal(t, cfg.InitialSystemMessage, actual.InitialSystemMessage)
+	})
+
+	t.Run("Channel persona", func(t *testing.T) {
+		assert.Equal(t, "oncall", cfg.GetChannelPersona("C1234"))
+		assert.Empty(t, cfg.GetChannelPersona("C9999"))
+	})
+
+	t.Run("Resolve persona", func(t *testing.T) {
+		command := &openaiCommand{cfg: cfg}
+
+		// default persona of the channel limits the hashtags
+		options := HashtagOptions{Model: "o1", NoStreaming: true, Debug: true}
+		err := command.resolvePersona(&options, "C1234", "")
+		require.NoError(t, err)
+		assert.Equal(t, HashtagOptions{Persona: "oncall", NoStreaming: true}, options)
+
+		// explicit persona wins over the channel persona
+		options = HashtagOptions{Persona: "reviewer", Model: "o1"}
+		err = command.resolvePersona(&options, "C1234", "")
+		require.NoError(t, err)
+		assert.Equal(t, HashtagOptions{Persona: "reviewer", Model: "o1"}, options)
+
+		// persona of the thread is used for replies
+		storage.Write(personaStorageKey, "C9999-1234", "reviewer")
+		options = HashtagOptions{}
+		err = command.resolvePersona(&options, "C9999", "C9999-1234")
+		require.NoError(t, err)
+		assert.Equal(t, "reviewer", options.Persona)
+
+		options = HashtagOptions{Persona: "unknown"}
+		err = command.resolvePersona(&options, "C9999", "")
+		require.EqualError(t, err, "unknown persona unknown")
+	})
+
+	t.Run("Unknown persona hashtag", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+
+		botCfg := &config.Config{}
+		botCfg.Set("openai", cfg)
+		commands := GetCommands(base, botCfg, nil)
+
+		message := msg.Message{}
+		message.Text = "openai #persona-pirate hello"
+		message.Channel = "C9999"
+		message.Timestamp = "1234"
+
+		mocks.AssertError(slackClient, message.MessageRef, "unknown persona pirate")
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+	})
+
+	t.Run("Template with persona", func(t *testing.T) {
+		openaiCfg, ts := startTestServer(
+			t,
+			apiCompletionURL,
+			[]testRequest{
+				{
+					`{"model":"gpt-4o-mini","temperature":0.2,"messages":[{"role":"system","content":"You are a terse incident assistant"},{"role":"user","content":"whats 1+1?"}]}`,
+					`{"choices": [{"message": {"role": "assistant", "content": "2"}, "finish_reason": "stop", "index": 0}]}`,
+					http.StatusOK,
+				},
+			},
+		)
+		defer ts.Close()
+		openaiCfg.Personas = cfg.Personas
+
+		command := openaiCommand{cfg: openaiCfg}
+
+		util.RegisterFunctions(command.GetTemplateFunction())
+		tpl, err := util.CompileTemplate(`{{ openai "whats 1+1?" "oncall" }}`)
+		require.NoError(t, err)
+
+		res, err := util.EvalTemplate(tpl, util.Parameters{})
+		require.NoError(t, err)
+		assert.Equal(t, "2", res)
+	})
+}
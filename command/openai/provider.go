@@ -0,0 +1,100 @@
+package openai
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	providerOpenAI    = "openai"
+	providerAzure     = "azure"
+	providerAnthropic = "anthropic"
+	providerOllama    = "ollama"
+)
+
+// provider is a LLM API which is able to answer a list of chat messages
+type provider interface {
+	// getURL returns the full URL of the given (OpenAI style) API endpoint
+	getURL(cfg Config, endpoint string) string
+
+	// setHeaders adds the authentication headers to the request
+	setHeaders(cfg Config, req *http.Request)
+
+	// chat sends the messages to the model and writes the answer (or the streamed deltas) into the channel.
+	// The token usage is returned, if it's reported by the API.
+	chat(cfg Config, messages []ChatMessage, stream bool, updates chan<- string) (Usage, error)
+}
+
+var providers = map[string]provider{
+	providerOpenAI:    openaiProvider{},
+	providerAzure:     azureProvider{},
+	providerAnthropic: anthropicProvider{},
+	providerOllama:    ollamaProvider{},
+}
+
+func getProvider(cfg Config) (provider, error) {
+	name := strings.ToLower(cfg.Provider)
+	if name == "" {
+		name = providerOpenAI
+	}
+
+	p, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown openai provider: %s", cfg.Provider)
+	}
+
+	return p, nil
+}
+
+// getHost returns the configured API host or the given default host of the provider
+func getHost(cfg Config, defaultHost string) string {
+	if cfg.APIHost != "" {
+		return strings.TrimRight(cfg.APIHost, "/")
+	}
+
+	return defaultHost
+}
+
+// openaiProvider uses the official OpenAI API or any compatible API, like vLLM or LiteLLM
+type openaiProvider struct{}
+
+func (p openaiProvider) getURL(cfg Config, endpoint string) string {
+	return getHost(cfg, apiHost) + endpoint
+}
+
+func (p openaiProvider) setHeaders(cfg Config, req *http.Request) {
+	if cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.APIKey)
+	}
+}
+
+// azureProvider uses the OpenAI wire format, but a deployment based URL and an "api-key" header
+type azureProvider struct {
+	openaiProvider
+}
+
+func (p azureProvider) getURL(cfg Config, endpoint string) string {
+	deployment := cfg.Deployment
+	if deployment == "" {
+		deployment = cfg.Model
+	}
+
+	apiVersion := cfg.APIVersion
+	if apiVersion == "" {
+		apiVersion = defaultAzureAPIVersion
+	}
+
+	// e.g. /v1/chat/completions -> /openai/deployments/my-gpt/chat/completions?api-version=2024-10-21
+	return fmt.Sprintf(
+		"%s/openai/deployments/%s%s?api-version=%s",
+		getHost(cfg, ""),
+		deployment,
+		strings.TrimPrefix(endpoint, "/v1"),
+		apiVersion,
+	)
+}
+
+func (p azureProvider) setHeaders(cfg Config, req *http.Request) {
+	req.Header.Set("api-key", cfg.APIKey)
+}
@@ -0,0 +1,190 @@
+package openai
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startProviderServer starts a stub server which checks the request and returns the given response
+func startProviderServer(t *testing.T, check func(req *http.Request, body string), statusCode int, response string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		check(req, string(body))
+
+		res.WriteHeader(statusCode)
+		res.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func collectResponse(t *testing.T, cfg Config, messages []ChatMessage, stream bool) []string {
+	t.Helper()
+
+	response, err := CallChatGPT(cfg, messages, stream)
+	require.NoError(t, err)
+
+	deltas := make([]string, 0)
+	for delta := range response {
+		deltas = append(deltas, delta)
+	}
+
+	return deltas
+}
+
+func TestProviders(t *testing.T) {
+	messages := []ChatMessage{
+		{Role: roleSystem, Content: "be nice"},
+		{Role: roleUser, Content: "whats 1+1?"},
+	}
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		cfg := defaultConfig
+		cfg.Provider = "foo"
+
+		_, err := CallChatGPT(cfg, messages, false)
+		require.EqualError(t, err, "unknown openai provider: foo")
+	})
+
+	t.Run("Select provider by model", func(t *testing.T) {
+		cfg := defaultConfig
+		cfg.APIKey = "openai-key"
+		cfg.Providers = []ProviderConfig{
+			{Provider: providerAnthropic, APIKey: "anthropic-key", Models: []string{"claude-*"}},
+			{Provider: providerOllama, APIHost: "http://ollama:11434", Models: []string{"llama3.1:8b"}},
+		}
+
+		assert.Equal(t, "openai-key", cfg.ForModel("gpt-4o").APIKey)
+		assert.Equal(t, "", cfg.ForModel("gpt-4o").Provider)
+
+		claude := cfg.ForModel("claude-sonnet-4-5")
+		assert.Equal(t, providerAnthropic, claude.Provider)
+		assert.Equal(t, "anthropic-key", claude.APIKey)
+		assert.Equal(t, "claude-sonnet-4-5", claude.Model)
+		assert.False(t, claude.isOpenAICompatible())
+
+		llama := cfg.ForModel("llama3.1:8b")
+		assert.Equal(t, providerOllama, llama.Provider)
+		assert.Equal(t, "http://ollama:11434", llama.APIHost)
+		assert.Equal(t, "", cfg.ForModel("llama3.1:70b").Provider)
+	})
+
+	t.Run("Ollama needs no api key", func(t *testing.T) {
+		cfg := Config{Provider: "Ollama"}
+		assert.True(t, cfg.IsEnabled())
+
+		cfg = Config{Provider: providerAnthropic}
+		assert.False(t, cfg.IsEnabled())
+	})
+
+	t.Run("Azure", func(t *testing.T) {
+		server := startProviderServer(t, func(req *http.Request, body string) {
+			assert.Equal(t, "/openai/deployments/my-gpt/chat/completions", req.URL.Path)
+			assert.Equal(t, "2024-06-01", req.URL.Query().Get("api-version"))
+			assert.Equal(t, "azure-key", req.Header.Get("api-key"))
+			assert.Empty(t, req.Header.Get("Authorization"))
+			assert.JSONEq(t, `{"model":"gpt-4o","messages":[{"role":"system","content":"be nice"},{"role":"user","content":"whats 1+1?"}]}`, body)
+		}, http.StatusOK, `{"choices":[{"index":0,"message":{"role":"assistant","content":"2"}}]}`)
+
+		cfg := defaultConfig
+		cfg.Provider = providerAzure
+		cfg.APIHost = server.URL
+		cfg.APIKey = "azure-key"
+		cfg.Model = "gpt-4o"
+		cfg.Deployment = "my-gpt"
+		cfg.APIVersion = "2024-06-01"
+
+		assert.Equal(t, []string{"2"}, collectResponse(t, cfg, messages, false))
+	})
+
+	t.Run("Anthropic", func(t *testing.T) {
+		server := startProviderServer(t, func(req *http.Request, body string) {
+			assert.Equal(t, anthropicMessagesURL, req.URL.Path)
+			assert.Equal(t, "anthropic-key", req.Header.Get("x-api-key"))
+			assert.Equal(t, anthropicVersion, req.Header.Get("anthropic-version"))
+			assert.JSONEq(t, `{"model":"claude-sonnet-4-5","system":"be nice","messages":[{"role":"user","content":"whats 1+1?"}],"max_tokens":4096,"stream":true}`, body)
+		}, http.StatusOK, `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","role":"assistant","content":[],"usage":{"input_tokens":12,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"The answer "}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"is 2"}}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":5}}
+
+event: message_stop
+data: {"type":"message_stop"}
+`)
+
+		cfg := defaultConfig
+		cfg.Providers = []ProviderConfig{
+			{Provider: providerAnthropic, APIHost: server.URL, APIKey: "anthropic-key", Models: []string{"claude-*"}},
+		}
+		cfg.Model = "claude-sonnet-4-5"
+
+		assert.Equal(t, []string{"The answer ", "is 2"}, collectResponse(t, cfg, messages, true))
+
+		usage, err := anthropicProvider{}.chat(cfg.ForModel(cfg.Model), messages, true, make(chan string, 10))
+		require.NoError(t, err)
+		assert.Equal(t, Usage{InputTokens: 12, OutputTokens: 5}, usage)
+	})
+
+	t.Run("Anthropic error", func(t *testing.T) {
+		server := startProviderServer(t, func(*http.Request, string) {}, http.StatusUnauthorized, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`)
+
+		cfg := defaultConfig
+		cfg.Provider = providerAnthropic
+		cfg.APIHost = server.URL
+
+		assert.Equal(t, []string{"invalid x-api-key"}, collectResponse(t, cfg, messages, true))
+	})
+
+	t.Run("Ollama", func(t *testing.T) {
+		server := startProviderServer(t, func(req *http.Request, body string) {
+			assert.Equal(t, ollamaChatURL, req.URL.Path)
+			assert.Empty(t, req.Header.Get("Authorization"))
+			assert.JSONEq(t, `{"model":"llama3.1:8b","messages":[{"role":"system","content":"be nice"},{"role":"user","content":"whats 1+1?"}],"stream":true}`, body)
+		}, http.StatusOK, strings.Join([]string{
+			`{"model":"llama3.1:8b","message":{"role":"assistant","content":"The answer "},"done":false}`,
+			`{"model":"llama3.1:8b","message":{"role":"assistant","content":"is 2"},"done":false}`,
+			`{"model":"llama3.1:8b","message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":12,"eval_count":5}`,
+		}, "\n"))
+
+		cfg := defaultConfig
+		cfg.Provider = providerOllama
+		cfg.APIHost = server.URL
+		cfg.Model = "llama3.1:8b"
+
+		assert.Equal(t, []string{"The answer ", "is 2", ""}, collectResponse(t, cfg, messages, true))
+
+		usage, err := ollamaProvider{}.chat(cfg, messages, true, make(chan string, 10))
+		require.NoError(t, err)
+		assert.Equal(t, Usage{InputTokens: 12, OutputTokens: 5}, usage)
+	})
+
+	t.Run("Ollama error", func(t *testing.T) {
+		server := startProviderServer(t, func(*http.Request, string) {}, http.StatusNotFound, `{"error":"model \"foo\" not found, try pulling it first"}`)
+
+		cfg := defaultConfig
+		cfg.Provider = providerOllama
+		cfg.APIHost = server.URL
+		cfg.Model = "foo"
+
+		assert.Equal(t, []string{`model "foo" not found, try pulling it first`}, collectResponse(t, cfg, messages, false))
+	})
+}
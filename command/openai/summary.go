@@ -0,0 +1,265 @@
+package openai
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const summarySystemMessage = `You summarize Slack conversations for the team. Users are mentioned by their Slack id like <@U123456>, keep this format to mention them.
+Answer in Slack markdown with exactly these four short sections:
+*Summary*: the topic and the outcome in 1-3 sentences
+*Decisions*: bullet points of the decisions which were made, or "none"
+*Open questions*: bullet points of the questions which are still open, or "none"
+*Action items*: bullet points like "<@U123456>: task", use "unassigned" if there is no owner, or "none"`
+
+const partialSummarySystemMessage = `You summarize one part of a long Slack conversation, the summaries of all parts are combined later.
+Keep all decisions, open questions and action items with their owners (Slack ids like <@U123456>). Answer with short bullet points only.`
+
+const historySummarySystemMessage = `You summarize the earlier part of a conversation, which doesn't fit into the context of the model anymore.
+Keep all facts, decisions and open questions which are needed to continue the conversation. Answer with at most %d words.`
+
+const (
+	defaultDigestPeriod = "24h"
+
+	// longer conversations are split into chunks of this size which are summarized separately
+	maxSummaryChunkTokens = 30000
+	maxSummaryDepth       = 3
+	maxDigestMessages     = 1000
+)
+
+// the permalink of a thread reply contains the timestamp of the thread
+var threadTSRe = regexp.MustCompile(`thread_ts=(\d+\.\d+)`)
+
+// bot function for "summarize thread <link>", without a link the current thread is summarized
+func (c *openaiCommand) summarizeThread(match matcher.Result, message msg.Message) {
+	var thread msg.MessageRef
+	if link := match.GetString("link"); link != "" {
+		linkMatch := linkRe.FindStringSubmatch(link)
+		if linkMatch == nil {
+			c.ReplyError(message, fmt.Errorf("invalid thread link: %s", link))
+			return
+		}
+
+		thread.Channel = linkMatch[2]
+		thread.Thread = linkMatch[3][0:10] + "." + linkMatch[3][10:]
+		if threadMatch := threadTSRe.FindStringSubmatch(link); threadMatch != nil {
+			thread.Thread = threadMatch[1]
+		}
+	} else {
+		if message.GetThread() == "" {
+			c.ReplyError(message, errors.New("please provide a link to the thread or use the command within the thread"))
+			return
+		}
+
+		thread.Channel = message.GetChannel()
+		thread.Thread = message.GetThread()
+	}
+	thread.Timestamp = thread.Thread
+
+	messages, err := c.GetThreadMessages(thread)
+	if err != nil {
+		c.ReplyError(message, errors.Wrap(err, "can't load the thread"))
+		return
+	}
+
+	c.postSummary(message, "*Summary of the thread:*", messages)
+}
+
+// bot function for "digest #channel [period]"
+func (c *openaiCommand) digest(match matcher.Result, message msg.Message) {
+	channelID := match.GetString("channelID")
+	if channelID == "" {
+		channelID, _ = client.GetChannelIDAndName(match.GetString("channel"))
+	}
+	if channelID == "" {
+		c.ReplyError(message, fmt.Errorf("unknown channel: %s", match.GetString("channel")))
+		return
+	}
+
+	periodString := match.GetString("period")
+	if periodString == "" {
+		periodString = defaultDigestPeriod
+	}
+
+	period, err := util.ParseDuration(periodString)
+	if err != nil {
+		c.ReplyError(message, err)
+		return
+	}
+
+	messages, err := c.getChannelHistorySince(channelID, time.Now().Add(-period))
+	if err != nil {
+		c.ReplyError(message, errors.Wrap(err, "can't load the channel history"))
+		return
+	}
+
+	if len(messages) == 0 {
+		c.SendMessage(message, fmt.Sprintf("There were no messages in <#%s> in the last %s", channelID, periodString), slack.MsgOptionTS(message.GetTimestamp()))
+		return
+	}
+
+	c.postSummary(message, fmt.Sprintf("*Digest of <#%s> of the last %s:*", channelID, periodString), messages)
+}
+
+// getChannelHistorySince fetches all messages of the channel since the given time (including thread messages and text attachments)
+func (c *openaiCommand) getChannelHistorySince(channel string, oldest time.Time) ([]slack.Message, error) {
+	params := &slack.GetConversationHistoryParameters{
+		ChannelID: channel,
+		Oldest:    fmt.Sprintf("%d.000000", oldest.Unix()),
+		Limit:     200,
+	}
+
+	messages := make([]slack.Message, 0)
+	for {
+		response, err := c.GetConversationHistory(params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, message := range response.Messages {
+			if message.SubType == slack.MsgSubTypeChannelJoin || message.SubType == slack.MsgSubTypeChannelLeave {
+				continue
+			}
+			messages = append(messages, message)
+		}
+
+		if !response.HasMore || response.ResponseMetaData.NextCursor == "" || len(messages) >= maxDigestMessages {
+			break
+		}
+		params.Cursor = response.ResponseMetaData.NextCursor
+	}
+
+	return c.addThreadReplies(channel, messages), nil
+}
+
+// postSummary summarizes the messages and posts the result with the given title
+func (c *openaiCommand) postSummary(message msg.Message, title string, messages []slack.Message) {
+	if budgetMessage := checkBudget(c.cfg.Usage, message.GetUser(), time.Now()); budgetMessage != "" {
+		c.SendMessage(message, budgetMessage, slack.MsgOptionTS(message.GetTimestamp()))
+		return
+	}
+
+	lines := make([]string, 0, len(messages))
+	for _, threadMessage := range messages {
+		if threadMessage.Text == "" {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("User <@%s> wrote: %s", threadMessage.User, threadMessage.Text))
+	}
+
+	c.AddReaction(":coffee:", message)
+	defer c.RemoveReaction(":coffee:", message)
+
+	summary, err := c.summarize(message, lines, 0)
+	if err != nil {
+		c.ReplyError(message, errors.Wrap(err, "can't generate the summary"))
+		return
+	}
+
+	c.SendMessage(message, title+"\n"+summary, slack.MsgOptionTS(message.GetTimestamp()))
+}
+
+// summarize generates the structured summary of the lines. Long inputs are split into chunks
+// which are summarized separately, then the summaries of the chunks are summarized.
+func (c *openaiCommand) summarize(ref msg.Ref, lines []string, depth int) (string, error) {
+	chunkTokens := min(maxSummaryChunkTokens, c.cfg.GetContextLimit(c.cfg.Model)/2)
+	chunks := chunkLines(c.cfg, lines, chunkTokens)
+
+	if len(chunks) <= 1 || depth >= maxSummaryDepth {
+		text := c.cfg.TruncateText(strings.Join(chunks, "\n"), chunkTokens)
+		return c.complete(c.cfg, ref, summarySystemMessage, text)
+	}
+
+	partialSummaries := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		partialSummary, err := c.complete(c.cfg, ref, partialSummarySystemMessage, chunk)
+		if err != nil {
+			return "", err
+		}
+		partialSummaries = append(partialSummaries, partialSummary)
+	}
+
+	return c.summarize(ref, partialSummaries, depth+1)
+}
+
+// chunkLines joins the lines to chunks with the given max number of tokens, too long lines are truncated
+func chunkLines(cfg Config, lines []string, tokens int) []string {
+	chunks := make([]string, 0)
+	var current strings.Builder
+	currentTokens := 0
+	for _, line := range lines {
+		line = cfg.TruncateText(line, tokens)
+		lineTokens := countTokens(cfg.Model, line) + 1 // incl. the newline
+		if current.Len() > 0 && currentTokens+lineTokens > tokens {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			currentTokens = 0
+		}
+
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(line)
+		currentTokens += lineTokens
+	}
+
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+
+	return chunks
+}
+
+// complete sends a single non-streamed request and records the usage for the user
+func (c *openaiCommand) complete(cfg Config, ref msg.Ref, systemMessage string, text string) (string, error) {
+	messages := []ChatMessage{
+		{Role: roleSystem, Content: systemMessage},
+		{Role: roleUser, Content: text},
+	}
+
+	answer, usage, err := completeChat(cfg, messages)
+	if err != nil {
+		return "", err
+	}
+
+	recordUsage(c.cfg.Usage, ref.GetUser(), ref.GetChannel(), cfg.Model, usage)
+
+	return answer, nil
+}
+
+// summarizeHistory summarizes the dropped messages of a conversation into the given number of tokens.
+// The summary is added as system message right after the other system messages.
+func (c *openaiCommand) summarizeHistory(cfg Config, ref msg.Ref, messages []ChatMessage, dropped []ChatMessage, tokens int) ([]ChatMessage, error) {
+	lines := make([]string, 0, len(dropped))
+	for _, message := range dropped {
+		lines = append(lines, message.Role+": "+message.GetText())
+	}
+
+	text := cfg.TruncateText(strings.Join(lines, "\n"), cfg.GetContextLimit(cfg.Model)/2)
+	summary, err := c.complete(cfg, ref, fmt.Sprintf(historySummarySystemMessage, tokens*3/4), text)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := slices.IndexFunc(messages, func(message ChatMessage) bool {
+		return message.Role != roleSystem
+	})
+	if idx == -1 {
+		idx = len(messages)
+	}
+
+	return slices.Insert(messages, idx, ChatMessage{
+		Role:    roleSystem,
+		Content: "Summary of the earlier conversation:\n" + cfg.TruncateText(summary, tokens),
+	}), nil
+}
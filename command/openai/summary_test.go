@@ -0,0 +1,280 @@
+package openai
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// completionResponse returns a non-streamed API response with the given answer
+func completionResponse(answer string) string {
+	return `{"choices":[{"index":0,"message":{"role":"assistant","content":` + jsonString(answer) + `}}]}`
+}
+
+// jsonString encodes the text as JSON string, e.g. to compare the system messages in the requests
+func jsonString(text string) string {
+	encoded, _ := json.Marshal(text)
+
+	return string(encoded)
+}
+
+func TestSummary(t *testing.T) {
+	storage.InitStorage("")
+	client.AllChannels = map[string]string{"C1234": "dev"}
+
+	summary := "*Summary*: deploy on friday\n*Decisions*:\n- deploy on friday\n*Open questions*: none\n*Action items*:\n- <@U1234>: prepare the release"
+
+	t.Run("Chunk lines", func(t *testing.T) {
+		lines := []string{
+			strings.Repeat("a", 20),
+			strings.Repeat("b", 10),
+			strings.Repeat("c", 30),
+			strings.Repeat("d", 100),
+		}
+
+		assert.Equal(t, []string{
+			strings.Repeat("a", 20) + "\n" + strings.Repeat("b", 10),
+			strings.Repeat("c", 30),
+			strings.Repeat("d", 40) + "\n...(truncated)",
+		}, chunkLines(Config{Model: "gpt-4o"}, lines, 10))
+		assert.Empty(t, chunkLines(Config{Model: "gpt-4o"}, nil, 10))
+	})
+
+	t.Run("Summarize thread by link", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+
+		openaiCfg, ts := startTestServer(
+			t,
+			apiCompletionURL,
+			[]testRequest{
+				{
+					`{"model":"gpt-4o","messages":[{"role":"system","content":` + jsonString(summarySystemMessage) + `},{"role":"user","content":"User <@U1234> wrote: shall we deploy on friday?\nUser <@U5678> wrote: yes, please prepare the release"}]}`,
+					completionResponse(summary),
+					http.StatusOK,
+				},
+			},
+		)
+		defer ts.Close()
+
+		cfg := &config.Config{}
+		cfg.Set("openai", openaiCfg)
+		commands := GetCommands(base, cfg, nil)
+
+		message := msg.Message{}
+		message.Text = "summarize thread https://myworkspace.slack.com/archives/C1234/p1234567890123456"
+		message.Channel = "C5678"
+		message.Timestamp = "1111.2222"
+
+		threadRef := msg.MessageRef{Channel: "C1234", Thread: "1234567890.123456", Timestamp: "1234567890.123456"}
+		slackClient.On("GetThreadMessages", threadRef).Once().Return([]slack.Message{
+			{Msg: slack.Msg{User: "U1234", Text: "shall we deploy on friday?"}},
+			{Msg: slack.Msg{User: "U5678", Text: "yes, please prepare the release"}},
+			{Msg: slack.Msg{User: "U5678"}},
+		}, nil)
+		mocks.AssertReaction(slackClient, ":coffee:", message)
+		mocks.AssertRemoveReaction(slackClient, ":coffee:", message)
+		mocks.AssertSlackMessage(slackClient, message, "*Summary of the thread:*\n"+summary, mock.Anything)
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+	})
+
+	t.Run("Summarize thread errors", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+
+		openaiCfg := defaultConfig
+		openaiCfg.APIKey = "0815pass"
+		cfg := &config.Config{}
+		cfg.Set("openai", openaiCfg)
+		commands := GetCommands(base, cfg, nil)
+
+		message := msg.Message{}
+		message.Text = "summarize thread"
+		message.Channel = "C5678"
+		mocks.AssertError(slackClient, message, "please provide a link to the thread or use the command within the thread")
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+
+		message.Text = "summarize thread https://example.com"
+		mocks.AssertError(slackClient, message, "invalid thread link: https://example.com")
+
+		actual = commands.Run(message)
+		assert.True(t, actual)
+	})
+
+	t.Run("Summarize thread with API error", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+
+		openaiCfg, ts := startTestServer(
+			t,
+			apiCompletionURL,
+			[]testRequest{
+				{"", `{"error":{"message":"Rate limit reached"}}`, http.StatusTooManyRequests},
+			},
+		)
+		defer ts.Close()
+
+		cfg := &config.Config{}
+		cfg.Set("openai", openaiCfg)
+		commands := GetCommands(base, cfg, nil)
+
+		message := msg.Message{}
+		message.Text = "summarize thread"
+		message.Channel = "C1234"
+		message.Thread = "1234567890.123456"
+		message.Timestamp = "1234567899.000000"
+
+		slackClient.On("GetThreadMessages", msg.MessageRef{Channel: "C1234", Thread: "1234567890.123456", Timestamp: "1234567890.123456"}).Once().Return([]slack.Message{
+			{Msg: slack.Msg{User: "U1234", Text: "shall we deploy on friday?"}},
+		}, nil)
+		mocks.AssertReaction(slackClient, ":coffee:", message)
+		mocks.AssertRemoveReaction(slackClient, ":coffee:", message)
+		mocks.AssertError(slackClient, message, "can't generate the summary: Rate limit reached")
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+	})
+
+	t.Run("Summarize long thread in chunks", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+
+		// each message needs its own chunk, the summaries of the chunks are summarized in the last request
+		openaiCfg, ts := startTestServer(
+			t,
+			apiCompletionURL,
+			[]testRequest{
+				{"", completionResponse("- part 1"), http.StatusOK},
+				{"", completionResponse("- part 2"), http.StatusOK},
+				{
+					`{"model":"dummy-test","messages":[{"role":"system","content":` + jsonString(summarySystemMessage) + `},{"role":"user","content":"- part 1\n- part 2"}]}`,
+					completionResponse(summary),
+					http.StatusOK,
+				},
+			},
+		)
+		defer ts.Close()
+		openaiCfg.Model = "dummy-test"
+
+		cfg := &config.Config{}
+		cfg.Set("openai", openaiCfg)
+		commands := GetCommands(base, cfg, nil)
+
+		message := msg.Message{}
+		message.Text = "summarize thread"
+		message.Channel = "C1234"
+		message.Thread = "1234.5678"
+		message.Timestamp = "1234.9999"
+
+		threadRef := msg.MessageRef{Channel: "C1234", Thread: "1234.5678", Timestamp: "1234.5678"}
+		slackClient.On("GetThreadMessages", threadRef).Once().Return([]slack.Message{
+			{Msg: slack.Msg{User: "U1234", Text: strings.Repeat("a", 150)}},
+			{Msg: slack.Msg{User: "U5678", Text: strings.Repeat("b", 150)}},
+		}, nil)
+		mocks.AssertReaction(slackClient, ":coffee:", message)
+		mocks.AssertRemoveReaction(slackClient, ":coffee:", message)
+		mocks.AssertSlackMessage(slackClient, message, "*Summary of the thread:*\n"+summary, mock.Anything)
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+	})
+
+	t.Run("Channel digest", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+
+		openaiCfg, ts := startTestServer(
+			t,
+			apiCompletionURL,
+			[]testRequest{
+				{
+					`{"model":"gpt-4o","messages":[{"role":"system","content":` + jsonString(summarySystemMessage) + `},{"role":"user","content":"User <@U1234> wrote: shall we deploy on friday?\nUser <@U5678> wrote: yes, please prepare the release"}]}`,
+					completionResponse(summary),
+					http.StatusOK,
+				},
+			},
+		)
+		defer ts.Close()
+
+		cfg := &config.Config{}
+		cfg.Set("openai", openaiCfg)
+		commands := GetCommands(base, cfg, nil)
+
+		// the history is loaded page by page, newest first
+		slackClient.On("GetConversationHistory", mock.MatchedBy(func(params *slack.GetConversationHistoryParameters) bool {
+			return params.ChannelID == "C1234" && params.Cursor == "" && params.Oldest != ""
+		})).Once().Return(&slack.GetConversationHistoryResponse{
+			HasMore: true,
+			ResponseMetaData: struct {
+				NextCursor string `json:"next_cursor"`
+			}{NextCursor: "page2"},
+			Messages: []slack.Message{
+				{Msg: slack.Msg{User: "U5678", Text: "yes, please prepare the release"}},
+				{Msg: slack.Msg{User: "U9999", Text: "<@U9999> has joined the channel", SubType: slack.MsgSubTypeChannelJoin}},
+			},
+		}, nil)
+		slackClient.On("GetConversationHistory", mock.MatchedBy(func(params *slack.GetConversationHistoryParameters) bool {
+			return params.ChannelID == "C1234" && params.Cursor == "page2"
+		})).Once().Return(&slack.GetConversationHistoryResponse{
+			Messages: []slack.Message{
+				{Msg: slack.Msg{User: "U1234", Text: "shall we deploy on friday?"}},
+			},
+		}, nil)
+
+		// scheduled via cron: no timestamp, so the digest is posted into the channel of the cron
+		message := msg.Message{}
+		message.Text = "digest <#C1234|dev> 7d"
+		message.Channel = "C5678"
+		message.User = "cron"
+
+		mocks.AssertReaction(slackClient, ":coffee:", message)
+		mocks.AssertRemoveReaction(slackClient, ":coffee:", message)
+		mocks.AssertSlackMessage(slackClient, message, "*Digest of <#C1234> of the last 7d:*\n"+summary, mock.Anything)
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+	})
+
+	t.Run("Empty channel digest", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+
+		openaiCfg := defaultConfig
+		openaiCfg.APIKey = "0815pass"
+		cfg := &config.Config{}
+		cfg.Set("openai", openaiCfg)
+		commands := GetCommands(base, cfg, nil)
+
+		message := msg.Message{}
+		message.Text = "digest #dev"
+		message.Channel = "C5678"
+		message.Timestamp = "1234"
+
+		slackClient.On("GetConversationHistory", mock.Anything).Once().Return(&slack.GetConversationHistoryResponse{}, nil)
+		mocks.AssertSlackMessage(slackClient, message, "There were no messages in <#C1234> in the last 24h", mock.Anything)
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+
+		message.Text = "digest #unknown"
+		mocks.AssertError(slackClient, message, "unknown channel: unknown")
+
+		actual = commands.Run(message)
+		assert.True(t, actual)
+	})
+}
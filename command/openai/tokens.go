@@ -2,6 +2,10 @@ package openai
 
 import (
 	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/tiktoken-go/tokenizer"
 )
 
 // https://platform.openai.com/docs/models/
@@ -19,32 +23,113 @@ var maxTokens = map[string]int{
 
 var modelDateRe = regexp.MustCompile(`-\d{4}`)
 
-// truncateMessages will truncate the messages to fit into the max tokens limit of the model
-// we always try to keep the last message, so we will truncate the first (oldest) messages
-func truncateMessages(model string, inputMessages []ChatMessage) ([]ChatMessage, int, int) {
-	maxTokens := getMaxTokensForModel(model)
-	currentTokens := 0
-	truncatedMessages := 0
+const (
+	// each message has an overhead for the role and the separators, see https://github.com/openai/openai-cookbook/blob/main/examples/How_to_count_tokens_with_tiktoken.ipynb
+	tokensPerMessage = 3
+
+	// estimated tokens of an attached image (high detail, 1024x1024)
+	tokensPerImage = 765
+
+	// part of the context which is reserved for the summary of the truncated messages
+	historySummaryRatio = 10
+)
+
+// the BPE encodings are embedded in the binary, the vocabulary is only loaded on first usage
+var codecs = map[tokenizer.Encoding]func() tokenizer.Codec{
+	tokenizer.Cl100kBase: sync.OnceValue(func() tokenizer.Codec {
+		codec, _ := tokenizer.Get(tokenizer.Cl100kBase)
+		return codec
+	}),
+	tokenizer.O200kBase: sync.OnceValue(func() tokenizer.Codec {
+		codec, _ := tokenizer.Get(tokenizer.O200kBase)
+		return codec
+	}),
+}
+
+// getEncoding returns the encoding of the model. Models of other providers use their own tokenizers, o200k is a good approximation there.
+func getEncoding(model string) tokenizer.Encoding {
+	if model == "gpt-4" ||
+		strings.HasPrefix(model, "gpt-4-") ||
+		strings.HasPrefix(model, "gpt-3.5") ||
+		strings.HasPrefix(model, "gpt-35") ||
+		strings.HasPrefix(model, "text-embedding-") {
+		return tokenizer.Cl100kBase
+	}
+
+	return tokenizer.O200kBase
+}
+
+// countTokens returns the number of tokens of the text, using the tokenizer of the model
+func countTokens(model string, text string) int {
+	if text == "" {
+		return 0
+	}
+
+	count, err := codecs[getEncoding(model)]().Count(text)
+	if err != nil {
+		return estimateTokensForMessage(text)
+	}
+
+	return count
+}
+
+// countMessageTokens returns the number of tokens of a chat message, including the overhead of the message and attached images
+func countMessageTokens(model string, message ChatMessage) int {
+	return tokensPerMessage + countTokens(model, message.GetText()) + len(message.GetImages())*tokensPerImage
+}
+
+// truncateMessages will truncate the messages to fit into the max tokens of the model. System messages are always kept,
+// from the other messages the newest ones are kept, including the current question.
+// It returns the kept messages, the dropped messages (both in chronological order) and the number of tokens of the kept messages.
+// When messages are dropped, a part of the context is left for a summary of the dropped messages.
+func truncateMessages(model string, maxTokens int, inputMessages []ChatMessage) ([]ChatMessage, []ChatMessage, int) {
+	systemTokens := 0
+	totalTokens := 0
+	tokens := make([]int, len(inputMessages))
+	for i, message := range inputMessages {
+		tokens[i] = countMessageTokens(model, message)
+		totalTokens += tokens[i]
+		if message.Role == roleSystem {
+			systemTokens += tokens[i]
+		}
+	}
+
+	if totalTokens < maxTokens {
+		return inputMessages, nil, totalTokens
+	}
 
 	// Walk newest→oldest so we always keep the most recent messages (including the current prompt).
-	kept := make([]ChatMessage, 0, len(inputMessages))
+	budget := maxTokens - maxTokens/historySummaryRatio - systemTokens
+	currentTokens := systemTokens
+	keep := make([]bool, len(inputMessages))
+	full := false
 	for i := len(inputMessages) - 1; i >= 0; i-- {
-		message := inputMessages[i]
-		tokens := estimateTokensForMessage(message.Content)
-		if currentTokens+tokens >= maxTokens {
-			truncatedMessages++
+		if inputMessages[i].Role == roleSystem {
+			keep[i] = true
 			continue
 		}
-		currentTokens += tokens
-		kept = append(kept, message)
+
+		// keep the conversation without gaps: all older messages are dropped as well
+		if full || tokens[i] >= budget {
+			full = true
+			continue
+		}
+		budget -= tokens[i]
+		currentTokens += tokens[i]
+		keep[i] = true
 	}
 
-	// Reverse kept to restore chronological order.
-	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
-		kept[i], kept[j] = kept[j], kept[i]
+	kept := make([]ChatMessage, 0, len(inputMessages))
+	dropped := make([]ChatMessage, 0)
+	for i, message := range inputMessages {
+		if keep[i] {
+			kept = append(kept, message)
+		} else {
+			dropped = append(dropped, message)
+		}
 	}
 
-	return kept, currentTokens, truncatedMessages
+	return kept, dropped, currentTokens
 }
 
 func getMaxTokensForModel(model string) int {
@@ -60,8 +145,34 @@ func getMaxTokensForModel(model string) int {
 	return 128000
 }
 
-// to lower the dependency to heavy external libs we use the rule of thumbs which is totally fine here
+// estimateTokensForMessage is the rule of thumb which is used when the text can't be tokenized
 // https://platform.openai.com/tokenizer
 func estimateTokensForMessage(message string) int {
 	return len(message) / 4
 }
+
+// TruncateText cuts the end of the text to fit into the given number of tokens, limited by the context limit of the configured model
+func (c Config) TruncateText(text string, tokens int) string {
+	model := c.Model
+	tokens = min(tokens, c.GetContextLimit(model))
+	if len(text) <= tokens {
+		// a token has at least one byte
+		return text
+	}
+
+	_, parts, err := codecs[getEncoding(model)]().Encode(text)
+	if err != nil {
+		if estimateTokensForMessage(text) <= tokens {
+			return text
+		}
+		// estimated by bytes: don't cut within a multibyte character
+		return strings.ToValidUTF8(text[:tokens*4], "") + "\n...(truncated)"
+	}
+
+	if len(parts) <= tokens {
+		return text
+	}
+
+	// a token might end within a multibyte character
+	return strings.ToValidUTF8(strings.Join(parts[:tokens], ""), "") + "\n...(truncated)"
+}
@@ -1,9 +1,11 @@
 package openai
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/tiktoken-go/tokenizer"
 )
 
 func TestModels(t *testing.T) {
@@ -25,46 +27,85 @@ func TestModels(t *testing.T) {
 	}
 }
 
-func TestTruncate(t *testing.T) {
-	messages := []ChatMessage{
-		{Content: "hello, Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua."},
-		{Content: "hello i am a super long string, with many tokens"},
-		{Content: "hello i am a super long string with many tokens, foo bar baz"},
-		{Content: "hello i am a super long string with many tokens, foo bar baz"},
-		{Content: "or am i? do you think so? and what about this?"},
-		{Content: "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua."},
-		{Content: "Lorem ipsum domino sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua."},
+func TestContextLimit(t *testing.T) {
+	cfg := Config{
+		ContextLimits: []ContextLimit{
+			{Model: "gpt-5", Tokens: 200000},
+			{Model: "gpt-5-mini", Tokens: 50000},
+		},
 	}
 
-	outputMessages, inputTokens, truncatedMessages := truncateMessages("dummy-test", messages)
-
-	assert.Len(t, outputMessages, 5)
-	assert.Equal(t, 99, inputTokens) // newest 5 messages fit; msg[0] and msg[2] are dropped
-	assert.Equal(t, 2, truncatedMessages)
-
-	// The newest (last) messages must survive truncation — we keep the tail, not the head.
-	assert.Equal(t, messages[len(messages)-1], outputMessages[len(outputMessages)-1], "last message must be kept")
-	assert.Equal(t, messages[len(messages)-2], outputMessages[len(outputMessages)-2], "second-to-last message must be kept")
+	assert.Equal(t, 200000, cfg.GetContextLimit("gpt-5.2"))
+	assert.Equal(t, 50000, cfg.GetContextLimit("gpt-5-mini-2025-08-07"))
+	assert.Equal(t, 128000, cfg.GetContextLimit("gpt-4o"))
+	assert.Equal(t, 100, cfg.GetContextLimit("dummy-test"))
 }
 
-func TestTruncateKeepsNewest(t *testing.T) {
-	// dummy-test model has max 100 tokens, each char≈0.25 tokens (estimateTokensForMessage divides by 4)
-	// "system" = 6 chars = 1 token, "current user question" = 21 chars = 5 tokens
-	// Pack old messages to fill the budget, then verify the newest (current prompt) is still present.
-	old := ChatMessage{Content: "old history old history old history old history old history old history old history old history old"} // 99 chars ≈ 24 tokens * 4 = 96
-	current := ChatMessage{Content: "current user question"}
+func TestTruncate(t *testing.T) {
+	// dummy-test model has max 100 tokens, 10 of them are reserved for the summary of the dropped messages
+	system := ChatMessage{Role: roleSystem, Content: "system prompt"}                                                                                  // 2+3 tokens
+	short := ChatMessage{Role: roleUser, Content: "short"}                                                                                             // 1+3 tokens
+	old := ChatMessage{Role: roleUser, Content: "old history old history old history old history old history old history old history old history old"} // 17+3 tokens
+	current := ChatMessage{Role: roleUser, Content: "current user question"}                                                                           // 3+3 tokens
+
+	t.Run("Fits", func(t *testing.T) {
+		inputMessages := []ChatMessage{system, old, old, current}
 
-	inputMessages := []ChatMessage{old, old, old, old, current}
+		outputMessages, dropped, inputTokens := truncateMessages("dummy-test", 100, inputMessages)
+		assert.Equal(t, inputMessages, outputMessages)
+		assert.Empty(t, dropped)
+		assert.Equal(t, 51, inputTokens)
+	})
 
-	outputMessages, _, truncated := truncateMessages("dummy-test", inputMessages)
+	t.Run("Keeps system messages and the newest messages", func(t *testing.T) {
+		inputMessages := []ChatMessage{system, short, old, old, old, old, old, current}
 
-	assert.Positive(t, truncated, "some old messages should be truncated")
-	assert.Equal(t, current, outputMessages[len(outputMessages)-1], "current (newest) message must survive truncation")
+		outputMessages, dropped, inputTokens := truncateMessages("dummy-test", 100, inputMessages)
+		assert.Equal(t, []ChatMessage{system, old, old, old, current}, outputMessages)
+		// the short message would fit, but there should be no gap in the conversation
+		assert.Equal(t, []ChatMessage{short, old, old}, dropped)
+		assert.Equal(t, 71, inputTokens)
+	})
 }
 
 func TestCountTokens(t *testing.T) {
 	t.Run("Count", func(t *testing.T) {
-		actual := estimateTokensForMessage("hello you!")
-		assert.Equal(t, 2, actual)
+		assert.Equal(t, 3, countTokens("gpt-4o", "hello you!"))
+		assert.Equal(t, 3, countTokens("gpt-4", "hello you!"))
+		assert.Equal(t, 0, countTokens("gpt-4o", ""))
+		assert.Equal(t, 2, estimateTokensForMessage("hello you!"))
+	})
+
+	t.Run("Encoding", func(t *testing.T) {
+		assert.Equal(t, tokenizer.O200kBase, getEncoding("gpt-4o-mini"))
+		assert.Equal(t, tokenizer.O200kBase, getEncoding("gpt-5.2"))
+		assert.Equal(t, tokenizer.O200kBase, getEncoding("claude-sonnet-4-5"))
+		assert.Equal(t, tokenizer.Cl100kBase, getEncoding("gpt-4"))
+		assert.Equal(t, tokenizer.Cl100kBase, getEncoding("gpt-4-turbo"))
+		assert.Equal(t, tokenizer.Cl100kBase, getEncoding("gpt-3.5-turbo"))
+	})
+
+	t.Run("Message", func(t *testing.T) {
+		message := newUserMessage("hello you!", []ContentPart{{Type: contentTypeImage, ImageURL: &ImageURL{URL: "data:image/png;base64,UE5H"}}})
+		assert.Equal(t, 3+3+tokensPerImage, countMessageTokens("gpt-4o", message))
 	})
 }
+
+func TestTruncateText(t *testing.T) {
+	cfg := Config{Model: "gpt-4o"}
+	assert.Equal(t, "short text", cfg.TruncateText("short text", 100))
+	assert.Equal(t, "123456\n...(truncated)", cfg.TruncateText("1234567890123", 2))
+	assert.Equal(t, "hello world,\n...(truncated)", cfg.TruncateText("hello world, how are you today?", 3))
+
+	// no broken multibyte characters
+	assert.Equal(t, "äö\n...(truncated)", cfg.TruncateText("äöü äöü äöü äöü", 2))
+
+	// limited by the max tokens of the model
+	actual := Config{Model: "dummy-test"}.TruncateText(strings.Repeat("hello ", 1000), 1000)
+	assert.Equal(t, "hello"+strings.Repeat(" hello", 99)+"\n...(truncated)", actual)
+
+	// limited by the configured context limit
+	cfg.ContextLimits = []ContextLimit{{Model: "gpt-4o", Tokens: 10}}
+	actual = cfg.TruncateText(strings.Repeat("hello ", 1000), 1000)
+	assert.Equal(t, "hello"+strings.Repeat(" hello", 9)+"\n...(truncated)", actual)
+}
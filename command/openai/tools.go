@@ -0,0 +1,381 @@
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/client"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// name of the single tool which is offered to the model
+	toolName = "run_bot_command"
+
+	// max number of model calls with tool calls, before we give up to get a final answer
+	maxToolIterations = 5
+
+	// the output of a command is truncated to this length before it's passed to the model
+	maxToolOutputLength = 4000
+)
+
+// toolRunner executes whitelisted bot commands on behalf of the model and captures their output
+type toolRunner struct {
+	cfg      ToolsConfig
+	capture  *OutputCapture
+	commands *bot.Commands
+
+	once  sync.Once
+	tools []Tool
+}
+
+// newToolRunner uses the live bot commands as tools: their output can only be captured when they are sending
+// their messages via the OutputCapture, which has to be the client of the given base command
+func newToolRunner(cfg ToolsConfig, base bot.BaseCommand, commands *bot.Commands) *toolRunner {
+	if !cfg.IsEnabled() || commands == nil {
+		return nil
+	}
+
+	capture, ok := base.SlackClient.(*OutputCapture)
+	if !ok {
+		log.Warn("openai tools are disabled: the output of the commands can't be captured")
+		return nil
+	}
+
+	return &toolRunner{
+		cfg:      cfg,
+		capture:  capture,
+		commands: commands,
+	}
+}
+
+// init builds the tool description lazily, as the command list is not complete yet when the openai command is created
+func (r *toolRunner) init() {
+	r.once.Do(func() {
+		r.tools = []Tool{
+			{
+				Type: "function",
+				Function: ToolFunction{
+					Name:        toolName,
+					Description: r.getDescription(r.commands.GetHelp()),
+					Parameters: map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"command": map[string]any{
+								"type":        "string",
+								"description": "the full command text, like in the examples",
+							},
+						},
+						"required": []string{"command"},
+					},
+				},
+			},
+		}
+	})
+}
+
+// getDescription lists all whitelisted commands with their description and examples
+func (r *toolRunner) getDescription(help []bot.Help) string {
+	var description strings.Builder
+	description.WriteString("Executes a command of this Slack bot with the permissions of the current user and returns its output. Available commands:")
+
+	for _, entry := range help {
+		examples := make([]string, 0, len(entry.Examples))
+		for _, example := range entry.Examples {
+			if r.isAllowed(example) {
+				examples = append(examples, "`"+example+"`")
+			}
+		}
+		if len(examples) == 0 && !r.isAllowed(entry.Command) {
+			continue
+		}
+
+		fmt.Fprintf(&description, "\n- %s: %s", entry.Command, entry.Description)
+		if len(examples) > 0 {
+			fmt.Fprintf(&description, " (examples: %s)", strings.Join(examples, ", "))
+		}
+	}
+
+	return description.String()
+}
+
+func (r *toolRunner) isAllowed(command string) bool {
+	return matchesCommand(command, r.cfg.Commands) || r.isDangerous(command)
+}
+
+func (r *toolRunner) isDangerous(command string) bool {
+	return matchesCommand(command, r.cfg.DangerousCommands)
+}
+
+// run executes the command in the context of the given message and returns the captured output
+func (r *toolRunner) run(ref msg.Ref, command string) (string, bool) {
+	r.init()
+
+	message := msg.Message{
+		MessageRef: msg.MessageRef{
+			Channel:         ref.GetChannel(),
+			User:            ref.GetUser(),
+			Timestamp:       ref.GetTimestamp(),
+			Thread:          ref.GetThread(),
+			InternalMessage: true,
+		},
+		Text: command,
+	}
+
+	r.capture.start(message)
+	matched, _ := r.commands.RunWithName(message)
+	output := r.capture.stop(message)
+
+	return output, matched
+}
+
+// matchesCommand checks if the command starts with one of the given command prefixes, like "pool list"
+func matchesCommand(command string, prefixes []string) bool {
+	command = strings.ToLower(strings.TrimSpace(command))
+
+	return slices.ContainsFunc(prefixes, func(prefix string) bool {
+		prefix = strings.ToLower(strings.TrimSpace(prefix))
+
+		return prefix != "" && (command == prefix || strings.HasPrefix(command, prefix+" "))
+	})
+}
+
+// callWithTools lets the model execute whitelisted bot commands till it's able to give a final answer.
+// The final answer is not streamed, as we only know at the end if the model wants to call another tool.
+// The returned usage contains the tokens of all API calls.
+func (c *openaiCommand) callWithTools(cfg Config, messages []ChatMessage, ref msg.Ref) (<-chan string, Usage, error) {
+	c.tools.init()
+
+	var usage Usage
+	messages = slices.Clone(messages)
+	for range maxToolIterations {
+		answer, callUsage, err := callChatGPTWithTools(cfg, messages, c.tools.tools)
+		usage = usage.add(callUsage)
+		if err != nil {
+			return nil, usage, err
+		}
+
+		if len(answer.ToolCalls) == 0 {
+			response := make(chan string, 1)
+			response <- answer.Content
+			close(response)
+
+			return response, usage, nil
+		}
+
+		messages = append(messages, answer)
+		for _, toolCall := range answer.ToolCalls {
+			messages = append(messages, ChatMessage{
+				Role:       roleTool,
+				ToolCallID: toolCall.ID,
+				Content:    c.executeToolCall(ref, toolCall),
+			})
+		}
+	}
+
+	return nil, usage, fmt.Errorf("no answer after %d tool calls", maxToolIterations)
+}
+
+// executeToolCall runs the requested bot command and returns the result for the model
+func (c *openaiCommand) executeToolCall(ref msg.Ref, toolCall ToolCall) string {
+	var arguments struct {
+		Command string `json:"command"`
+	}
+	if toolCall.Function.Name != toolName || json.Unmarshal([]byte(toolCall.Function.Arguments), &arguments) != nil {
+		return fmt.Sprintf("Invalid tool call, use the %s tool with a \"command\" argument.", toolName)
+	}
+
+	command := strings.TrimSpace(arguments.Command)
+	log.Infof("openai tool call by user %s: %s", ref.GetUser(), command)
+
+	switch {
+	case c.tools.isDangerous(command):
+		c.SendBlockMessage(
+			ref,
+			[]slack.Block{
+				client.GetTextBlock(fmt.Sprintf(":warning: I'd like to execute `%s`. Please confirm:", command)),
+				slack.NewActionBlock(
+					"",
+					client.GetInteractionButton("confirm", "Execute "+command, command, slack.StyleDanger),
+				),
+			},
+			slack.MsgOptionTS(ref.GetTimestamp()),
+		)
+
+		return "The command was not executed yet: the user has to confirm it by pressing the posted button."
+	case !c.tools.isAllowed(command):
+		return fmt.Sprintf("The command %q is not allowed. Only use the commands listed in the tool description.", command)
+	}
+
+	c.SendMessage(ref, fmt.Sprintf(":gear: executing `%s`", command), slack.MsgOptionTS(ref.GetTimestamp()))
+
+	output, matched := c.tools.run(ref, command)
+	switch {
+	case !matched:
+		return fmt.Sprintf("Unknown command %q.", command)
+	case output == "":
+		return "The command was executed without any output."
+	case len(output) > maxToolOutputLength:
+		// don't cut within a multibyte character
+		return strings.ToValidUTF8(output[:maxToolOutputLength], "") + "\n...(truncated)"
+	}
+
+	return output
+}
+
+// callChatGPTWithTools does a blocking API call which offers the given tools to the model
+func callChatGPTWithTools(cfg Config, messages []ChatMessage, tools []Tool) (ChatMessage, Usage, error) {
+	jsonData, _ := json.Marshal(ChatRequest{
+		Model:           cfg.Model,
+		Temperature:     cfg.Temperature,
+		Seed:            cfg.Seed,
+		MaxTokens:       cfg.MaxTokens,
+		ReasoningEffort: cfg.ReasoningEffort,
+		Messages:        messages,
+		Tools:           tools,
+	})
+
+	if cfg.LogTexts {
+		log.Println(string(jsonData))
+	}
+
+	resp, err := doRequest(cfg, apiCompletionURL, jsonData)
+	if err != nil {
+		return ChatMessage{}, Usage{}, err
+	}
+	defer resp.Body.Close()
+
+	var chatResponse ChatResponse
+	if err = json.NewDecoder(resp.Body).Decode(&chatResponse); err != nil {
+		return ChatMessage{}, Usage{}, fmt.Errorf("error %d: %w", resp.StatusCode, err)
+	}
+
+	if err = chatResponse.GetError(); err != nil {
+		return ChatMessage{}, Usage{}, err
+	}
+
+	if len(chatResponse.Choices) == 0 {
+		return ChatMessage{}, chatResponse.GetUsage(), errors.New("no choices returned")
+	}
+
+	return chatResponse.GetMessage(), chatResponse.GetUsage(), nil
+}
+
+// OutputCapture is a SlackClient which collects the messages of the currently executed tool commands.
+// Messages to other references (e.g. later async updates) are passed to the real client.
+type OutputCapture struct {
+	client.SlackClient
+
+	mu      sync.Mutex
+	outputs map[string]*strings.Builder
+}
+
+// NewOutputCapture wraps the client, it has to be used by all commands which are usable as openai tools
+func NewOutputCapture(slackClient client.SlackClient) *OutputCapture {
+	return &OutputCapture{
+		SlackClient: slackClient,
+		outputs:     make(map[string]*strings.Builder),
+	}
+}
+
+func getCaptureKey(ref msg.Ref) string {
+	return ref.GetChannel() + "-" + ref.GetTimestamp()
+}
+
+func (o *OutputCapture) start(ref msg.Ref) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.outputs[getCaptureKey(ref)] = &strings.Builder{}
+}
+
+func (o *OutputCapture) stop(ref msg.Ref) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	key := getCaptureKey(ref)
+	output := o.outputs[key]
+	delete(o.outputs, key)
+
+	return strings.TrimSpace(output.String())
+}
+
+// write appends the text to the output of the ref, returns false if the ref is not captured
+func (o *OutputCapture) write(ref msg.Ref, text string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	output, ok := o.outputs[getCaptureKey(ref)]
+	if !ok {
+		return false
+	}
+
+	if text != "" {
+		output.WriteString(text + "\n")
+	}
+
+	return true
+}
+
+func (o *OutputCapture) SendMessage(ref msg.Ref, text string, options ...slack.MsgOption) string {
+	if o.write(ref, text) {
+		return ""
+	}
+
+	return o.SlackClient.SendMessage(ref, text, options...)
+}
+
+func (o *OutputCapture) SendEphemeralMessage(ref msg.Ref, text string, options ...slack.MsgOption) {
+	if !o.write(ref, text) {
+		o.SlackClient.SendEphemeralMessage(ref, text, options...)
+	}
+}
+
+func (o *OutputCapture) SendBlockMessage(ref msg.Ref, blocks []slack.Block, options ...slack.MsgOption) string {
+	if o.write(ref, blocksToText(blocks)) {
+		return ""
+	}
+
+	return o.SlackClient.SendBlockMessage(ref, blocks, options...)
+}
+
+func (o *OutputCapture) ReplyError(ref msg.Ref, err error) {
+	if !o.write(ref, "Error: "+err.Error()) {
+		o.SlackClient.ReplyError(ref, err)
+	}
+}
+
+// blocksToText extracts the readable texts of the given blocks
+func blocksToText(blocks []slack.Block) string {
+	lines := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *slack.SectionBlock:
+			if b.Text != nil {
+				lines = append(lines, b.Text.Text)
+			}
+			for _, field := range b.Fields {
+				lines = append(lines, field.Text)
+			}
+		case *slack.HeaderBlock:
+			if b.Text != nil {
+				lines = append(lines, b.Text.Text)
+			}
+		case *slack.ContextBlock:
+			for _, element := range b.ContextElements.Elements {
+				if text, ok := element.(*slack.TextBlockObject); ok {
+					lines = append(lines, text.Text)
+				}
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
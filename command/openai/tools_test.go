@@ -0,0 +1,178 @@
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// queueTestCommand is a simple bot command which is exposed as tool
+type queueTestCommand struct {
+	bot.BaseCommand
+}
+
+func (c *queueTestCommand) GetMatcher() matcher.Matcher {
+	return matcher.NewGroupMatcher(
+		matcher.NewTextMatcher("list queue", func(_ matcher.Result, message msg.Message) {
+			c.SendMessage(message, "There are 2 queued commands")
+		}),
+		matcher.NewTextMatcher("clear queue", func(_ matcher.Result, message msg.Message) {
+			c.ReplyError(message, errors.New("should not be executed"))
+		}),
+	)
+}
+
+func (c *queueTestCommand) GetHelp() []bot.Help {
+	return []bot.Help{
+		{
+			Command:     "list queue",
+			Description: "lists all queued commands",
+			Examples:    []string{"list queue"},
+		},
+		{
+			Command:     "clear queue",
+			Description: "removes all queued commands",
+			Examples:    []string{"clear queue"},
+		},
+		{
+			Command:     "secret command",
+			Description: "not whitelisted",
+			Examples:    []string{"secret command"},
+		},
+	}
+}
+
+func TestTools(t *testing.T) {
+	storage.InitStorage("")
+
+	t.Run("Match commands", func(t *testing.T) {
+		prefixes := []string{"pool list", "Jira", ""}
+
+		assert.True(t, matchesCommand("pool list", prefixes))
+		assert.True(t, matchesCommand("pool list free", prefixes))
+		assert.True(t, matchesCommand("jira PROJ-1", prefixes))
+		assert.False(t, matchesCommand("pool lock", prefixes))
+		assert.False(t, matchesCommand("jiraa PROJ-1", prefixes))
+		assert.False(t, matchesCommand("", prefixes))
+	})
+
+	t.Run("Capture output", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		capture := NewOutputCapture(slackClient)
+
+		ref := msg.MessageRef{Channel: "C123", Timestamp: "1234"}
+		otherRef := msg.MessageRef{Channel: "C123", Timestamp: "5678"}
+
+		capture.start(ref)
+		capture.SendMessage(ref, "hello")
+		capture.SendBlockMessage(ref, []slack.Block{
+			client.GetTextBlock("block text"),
+			client.GetContextBlock("context text"),
+		})
+		capture.ReplyError(ref, errors.New("oops"))
+
+		// other messages are passed to slack
+		mocks.AssertSlackMessage(slackClient, otherRef, "not captured")
+		capture.SendMessage(otherRef, "not captured")
+
+		assert.Equal(t, "hello\nblock text\ncontext text\nError: oops", capture.stop(ref))
+	})
+
+	t.Run("Execute tools", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: NewOutputCapture(slackClient)}
+
+		requests := make([]ChatRequest, 0)
+		responses := []string{
+			`{"choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","content":null,"tool_calls":[
+				{"id":"call_1","type":"function","function":{"name":"run_bot_command","arguments":"{\"command\":\"list queue\"}"}},
+				{"id":"call_2","type":"function","function":{"name":"run_bot_command","arguments":"{\"command\":\"clear queue\"}"}},
+				{"id":"call_3","type":"function","function":{"name":"run_bot_command","arguments":"{\"command\":\"secret command\"}"}}
+			]}}]}`,
+			`{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"There are 2 queued commands. Please confirm the clearing."}}]}`,
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc(apiCompletionURL, func(res http.ResponseWriter, req *http.Request) {
+			var request ChatRequest
+			assert.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+			requests = append(requests, request)
+
+			res.Write([]byte(responses[len(requests)-1]))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		openaiCfg := defaultConfig
+		openaiCfg.APIHost = server.URL
+		openaiCfg.APIKey = "0815pass"
+		openaiCfg.InitialSystemMessage = ""
+		openaiCfg.Tools = ToolsConfig{
+			Enabled:           true,
+			Commands:          []string{"list queue"},
+			DangerousCommands: []string{"clear queue"},
+		}
+		cfg := &config.Config{}
+		cfg.Set("openai", openaiCfg)
+
+		toolCommands := &bot.Commands{}
+		toolCommands.AddCommand(&queueTestCommand{base})
+		commands := GetCommands(base, cfg, toolCommands)
+
+		message := msg.Message{}
+		message.Text = "openai how many commands are queued?"
+		message.Channel = "testchan"
+		message.User = "U1234"
+		message.Timestamp = "1234"
+		ref := message.MessageRef
+
+		done := make(chan bool)
+		mocks.AssertReaction(slackClient, ":bulb:", ref)
+		mocks.AssertReaction(slackClient, ":speech_balloon:", ref)
+		mocks.AssertRemoveReaction(slackClient, ":bulb:", ref)
+		slackClient.On("RemoveReaction", util.Reaction(":speech_balloon:"), ref).Once().Run(func(mock.Arguments) {
+			close(done)
+		})
+		mocks.AssertSlackMessage(slackClient, ref, ":gear: executing `list queue`", mock.Anything)
+		slackClient.On("SendBlockMessage", ref, mock.Anything, mock.Anything).Once().Return("")
+		mocks.AssertSlackMessage(slackClient, ref, ":bulb: thinking...", mock.Anything)
+		mocks.AssertSlackMessage(slackClient, ref, "There are 2 queued commands. Please confirm the clearing.", mock.Anything, mock.Anything)
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+		<-done
+
+		require.Len(t, requests, 2)
+
+		// the tool description only contains the whitelisted commands
+		require.Len(t, requests[0].Tools, 1)
+		description := requests[0].Tools[0].Function.Description
+		assert.Contains(t, description, "list queue: lists all queued commands (examples: `list queue`)")
+		assert.Contains(t, description, "clear queue: removes all queued commands")
+		assert.NotContains(t, description, "secret command")
+
+		// the results of the tool calls are passed back to the model
+		toolMessages := requests[1].Messages[2:]
+		require.Len(t, toolMessages, 3)
+		assert.Equal(t, ChatMessage{Role: roleTool, ToolCallID: "call_1", Content: "There are 2 queued commands"}, toolMessages[0])
+		assert.Equal(t, "call_2", toolMessages[1].ToolCallID)
+		assert.Contains(t, toolMessages[1].Content, "the user has to confirm it")
+		assert.Equal(t, "call_3", toolMessages[2].ToolCallID)
+		assert.Contains(t, toolMessages[2].Content, "is not allowed")
+	})
+}
@@ -0,0 +1,322 @@
+package openai
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// the usage is aggregated per day, the key is the date
+	usageStorageKey = "chatgpt_usage"
+	usageDateFormat = "2006-01-02"
+	usageRetention  = 400 * 24 * time.Hour
+
+	defaultUsagePeriod = "30d"
+)
+
+// default prices in USD per 1M tokens (or per image), see https://openai.com/api/pricing/
+var defaultPrices = []ModelPrice{
+	{Model: "gpt-4o", Input: 2.5, Output: 10},
+	{Model: "gpt-4o-mini", Input: 0.15, Output: 0.6},
+	{Model: "gpt-4.1", Input: 2, Output: 8},
+	{Model: "gpt-4.1-mini", Input: 0.4, Output: 1.6},
+	{Model: "gpt-5", Input: 1.25, Output: 10},
+	{Model: "gpt-5-mini", Input: 0.25, Output: 2},
+	{Model: "gpt-5-nano", Input: 0.05, Output: 0.4},
+	{Model: "dall-e-2", Image: 0.02},
+	{Model: "dall-e-3", Image: 0.04},
+	{Model: "gpt-image-1", Image: 0.04},
+}
+
+var (
+	requestsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "slack_bot_openai_requests_total",
+		Help: "number of requests to the LLM API",
+	}, []string{"model"})
+	tokensCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "slack_bot_openai_tokens_total",
+		Help: "number of used tokens, type is \"input\" or \"output\"",
+	}, []string{"model", "type"})
+	costCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "slack_bot_openai_cost_dollars_total",
+		Help: "estimated costs in USD",
+	}, []string{"model"})
+
+	registerMetrics sync.Once
+	usageMu         sync.Mutex
+	lastUsagePrune  string
+)
+
+// UsageConfig defines the prices of the models and the budgets per user
+type UsageConfig struct {
+	// estimated costs in USD per user, 0 means unlimited
+	DailyBudget   float64 `mapstructure:"daily_budget"`
+	MonthlyBudget float64 `mapstructure:"monthly_budget"`
+
+	// prices per model, replaces the default prices
+	Prices []ModelPrice `mapstructure:"prices"`
+}
+
+// ModelPrice in USD per 1M tokens and per generated image. The model is a prefix, the longest matching one is used.
+type ModelPrice struct {
+	Model  string  `mapstructure:"model"`
+	Input  float64 `mapstructure:"input"`
+	Output float64 `mapstructure:"output"`
+	Image  float64 `mapstructure:"image"`
+}
+
+// getCost estimates the costs in USD of the used tokens
+func (c UsageConfig) getCost(model string, usage Usage) float64 {
+	var price ModelPrice
+	for _, current := range c.Prices {
+		if strings.HasPrefix(model, current.Model) && len(current.Model) > len(price.Model) {
+			price = current
+		}
+	}
+
+	return (float64(usage.InputTokens)*price.Input+float64(usage.OutputTokens)*price.Output)/1_000_000 + float64(usage.Images)*price.Image
+}
+
+// Usage is the number of used tokens (and generated images) of a request
+type Usage struct {
+	InputTokens  int
+	OutputTokens int
+	Images       int
+}
+
+func (u Usage) add(usage Usage) Usage {
+	return Usage{
+		InputTokens:  u.InputTokens + usage.InputTokens,
+		OutputTokens: u.OutputTokens + usage.OutputTokens,
+		Images:       u.Images + usage.Images,
+	}
+}
+
+// orEstimate fills the missing token counts, if they are not reported by the API
+func (u Usage) orEstimate(model string, messages []ChatMessage, output string) Usage {
+	if u.InputTokens == 0 {
+		for _, message := range messages {
+			u.InputTokens += countMessageTokens(model, message)
+		}
+	}
+	if u.OutputTokens == 0 {
+		u.OutputTokens = countTokens(model, output)
+	}
+
+	return u
+}
+
+// usageEntry is the aggregated usage of a user in a channel with a model on one day
+type usageEntry struct {
+	User         string  `json:"user"`
+	Channel      string  `json:"channel"`
+	Model        string  `json:"model"`
+	Requests     int     `json:"requests"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// recordUsage stores the usage of a request. User and channel are empty for requests without a user context, like templates.
+func recordUsage(cfg UsageConfig, user string, channel string, model string, usage Usage) {
+	cost := cfg.getCost(model, usage)
+
+	requestsCounter.WithLabelValues(model).Inc()
+	tokensCounter.WithLabelValues(model, "input").Add(float64(usage.InputTokens))
+	tokensCounter.WithLabelValues(model, "output").Add(float64(usage.OutputTokens))
+	costCounter.WithLabelValues(model).Add(cost)
+
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	now := time.Now()
+	day := now.Format(usageDateFormat)
+
+	var entries []usageEntry
+	_ = storage.Read(usageStorageKey, day, &entries)
+
+	idx := slices.IndexFunc(entries, func(entry usageEntry) bool {
+		return entry.User == user && entry.Channel == channel && entry.Model == model
+	})
+	if idx == -1 {
+		entries = append(entries, usageEntry{User: user, Channel: channel, Model: model})
+		idx = len(entries) - 1
+	}
+	entries[idx].Requests++
+	entries[idx].InputTokens += usage.InputTokens
+	entries[idx].OutputTokens += usage.OutputTokens
+	entries[idx].Cost += cost
+
+	if err := storage.Write(usageStorageKey, day, entries); err != nil {
+		log.Warnf("Error while storing openai usage: %s", err)
+	}
+
+	if lastUsagePrune != day {
+		pruneUsage(now)
+		lastUsagePrune = day
+	}
+}
+
+// pruneUsage deletes the usage of days which are older than the retention, the caller has to hold usageMu
+func pruneUsage(now time.Time) {
+	oldest := now.Add(-usageRetention).Format(usageDateFormat)
+
+	days, _ := storage.GetKeys(usageStorageKey)
+	for _, day := range days {
+		if day < oldest {
+			_ = storage.Delete(usageStorageKey, day)
+		}
+	}
+}
+
+// getUsage returns all entries of the days between "from" and "to"
+func getUsage(from time.Time, to time.Time) []usageEntry {
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	entries := make([]usageEntry, 0)
+	lastDay := to.Format(usageDateFormat)
+	for day := from; day.Format(usageDateFormat) <= lastDay; day = day.AddDate(0, 0, 1) {
+		var dayEntries []usageEntry
+		if err := storage.Read(usageStorageKey, day.Format(usageDateFormat), &dayEntries); err == nil {
+			entries = append(entries, dayEntries...)
+		}
+	}
+
+	return entries
+}
+
+// getUserCost sums up the estimated costs of the user since the given time
+func getUserCost(user string, from time.Time, now time.Time) float64 {
+	var cost float64
+	for _, entry := range getUsage(from, now) {
+		if entry.User == user {
+			cost += entry.Cost
+		}
+	}
+
+	return cost
+}
+
+// checkBudget returns a message for the user, when the daily or monthly budget is used up
+func checkBudget(cfg UsageConfig, user string, now time.Time) string {
+	if cfg.DailyBudget > 0 && getUserCost(user, now, now) >= cfg.DailyBudget {
+		return fmt.Sprintf("Sorry, you used up your daily AI budget of $%.2f. Please try again tomorrow!", cfg.DailyBudget)
+	}
+
+	if cfg.MonthlyBudget > 0 {
+		firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		if getUserCost(user, firstOfMonth, now) >= cfg.MonthlyBudget {
+			return fmt.Sprintf("Sorry, you used up your monthly AI budget of $%.2f. Please try again next month!", cfg.MonthlyBudget)
+		}
+	}
+
+	return ""
+}
+
+// usageSummary is the total usage of a user, channel or model
+type usageSummary struct {
+	name         string
+	requests     int
+	inputTokens  int
+	outputTokens int
+	cost         float64
+}
+
+func (s *usageSummary) add(entry usageEntry) {
+	s.requests += entry.Requests
+	s.inputTokens += entry.InputTokens
+	s.outputTokens += entry.OutputTokens
+	s.cost += entry.Cost
+}
+
+func (s *usageSummary) String() string {
+	return fmt.Sprintf("%s: $%.2f (%d requests, %d input / %d output tokens)", s.name, s.cost, s.requests, s.inputTokens, s.outputTokens)
+}
+
+// summarizeUsage groups the entries by the given key, the most expensive first
+func summarizeUsage(entries []usageEntry, getName func(usageEntry) string) []*usageSummary {
+	summaries := make(map[string]*usageSummary)
+	for _, entry := range entries {
+		name := getName(entry)
+		if summaries[name] == nil {
+			summaries[name] = &usageSummary{name: name}
+		}
+		summaries[name].add(entry)
+	}
+
+	sorted := slices.Collect(maps.Values(summaries))
+	slices.SortFunc(sorted, func(a, b *usageSummary) int {
+		return cmp.Or(cmp.Compare(b.cost, a.cost), cmp.Compare(b.inputTokens+b.outputTokens, a.inputTokens+a.outputTokens), cmp.Compare(a.name, b.name))
+	})
+
+	return sorted
+}
+
+// bot function to show the usage report of the last days
+func (c *openaiCommand) usageReport(match matcher.Result, message msg.Message) {
+	periodString := match.GetString("period")
+	if periodString == "" {
+		periodString = defaultUsagePeriod
+	}
+
+	period, err := util.ParseDuration(periodString)
+	if err != nil {
+		c.ReplyError(message, err)
+		return
+	}
+
+	now := time.Now()
+	entries := getUsage(now.Add(-period), now)
+
+	total := usageSummary{name: "*Total*"}
+	for _, entry := range entries {
+		total.add(entry)
+	}
+
+	lines := []string{
+		fmt.Sprintf("*OpenAI usage of the last %s:*", periodString),
+		total.String(),
+		"",
+		"*Usage per user:*",
+	}
+	for _, summary := range summarizeUsage(entries, func(entry usageEntry) string {
+		if entry.User == "" {
+			return "templates"
+		}
+		return fmt.Sprintf("<@%s>", entry.User)
+	}) {
+		lines = append(lines, summary.String())
+	}
+
+	lines = append(lines, "", "*Usage per channel:*")
+	for _, summary := range summarizeUsage(entries, func(entry usageEntry) string {
+		if entry.Channel == "" {
+			return "templates"
+		}
+		return fmt.Sprintf("<#%s>", entry.Channel)
+	}) {
+		lines = append(lines, summary.String())
+	}
+
+	lines = append(lines, "", "*Usage per model:*")
+	for _, summary := range summarizeUsage(entries, func(entry usageEntry) string {
+		return "`" + entry.Model + "`"
+	}) {
+		lines = append(lines, summary.String())
+	}
+
+	c.SendMessage(message, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,199 @@
+package openai
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/innogames/slack-bot/v2/command/queue"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsage(t *testing.T) {
+	storage.InitStorage("")
+
+	cfg := defaultConfig.Usage
+	cfg.DailyBudget = 1
+	cfg.MonthlyBudget = 2
+
+	t.Run("Cost", func(t *testing.T) {
+		usage := Usage{InputTokens: 1_000_000, OutputTokens: 100_000}
+
+		assert.InDelta(t, 3.5, cfg.getCost("gpt-4o", usage), 0.0001)
+		assert.InDelta(t, 0.21, cfg.getCost("gpt-4o-mini-2024-07-18", usage), 0.0001)
+		assert.InDelta(t, 0.0, cfg.getCost("llama3.1:8b", usage), 0.0001)
+	})
+
+	t.Run("Estimate usage", func(t *testing.T) {
+		messages := []ChatMessage{{Role: roleUser, Content: "12345678"}}
+
+		// "123", "456", "78" and the overhead of the message
+		assert.Equal(t, Usage{InputTokens: 6, OutputTokens: 2}, Usage{}.orEstimate("gpt-4o", messages, "1234"))
+		assert.Equal(t, Usage{InputTokens: 10, OutputTokens: 20}, Usage{InputTokens: 10, OutputTokens: 20}.orEstimate("gpt-4o", messages, "1234"))
+		assert.Equal(t, Usage{InputTokens: 11, OutputTokens: 22}, Usage{InputTokens: 10, OutputTokens: 20}.add(Usage{InputTokens: 1, OutputTokens: 2}))
+	})
+
+	t.Run("Budget", func(t *testing.T) {
+		now := time.Now()
+		assert.Empty(t, checkBudget(cfg, "U1234", now))
+
+		recordUsage(cfg, "U1234", "C1234", "gpt-4o", Usage{InputTokens: 200_000, OutputTokens: 50_000})
+		recordUsage(cfg, "U1234", "C1234", "gpt-4o", Usage{InputTokens: 200_000, OutputTokens: 10_000})
+		assert.InDelta(t, 1.6, getUserCost("U1234", now, now), 0.0001)
+		assert.Equal(t, "Sorry, you used up your daily AI budget of $1.00. Please try again tomorrow!", checkBudget(cfg, "U1234", now))
+		assert.Empty(t, checkBudget(cfg, "U5678", now))
+
+		// the usage of the earlier days of the month is part of the monthly budget
+		yesterday := now.AddDate(0, 0, -1)
+		require.NoError(t, storage.Write(usageStorageKey, yesterday.Format(usageDateFormat), []usageEntry{
+			{User: "U5678", Channel: "C1234", Model: "gpt-4o", Requests: 1, Cost: 5},
+		}))
+		if yesterday.Month() == now.Month() {
+			assert.Equal(t, "Sorry, you used up your monthly AI budget of $2.00. Please try again next month!", checkBudget(cfg, "U5678", now))
+		}
+		assert.Empty(t, checkBudget(cfg, "U5678", yesterday.AddDate(0, 1, 0)))
+	})
+
+	t.Run("Prune old usage", func(t *testing.T) {
+		old := time.Now().Add(-usageRetention - 24*time.Hour).Format(usageDateFormat)
+		require.NoError(t, storage.Write(usageStorageKey, old, []usageEntry{{User: "U1234"}}))
+
+		pruneUsage(time.Now())
+
+		var entries []usageEntry
+		require.Error(t, storage.Read(usageStorageKey, old, &entries))
+	})
+
+	t.Run("Budget exceeded", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+
+		openaiCfg := defaultConfig
+		openaiCfg.APIKey = "0815pass"
+		openaiCfg.Usage = cfg
+		botCfg := &config.Config{}
+		botCfg.Set("openai", openaiCfg)
+		commands := GetCommands(base, botCfg, nil)
+
+		message := msg.Message{}
+		message.Text = "openai whats 1+1?"
+		message.Channel = "C1234"
+		message.User = "U1234"
+		message.Timestamp = "1234"
+
+		mocks.AssertSlackMessage(slackClient, message.MessageRef, "Sorry, you used up your daily AI budget of $1.00. Please try again tomorrow!", mock.Anything)
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+	})
+
+	t.Run("Record usage of a conversation", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+
+		openaiCfg, ts := startTestServer(
+			t,
+			apiCompletionURL,
+			[]testRequest{
+				{
+					`{"model":"gpt-4o","messages":[{"role":"system","content":"You are a helpful Slack bot. By default, keep your answer short and truthful"},{"role":"user","content":"whats 1+1?"}],"stream":true,"stream_options":{"include_usage":true}}`,
+					`data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"role":"assistant","content":"2"}}]}
+
+data: {"id":"1","object":"chat.completion.chunk","choices":[],"usage":{"prompt_tokens":30,"completion_tokens":7,"total_tokens":37}}
+
+data: [DONE]`,
+					http.StatusOK,
+				},
+			},
+		)
+		defer ts.Close()
+
+		botCfg := &config.Config{}
+		botCfg.Set("openai", openaiCfg)
+		commands := GetCommands(base, botCfg, nil)
+
+		message := msg.Message{}
+		message.Text = "openai whats 1+1?"
+		message.Channel = "C5678"
+		message.User = "U9999"
+		message.Timestamp = "1234"
+		ref := message.MessageRef
+
+		mocks.AssertReaction(slackClient, ":bulb:", ref)
+		mocks.AssertReaction(slackClient, ":speech_balloon:", ref)
+		mocks.AssertRemoveReaction(slackClient, ":bulb:", ref)
+		mocks.AssertRemoveReaction(slackClient, ":speech_balloon:", ref)
+		mocks.AssertSlackMessage(slackClient, ref, ":bulb: thinking...", mock.Anything)
+		mocks.AssertSlackMessage(slackClient, ref, "2", mock.Anything, mock.Anything)
+
+		actual := commands.Run(message)
+		queue.WaitTillHavingNoQueuedMessage()
+		assert.True(t, actual)
+
+		assert.Eventually(t, func() bool {
+			for _, entry := range getUsage(time.Now(), time.Now()) {
+				if entry.User == "U9999" {
+					return entry == usageEntry{User: "U9999", Channel: "C5678", Model: "gpt-4o", Requests: 1, InputTokens: 30, OutputTokens: 7, Cost: 0.000145}
+				}
+			}
+			return false
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Usage report", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+		client.AllUsers = config.UserMap{"UADMIN": "admin"}
+
+		openaiCfg := defaultConfig
+		openaiCfg.APIKey = "0815pass"
+		botCfg := &config.Config{}
+		botCfg.AdminUsers = config.UserList{"UADMIN"}
+		botCfg.Set("openai", openaiCfg)
+		commands := GetCommands(base, botCfg, nil)
+
+		storage.DeleteCollection(usageStorageKey)
+		recordUsage(cfg, "U1234", "C1234", "gpt-4o", Usage{InputTokens: 200_000, OutputTokens: 50_000})
+		recordUsage(cfg, "U5678", "C1234", "gpt-4o-mini", Usage{InputTokens: 1000, OutputTokens: 500})
+		recordUsage(cfg, "", "", "gpt-4o-mini", Usage{InputTokens: 1000, OutputTokens: 500})
+
+		message := msg.Message{}
+		message.Text = "openai usage 7d"
+		message.User = "UADMIN"
+
+		mocks.AssertSlackMessage(slackClient, message, `*OpenAI usage of the last 7d:*
+*Total*: $1.00 (3 requests, 202000 input / 51000 output tokens)
+
+*Usage per user:*
+<@U1234>: $1.00 (1 requests, 200000 input / 50000 output tokens)
+<@U5678>: $0.00 (1 requests, 1000 input / 500 output tokens)
+templates: $0.00 (1 requests, 1000 input / 500 output tokens)
+
+*Usage per channel:*
+<#C1234>: $1.00 (2 requests, 201000 input / 50500 output tokens)
+templates: $0.00 (1 requests, 1000 input / 500 output tokens)
+
+*Usage per model:*
+`+"`gpt-4o`"+`: $1.00 (1 requests, 200000 input / 50000 output tokens)
+`+"`gpt-4o-mini`"+`: $0.00 (2 requests, 2000 input / 1000 output tokens)`)
+
+		actual := commands.Run(message)
+		assert.True(t, actual)
+
+		// no admin
+		message.User = "U1234"
+		mocks.AssertReaction(slackClient, "❌", message)
+		mocks.AssertError(slackClient, message, "sorry, you are no admin and not allowed to execute this command")
+
+		actual = commands.Run(message)
+		assert.True(t, actual)
+	})
+}
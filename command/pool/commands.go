@@ -3,18 +3,23 @@ package pool
 import (
 	"github.com/innogames/slack-bot/v2/bot"
 	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/stats"
 	"github.com/innogames/slack-bot/v2/client"
 )
 
 // GetCommands will return a list of available Pool commands...if the config is set!
-func GetCommands(cfg *config.Pool, slackClient client.SlackClient) bot.Commands {
+func GetCommands(cfg *config.Config, slackClient client.SlackClient) bot.Commands {
 	var commands bot.Commands
 
-	if !cfg.IsEnabled() {
+	if !cfg.Pool.IsEnabled() {
 		return commands
 	}
 
-	p := getNewPool(cfg)
+	p := getNewPool(&cfg.Pool)
+
+	if cfg.Metrics.IsEnabled() {
+		stats.RegisterCollector(&metricsCollector{pool: p})
+	}
 
 	commands.AddCommand(
 		newPoolCommands(slackClient, cfg, p),
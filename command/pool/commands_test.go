@@ -1,6 +1,7 @@
 package pool
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -8,8 +9,10 @@ import (
 	"github.com/innogames/slack-bot/v2/bot/config"
 	"github.com/innogames/slack-bot/v2/bot/msg"
 	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/client"
 	"github.com/innogames/slack-bot/v2/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -21,13 +24,14 @@ func TestPools(t *testing.T) {
 	base := bot.BaseCommand{SlackClient: slackClient}
 
 	t.Run("Pools are not active", func(t *testing.T) {
-		cfg := &config.Pool{}
+		cfg := &config.Config{}
 		commands := GetCommands(cfg, base)
 		assert.Empty(t, commands.GetCommandNames())
 	})
 
 	t.Run("Full test", func(t *testing.T) {
-		cfg := &config.Pool{
+		cfg := &config.Config{}
+		cfg.Pool = config.Pool{
 			LockDuration: time.Minute,
 			NotifyExpire: time.Minute,
 			Resources: []*config.Resource{
@@ -78,7 +82,7 @@ func TestPools(t *testing.T) {
 		runCommand(message)
 
 		help := commands.GetHelp()
-		assert.Len(t, help, 6)
+		assert.Len(t, help, 13)
 	})
 }
 
@@ -466,3 +470,141 @@ func TestPoolLockExpiration(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestPoolFeaturesAndHandover(t *testing.T) {
+	storage.InitStorage("")
+
+	cfg := &config.Pool{
+		LockDuration: time.Hour,
+		Resources: []*config.Resource{
+			{Name: "server1", Features: []string{"web"}},
+			{Name: "server2", Features: []string{"web", "USB plugs"}},
+			{Name: "server3", Features: []string{"web", "usb plugs"}, ExplicitLock: true},
+		},
+	}
+
+	t.Run("lock with features", func(t *testing.T) {
+		p := getNewPool(cfg)
+
+		lock, err := p.LockWithFeatures("user1", "", []string{"usb plugs", "web"})
+		require.NoError(t, err)
+		assert.Equal(t, "server2", lock.Resource.Name)
+
+		// server3 can only be locked explicitly
+		_, err = p.LockWithFeatures("user2", "", []string{"usb plugs"})
+		assert.Equal(t, ErrNoResourceAvailable, err)
+
+		_, err = p.LockWithFeatures("user2", "", []string{"unknown"})
+		assert.Equal(t, ErrNoResourceAvailable, err)
+
+		require.NoError(t, p.Unlock("user1", "server2"))
+	})
+
+	t.Run("parse features", func(t *testing.T) {
+		commands := &poolCommands{config: cfg}
+
+		features, reason, err := commands.parseFeatures("usb plugs and WEB load testing")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"USB plugs", "web"}, features)
+		assert.Equal(t, "load testing", reason)
+
+		features, reason, err = commands.parseFeatures("web, usb plugs")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"web", "USB plugs"}, features)
+		assert.Empty(t, reason)
+
+		_, _, err = commands.parseFeatures("webserver")
+		require.EqualError(t, err, "unknown feature 'webserver', available features: USB plugs, web")
+	})
+
+	t.Run("handover and force unlock", func(t *testing.T) {
+		p := getNewPool(cfg)
+
+		lock, err := p.Lock("user1", "testing", "server1")
+		require.NoError(t, err)
+
+		_, err = p.Handover("user2", "server1", "user3")
+		assert.Equal(t, ErrResourceLockedByDifferentUser, err)
+
+		_, err = p.Handover("user1", "server2", "user3")
+		assert.Equal(t, ErrNoLockedResourceFound, err)
+
+		handedOver, err := p.Handover("user1", "server1", "user2")
+		require.NoError(t, err)
+		assert.Equal(t, "user2", handedOver.User)
+		assert.Equal(t, "testing", handedOver.Reason)
+		assert.Equal(t, lock.LockUntil, handedOver.LockUntil)
+		assert.Len(t, p.GetLocks("user2"), 1)
+		assert.Empty(t, p.GetLocks("user1"))
+
+		previous, err := p.ForceUnlock("server1")
+		require.NoError(t, err)
+		assert.Equal(t, "user2", previous.User)
+		assert.Empty(t, p.GetLocks(""))
+
+		_, err = p.ForceUnlock("server1")
+		assert.Equal(t, ErrNoLockedResourceFound, err)
+	})
+
+	t.Run("commands", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+
+		client.AllUsers = config.UserMap{
+			"U1": "user1",
+			"U2": "user2",
+		}
+
+		commands := GetCommands(&config.Config{Pool: *cfg, AdminUsers: config.UserList{"U2"}}, base)
+
+		message := msg.Message{}
+		message.User = "U1"
+		message.Text = "pool lock with feature usb plugs testing"
+		mocks.AssertSlackMessageRegexp(slackClient, message, "^`server2` is locked for you until .*\n_testing_\n")
+		assert.True(t, commands.Run(message))
+
+		// unknown features must not lock any resource
+		message.Text = "pool lock with feature unknown"
+		mocks.AssertError(slackClient, message, "unknown feature 'unknown', available features: USB plugs, web")
+		assert.True(t, commands.Run(message))
+
+		message.Text = "pool lock with features web, gpu testing"
+		mocks.AssertError(slackClient, message, "unknown feature 'gpu testing', available features: USB plugs, web")
+		assert.True(t, commands.Run(message))
+
+		message.Text = "pool handover server2 to <@U2>"
+		mocks.AssertSlackMessageRegexp(slackClient, message, "^`server2` is now locked by user2 until")
+		slackClient.On("SendToUser", "user2", mock.MatchedBy(func(text string) bool {
+			return strings.HasPrefix(text, "user1 handed over `server2` to you")
+		})).Once()
+		assert.True(t, commands.Run(message))
+
+		// user1 is no admin
+		message.Text = "pool force-unlock server2"
+		mocks.AssertReaction(slackClient, "❌", message)
+		mocks.AssertError(slackClient, message, "sorry, you are no admin and not allowed to execute this command")
+		assert.True(t, commands.Run(message))
+
+		message.User = "U2"
+		message.Text = "pool lock server1"
+		mocks.AssertSlackMessageRegexp(slackClient, message, "^`server1` is locked for you until")
+		assert.True(t, commands.Run(message))
+
+		message.User = "U2"
+		message.Text = "pool handover server1 to @user1"
+		mocks.AssertSlackMessageRegexp(slackClient, message, "^`server1` is now locked by user1 until")
+		slackClient.On("SendToUser", "user1", mock.MatchedBy(func(text string) bool {
+			return strings.HasPrefix(text, "user2 handed over `server1` to you")
+		})).Once()
+		assert.True(t, commands.Run(message))
+
+		message.Text = "pool force-unlock server1"
+		mocks.AssertSlackMessage(slackClient, message, "`server1` (locked by user1) is free again")
+		slackClient.On("SendToUser", "user1", "your lock for `server1` got removed by user2").Once()
+		assert.True(t, commands.Run(message))
+
+		message.Text = "pool force-unlock server2"
+		mocks.AssertSlackMessage(slackClient, message, "`server2` (locked by user2) is free again")
+		assert.True(t, commands.Run(message))
+	})
+}
@@ -0,0 +1,61 @@
+package pool
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/pkg/errors"
+)
+
+func (c *poolCommands) handover(match matcher.Result, message msg.Message) {
+	_, userName := client.GetUserIDAndName(message.GetUser())
+
+	resourceName := match.GetString("resource")
+	_, newUserName := client.GetUserIDAndName(match.GetString("user"))
+	if newUserName == "" {
+		c.slackClient.ReplyError(message, errors.New("unknown user: "+match.GetString("user")))
+		return
+	}
+
+	lock, err := c.pool.Handover(userName, resourceName, newUserName)
+	if err != nil {
+		c.slackClient.ReplyError(message, err)
+		return
+	}
+
+	c.slackClient.SendMessage(message, fmt.Sprintf("`%s` is now locked by %s until %s", resourceName, newUserName, lock.LockUntil.Format(time.RFC1123)))
+	c.slackClient.SendToUser(
+		newUserName,
+		fmt.Sprintf("%s handed over `%s` to you: it's locked for you until %s!\n%s%s",
+			userName,
+			resourceName,
+			lock.LockUntil.Format(time.RFC1123),
+			getFormattedReason(lock.Reason),
+			getAddressesAndFeatures(lock.Resource),
+		),
+	)
+}
+
+func (c *poolCommands) forceUnlock(match matcher.Result, message msg.Message) {
+	_, userName := client.GetUserIDAndName(message.GetUser())
+
+	resourceName := match.GetString("resource")
+
+	lock, err := c.pool.ForceUnlock(resourceName)
+	if err != nil {
+		c.slackClient.ReplyError(message, err)
+		return
+	}
+
+	c.slackClient.SendMessage(message, fmt.Sprintf("`%s` (locked by %s) is free again", resourceName, lock.User))
+	if lock.User != userName {
+		c.slackClient.SendToUser(lock.User, fmt.Sprintf("your lock for `%s` got removed by %s", resourceName, userName))
+	}
+	runHooks(lock.Resource.OnUnlock, message, *lock)
+
+	// maybe someone is already waiting for it
+	c.processQueue()
+}
@@ -0,0 +1,194 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrResourceUnhealthy = errors.New("resource is unhealthy")
+
+var urlRe = regexp.MustCompile(`https?://\S+`)
+
+// healthState is the result of the latest health check of a resource
+type healthState struct {
+	Healthy   bool
+	Error     string
+	CheckedAt time.Time
+}
+
+// setHealth stores the result of a health check
+func (p *pool) setHealth(resourceName string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.health == nil {
+		p.health = make(map[string]healthState)
+	}
+
+	state := healthState{
+		Healthy:   err == nil,
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		state.Error = err.Error()
+	}
+
+	if previous, ok := p.health[resourceName]; ok && previous.Healthy != state.Healthy {
+		log.Infof("[Pool] health of %s changed: healthy=%t %s", resourceName, state.Healthy, state.Error)
+	}
+
+	p.health[resourceName] = state
+}
+
+// GetHealth returns the latest health state of a resource. False if the resource was not checked (yet)
+func (p *pool) GetHealth(resourceName string) (healthState, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state, ok := p.health[resourceName]
+
+	return state, ok
+}
+
+// isHealthy checks if the resource is not marked as unhealthy, the caller has to hold p.mu
+func (p *pool) isHealthy(resourceName string) bool {
+	state, ok := p.health[resourceName]
+
+	return !ok || state.Healthy
+}
+
+// checkHealth executes the configured health checks of all resources in parallel
+func (c *poolCommands) checkHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, resource := range c.config.Resources {
+		if !resource.HealthCheck.IsEnabled() {
+			continue
+		}
+
+		wg.Go(func() {
+			c.pool.setHealth(resource.Name, checkResource(ctx, resource))
+		})
+	}
+	wg.Wait()
+}
+
+// checkResource checks all addresses of the resource, an error is returned if one of them is not reachable
+func checkResource(ctx context.Context, resource *config.Resource) error {
+	ctx, cancel := context.WithTimeout(ctx, resource.HealthCheck.GetTimeout())
+	defer cancel()
+
+	for _, address := range resource.Addresses {
+		target := getCheckTarget(address)
+
+		var err error
+		switch resource.HealthCheck.Type {
+		case "http":
+			err = checkHTTP(ctx, target)
+		case "tcp":
+			err = checkTCP(ctx, target)
+		default:
+			err = fmt.Errorf("unknown health check type: %s", resource.HealthCheck.Type)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkHTTP(ctx context.Context, target string) error {
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.GetHTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s returned status %d", target, resp.StatusCode)
+	}
+
+	return nil
+}
+
+func checkTCP(ctx context.Context, target string) error {
+	if parsed, err := url.Parse(target); err == nil && parsed.Host != "" {
+		target = parsed.Host
+		if parsed.Port() == "" {
+			port := "80"
+			if parsed.Scheme == "https" {
+				port = "443"
+			}
+			target = net.JoinHostPort(parsed.Hostname(), port)
+		}
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", target)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// getCheckTarget extracts the checkable part of an address. Addresses might have a label, like "admin: https://xa-admin.local"
+func getCheckTarget(address string) string {
+	if found := urlRe.FindString(address); found != "" {
+		return found
+	}
+
+	if _, target, ok := strings.Cut(address, ": "); ok {
+		return strings.TrimSpace(target)
+	}
+
+	return strings.TrimSpace(address)
+}
+
+// getHealthInfo formats the health state of a resource, empty if there is no health check
+func (c *poolCommands) getHealthInfo(resource config.Resource) string {
+	if !resource.HealthCheck.IsEnabled() {
+		return ""
+	}
+
+	state, ok := c.pool.GetHealth(resource.Name)
+	switch {
+	case !ok:
+		return "\n>_Health:_ not checked yet"
+	case state.Healthy:
+		return fmt.Sprintf("\n>_Health:_ healthy (checked at %s)", state.CheckedAt.Format(time.RFC1123))
+	default:
+		return fmt.Sprintf("\n>_Health:_ :warning: unhealthy: %s (checked at %s)", state.Error, state.CheckedAt.Format(time.RFC1123))
+	}
+}
+
+// getHealthMarker returns a short marker for unhealthy resources, used in lists
+func (c *poolCommands) getHealthMarker(resourceName string) string {
+	if state, ok := c.pool.GetHealth(resourceName); ok && !state.Healthy {
+		return " :warning: _unhealthy_"
+	}
+
+	return ""
+}
@@ -0,0 +1,156 @@
+package pool
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCheckTarget(t *testing.T) {
+	assert.Equal(t, "https://xa1.local", getCheckTarget("market: https://xa1.local"))
+	assert.Equal(t, "http://xa.local:8080/health", getCheckTarget("http://xa.local:8080/health"))
+	assert.Equal(t, "db.local:5432", getCheckTarget("db: db.local:5432"))
+	assert.Equal(t, "db.local:5432", getCheckTarget("db.local:5432"))
+}
+
+func TestHealthChecks(t *testing.T) {
+	storage.InitStorage("")
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+
+	lc := &net.ListenConfig{}
+	listener, err := lc.Listen(context.Background(), "tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	cfg := &config.Config{}
+	cfg.Pool = config.Pool{
+		LockDuration: time.Hour,
+		Resources: []*config.Resource{
+			{
+				Name:        "server1",
+				Addresses:   []string{"web: " + healthy.URL},
+				HealthCheck: config.HealthCheck{Type: "http"},
+			},
+			{
+				Name:        "server2",
+				Addresses:   []string{"web: " + broken.URL},
+				HealthCheck: config.HealthCheck{Type: "http", Timeout: time.Second},
+			},
+			{
+				Name:        "server3",
+				Addresses:   []string{"port: " + listener.Addr().String()},
+				HealthCheck: config.HealthCheck{Type: "tcp"},
+			},
+			{
+				Name: "server4",
+			},
+		},
+	}
+
+	slackClient := mocks.NewSlackClient(t)
+	p := getNewPool(&cfg.Pool)
+	commands := &poolCommands{slackClient: slackClient, config: &cfg.Pool, pool: p}
+
+	commands.checkHealth(context.Background())
+
+	state, ok := p.GetHealth("server1")
+	assert.True(t, ok)
+	assert.True(t, state.Healthy)
+
+	state, ok = p.GetHealth("server2")
+	assert.True(t, ok)
+	assert.False(t, state.Healthy)
+	assert.Contains(t, state.Error, "returned status 502")
+
+	state, ok = p.GetHealth("server3")
+	assert.True(t, ok)
+	assert.True(t, state.Healthy)
+
+	_, ok = p.GetHealth("server4")
+	assert.False(t, ok)
+
+	// unhealthy resources are not lockable
+	_, err = p.Lock("user1", "", "server2")
+	assert.Equal(t, ErrResourceUnhealthy, err)
+
+	message := msg.Message{}
+	message.Text = "pool list free"
+	mocks.AssertSlackMessage(slackClient, message, "*Available:*\n`server1`, `server2` :warning: _unhealthy_, `server3`, `server4`\n")
+	poolCommands := bot.Commands{}
+	poolCommands.AddCommand(commands)
+	assert.True(t, poolCommands.Run(message))
+
+	message.Text = "pool info free"
+	mocks.AssertSlackMessageRegexp(slackClient, message, "`server2`:\n.*\n.*\n>_Features:_\n>_Health:_ :warning: unhealthy: .* returned status 502")
+	assert.True(t, poolCommands.Run(message))
+}
+
+func TestHooks(t *testing.T) {
+	storage.InitStorage("")
+
+	lock := mocks.LockInternalMessages()
+	defer lock.Unlock()
+
+	client.AllUsers = config.UserMap{
+		"U1": "user1",
+	}
+
+	slackClient := mocks.NewSlackClient(t)
+	base := bot.BaseCommand{SlackClient: slackClient}
+
+	cfg := &config.Config{}
+	cfg.Pool = config.Pool{
+		LockDuration: time.Hour,
+		Resources: []*config.Resource{
+			{
+				Name:     "server1",
+				OnLock:   []string{"reply {{.user}} locked {{.resource}}: {{.reason}}"},
+				OnUnlock: []string{"trigger job ResetServer {{.resource}}"},
+			},
+		},
+	}
+	commands := GetCommands(cfg, base)
+
+	message := msg.Message{}
+	message.User = "U1"
+	message.Text = "pool lock server1 testing"
+	mocks.AssertSlackMessageRegexp(slackClient, message, "^`server1` is locked for you until")
+	assert.True(t, commands.Run(message))
+
+	hook := mocks.WaitTillHavingInternalMessage()
+	assert.Equal(t, "reply user1 locked server1: testing", hook.Text)
+	assert.Equal(t, "U1", hook.User)
+
+	message.Text = "pool unlock server1"
+	mocks.AssertSlackMessage(slackClient, message, "`server1` is free again")
+	assert.True(t, commands.Run(message))
+
+	hook = mocks.WaitTillHavingInternalMessage()
+	assert.Equal(t, "trigger job ResetServer server1", hook.Text)
+
+	// hooks of background actions are replied in the DM of the user
+	ref := getUserRef("user1")
+	assert.Equal(t, "U1", ref.Channel)
+	assert.Equal(t, "U1", ref.User)
+}
@@ -0,0 +1,227 @@
+package pool
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const historyStorageKey = "pool_history"
+
+// EventType is the kind of change of a resource lock, like "lock" or "unlock"
+type EventType string
+
+const (
+	EventLock        EventType = "lock"
+	EventUnlock      EventType = "unlock"
+	EventExtend      EventType = "extend"
+	EventExpire      EventType = "expire"
+	EventHandover    EventType = "handover"
+	EventForceUnlock EventType = "force-unlock"
+)
+
+// Event is a single recorded change of a resource lock.
+// For a handover the User is the new owner, for all other events it's the owner of the lock.
+type Event struct {
+	Resource  string
+	User      string
+	Type      EventType
+	Time      time.Time
+	LockUntil time.Time
+}
+
+// storage key of the event, the history is ordered by this time
+func (e *Event) key() string {
+	return fmt.Sprintf("%d-%s", e.Time.UnixNano(), e.Resource)
+}
+
+// loadHistory restores all recorded events, the caller has to hold p.mu
+func (p *pool) loadHistory() {
+	keys, _ := storage.GetKeys(historyStorageKey)
+	for _, key := range keys {
+		var event Event
+		if err := storage.Read(historyStorageKey, key, &event); err != nil {
+			log.Errorf("[Pool] unable to restore history event '%s': %s", key, err)
+			continue
+		}
+
+		p.events = append(p.events, event)
+	}
+
+	sort.SliceStable(p.events, func(i, j int) bool {
+		return p.events[i].Time.Before(p.events[j].Time)
+	})
+
+	p.pruneHistory(time.Now())
+}
+
+// recordEvent adds an event to the history, the caller has to hold p.mu
+func (p *pool) recordEvent(eventType EventType, lock *ResourceLock) {
+	event := Event{
+		Resource:  lock.Resource.Name,
+		User:      lock.User,
+		Type:      eventType,
+		Time:      time.Now(),
+		LockUntil: lock.LockUntil,
+	}
+	p.events = append(p.events, event)
+
+	if err := storage.Write(historyStorageKey, event.key(), event); err != nil {
+		log.Error(errors.Wrap(err, "error while storing pool history event"))
+	}
+
+	p.pruneHistory(event.Time)
+}
+
+// pruneHistory removes all events which are older than the retention, the caller has to hold p.mu
+func (p *pool) pruneHistory(now time.Time) {
+	if p.historyRetention <= 0 {
+		return
+	}
+
+	threshold := now.Add(-p.historyRetention)
+
+	outdated := 0
+	for outdated < len(p.events) && p.events[outdated].Time.Before(threshold) {
+		event := p.events[outdated]
+		if err := storage.Delete(historyStorageKey, event.key()); err != nil {
+			log.Error(errors.Wrap(err, "error while deleting pool history event"))
+		}
+		outdated++
+	}
+
+	p.events = p.events[outdated:]
+}
+
+// GetHistory returns a copy of all recorded events since the given time, optionally filtered by a resource
+func (p *pool) GetHistory(resourceName string, since time.Time) []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	events := make([]Event, 0)
+	for _, event := range p.events {
+		if event.Time.Before(since) || (resourceName != "" && event.Resource != resourceName) {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
+
+// lockSession is a time frame in which a resource was locked by one user
+type lockSession struct {
+	Resource string
+	User     string
+	From     time.Time
+	Until    time.Time
+	Expired  bool
+}
+
+func (s lockSession) duration() time.Duration {
+	return s.Until.Sub(s.From)
+}
+
+// getSessions reconstructs the lock time frames out of the (sorted) events, clipped to the time frame between since and now.
+// Locks which are still active are ending now.
+func getSessions(events []Event, since, now time.Time) []lockSession {
+	open := make(map[string]*lockSession)
+	sessions := make([]lockSession, 0)
+
+	closeSession := func(resource string, until time.Time, expired bool) {
+		session, ok := open[resource]
+		if !ok {
+			return
+		}
+		delete(open, resource)
+
+		session.Until = until
+		session.Expired = expired
+		if session.Until.Before(since) {
+			return
+		}
+		if session.From.Before(since) {
+			session.From = since
+		}
+		sessions = append(sessions, *session)
+	}
+
+	for _, event := range events {
+		switch event.Type {
+		case EventLock, EventHandover:
+			closeSession(event.Resource, event.Time, false)
+			open[event.Resource] = &lockSession{
+				Resource: event.Resource,
+				User:     event.User,
+				From:     event.Time,
+			}
+		case EventUnlock, EventForceUnlock:
+			closeSession(event.Resource, event.Time, false)
+		case EventExpire:
+			closeSession(event.Resource, event.Time, true)
+		case EventExtend:
+			// no change of the owner
+		}
+	}
+
+	for resource := range open {
+		closeSession(resource, now, false)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].From.Before(sessions[j].From)
+	})
+
+	return sessions
+}
+
+// usageReport aggregates the lock sessions of a time frame
+type usageReport struct {
+	Period        time.Duration
+	ResourceUsage map[string]time.Duration
+	UserUsage     map[string]time.Duration
+	UserLocks     map[string]int
+	Expired       map[string]int
+	Longest       []lockSession
+}
+
+// getUsageReport builds the utilization report of all resources in the given period
+func getUsageReport(events []Event, period time.Duration, now time.Time) usageReport {
+	report := usageReport{
+		Period:        period,
+		ResourceUsage: make(map[string]time.Duration),
+		UserUsage:     make(map[string]time.Duration),
+		UserLocks:     make(map[string]int),
+		Expired:       make(map[string]int),
+	}
+
+	sessions := getSessions(events, now.Add(-period), now)
+	for _, session := range sessions {
+		report.ResourceUsage[session.Resource] += session.duration()
+		report.UserUsage[session.User] += session.duration()
+		report.UserLocks[session.User]++
+		if session.Expired {
+			report.Expired[session.User]++
+		}
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].duration() > sessions[j].duration()
+	})
+	report.Longest = sessions[:min(len(sessions), 5)]
+
+	return report
+}
+
+// utilization returns the ratio (0-1) of the period in which the resource was locked
+func (r usageReport) utilization(resourceName string) float64 {
+	if r.Period <= 0 {
+		return 0
+	}
+
+	return float64(r.ResourceUsage[resourceName]) / float64(r.Period)
+}
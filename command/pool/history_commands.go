@@ -0,0 +1,117 @@
+package pool
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/util"
+)
+
+const (
+	historyLimit        = 25
+	defaultReportPeriod = "7d"
+)
+
+func (c *poolCommands) history(match matcher.Result, message msg.Message) {
+	resourceName := match.GetString("resource")
+
+	events := c.pool.GetHistory(resourceName, time.Time{})
+	if len(events) == 0 {
+		c.slackClient.SendMessage(message, fmt.Sprintf("there is no recorded history for `%s`", resourceName))
+		return
+	}
+
+	// only show the latest events, newest first
+	events = events[max(0, len(events)-historyLimit):]
+	slices.Reverse(events)
+
+	lines := make([]string, 0, len(events)+1)
+	lines = append(lines, fmt.Sprintf("*History of `%s`:*", resourceName))
+	for _, event := range events {
+		lines = append(lines, fmt.Sprintf("%s: %s", event.Time.Format(time.RFC1123), formatEvent(event)))
+	}
+
+	c.slackClient.SendMessage(message, strings.Join(lines, "\n"))
+}
+
+func (c *poolCommands) report(match matcher.Result, message msg.Message) {
+	periodString := match.GetString("period")
+	if periodString == "" {
+		periodString = defaultReportPeriod
+	}
+
+	period, err := util.ParseDuration(periodString)
+	if err != nil {
+		c.slackClient.ReplyError(message, err)
+		return
+	}
+
+	report := getUsageReport(c.pool.GetHistory("", time.Time{}), period, time.Now())
+
+	lines := []string{
+		fmt.Sprintf("*Pool report of the last %s:*", periodString),
+		"",
+		"*Utilization per resource:*",
+	}
+	for _, resource := range c.config.Resources {
+		lines = append(lines, fmt.Sprintf(
+			"`%s`: %.1f%% (%s)",
+			resource.Name,
+			report.utilization(resource.Name)*100,
+			util.FormatDuration(report.ResourceUsage[resource.Name].Round(time.Minute)),
+		))
+	}
+
+	lines = append(lines, "", "*Usage per user:*")
+	users := slices.Collect(maps.Keys(report.UserUsage))
+	slices.SortFunc(users, func(a, b string) int {
+		return cmp.Compare(report.UserUsage[b], report.UserUsage[a])
+	})
+	for _, user := range users {
+		lines = append(lines, fmt.Sprintf("%s: %s (%d locks)", user, util.FormatDuration(report.UserUsage[user].Round(time.Minute)), report.UserLocks[user]))
+	}
+
+	lines = append(lines, "", "*Longest locks:*")
+	for _, session := range report.Longest {
+		lines = append(lines, fmt.Sprintf(
+			"%s locked `%s` for %s (since %s)",
+			session.User,
+			session.Resource,
+			util.FormatDuration(session.duration().Round(time.Minute)),
+			session.From.Format(time.RFC1123),
+		))
+	}
+
+	lines = append(lines, "", "*Expired without unlock:*")
+	expiredUsers := slices.Sorted(maps.Keys(report.Expired))
+	for _, user := range expiredUsers {
+		lines = append(lines, fmt.Sprintf("%s: %d", user, report.Expired[user]))
+	}
+
+	c.slackClient.SendMessage(message, strings.Join(lines, "\n"))
+}
+
+func formatEvent(event Event) string {
+	switch event.Type {
+	case EventLock:
+		return fmt.Sprintf("locked by %s until %s", event.User, event.LockUntil.Format(time.RFC1123))
+	case EventExtend:
+		return fmt.Sprintf("extended by %s until %s", event.User, event.LockUntil.Format(time.RFC1123))
+	case EventUnlock:
+		return "unlocked by " + event.User
+	case EventExpire:
+		return fmt.Sprintf("lock of %s expired", event.User)
+	case EventHandover:
+		return "handed over to " + event.User
+	case EventForceUnlock:
+		return fmt.Sprintf("lock of %s got removed by an admin", event.User)
+	}
+
+	return string(event.Type)
+}
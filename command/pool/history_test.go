@@ -0,0 +1,107 @@
+package pool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageReport(t *testing.T) {
+	now := time.Date(2024, 5, 8, 12, 0, 0, 0, time.UTC)
+
+	events := []Event{
+		// started before the report period -> only the last 2 hours are counted
+		{Resource: "server1", User: "user1", Type: EventLock, Time: now.Add(-26 * time.Hour)},
+		{Resource: "server1", User: "user1", Type: EventExtend, Time: now.Add(-25 * time.Hour)},
+		{Resource: "server1", User: "user2", Type: EventHandover, Time: now.Add(-22 * time.Hour)},
+		{Resource: "server1", User: "user2", Type: EventExpire, Time: now.Add(-18 * time.Hour)},
+		{Resource: "server2", User: "user1", Type: EventLock, Time: now.Add(-6 * time.Hour)},
+		{Resource: "server2", User: "user1", Type: EventUnlock, Time: now.Add(-5 * time.Hour)},
+		// still locked
+		{Resource: "server2", User: "user2", Type: EventLock, Time: now.Add(-3 * time.Hour)},
+	}
+
+	report := getUsageReport(events, 24*time.Hour, now)
+
+	assert.Equal(t, 6*time.Hour, report.ResourceUsage["server1"])
+	assert.Equal(t, 4*time.Hour, report.ResourceUsage["server2"])
+	assert.InDelta(t, 0.25, report.utilization("server1"), 0.001)
+	assert.InDelta(t, 0.0, report.utilization("server3"), 0.001)
+
+	assert.Equal(t, 3*time.Hour, report.UserUsage["user1"])
+	assert.Equal(t, 7*time.Hour, report.UserUsage["user2"])
+	assert.Equal(t, 2, report.UserLocks["user1"])
+	assert.Equal(t, map[string]int{"user2": 1}, report.Expired)
+
+	require.Len(t, report.Longest, 4)
+	assert.Equal(t, "user2", report.Longest[0].User)
+	assert.Equal(t, "server1", report.Longest[0].Resource)
+	assert.Equal(t, 4*time.Hour, report.Longest[0].duration())
+}
+
+func TestPoolHistory(t *testing.T) {
+	storage.InitStorage("")
+
+	cfg := &config.Config{}
+	cfg.Pool = config.Pool{
+		LockDuration: time.Hour,
+		Resources: []*config.Resource{
+			{Name: "server1"},
+			{Name: "server2"},
+		},
+	}
+
+	p := getNewPool(&cfg.Pool)
+
+	_, err := p.Lock("user1", "", "server1")
+	require.NoError(t, err)
+	_, err = p.ExtendLock("user1", "server1", "1h")
+	require.NoError(t, err)
+	_, err = p.Handover("user1", "server1", "user2")
+	require.NoError(t, err)
+	require.NoError(t, p.Expire("user2", "server1"))
+	_, err = p.Lock("user3", "", "server2")
+	require.NoError(t, err)
+
+	events := p.GetHistory("server1", time.Time{})
+	require.Len(t, events, 4)
+	assert.Equal(t, EventLock, events[0].Type)
+	assert.Equal(t, EventExtend, events[1].Type)
+	assert.Equal(t, EventHandover, events[2].Type)
+	assert.Equal(t, "user2", events[2].User)
+	assert.Equal(t, EventExpire, events[3].Type)
+
+	// the history is restored after a restart
+	assert.Len(t, getNewPool(&cfg.Pool).GetHistory("", time.Time{}), 5)
+
+	t.Run("commands", func(t *testing.T) {
+		slackClient := mocks.NewSlackClient(t)
+		base := bot.BaseCommand{SlackClient: slackClient}
+		commands := GetCommands(cfg, base)
+
+		message := msg.Message{}
+		message.Text = "pool history server1"
+		mocks.AssertSlackMessageRegexp(slackClient, message, "^\\*History of `server1`:\\*\n.*: lock of user2 expired\n.*: handed over to user2\n.*: extended by user1 until .*\n.*: locked by user1 until .*$")
+		assert.True(t, commands.Run(message))
+
+		message.Text = "pool report 1d"
+		mocks.AssertSlackMessageRegexp(slackClient, message, "^\\*Pool report of the last 1d:\\*\n\n\\*Utilization per resource:\\*\n`server1`: 0.0% \\(0s\\)\n`server2`: 0.0% \\(0s\\)\n\n\\*Usage per user:\\*\n")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		collector := &metricsCollector{pool: p}
+
+		// 2 resources with locked+utilization, 3 users, one expired lock, waiting list + reservations
+		assert.Equal(t, 10, testutil.CollectAndCount(collector))
+		assert.Equal(t, 1, testutil.CollectAndCount(collector, "slack_bot_pool_user_expired_locks"))
+	})
+}
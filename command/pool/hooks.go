@@ -0,0 +1,40 @@
+package pool
+
+import (
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/innogames/slack-bot/v2/client"
+	log "github.com/sirupsen/logrus"
+)
+
+// runHooks executes the configured on_lock/on_unlock commands of a resource, e.g. to reset a test server after an unlock.
+// Available template variables: {{.resource}}, {{.user}} and {{.reason}}
+func runHooks(commands []string, ref msg.Ref, lock ResourceLock) {
+	params := util.Parameters{
+		"resource": lock.Resource.Name,
+		"user":     lock.User,
+		"reason":   lock.Reason,
+	}
+
+	for _, command := range commands {
+		temp, err := util.CompileTemplate(command)
+		if err != nil {
+			log.Warn(err)
+			continue
+		}
+		text, _ := util.EvalTemplate(temp, params)
+		client.HandleMessage(ref.WithText(text))
+	}
+}
+
+// getUserRef builds a message reference for hooks which are not triggered by a message, like expired locks.
+// The user's direct message channel is used to reply.
+func getUserRef(userName string) msg.Message {
+	userID, _ := client.GetUserIDAndName(userName)
+
+	ref := msg.Message{}
+	ref.User = userID
+	ref.Channel = userID
+
+	return ref
+}
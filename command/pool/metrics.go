@@ -0,0 +1,84 @@
+package pool
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsPeriod is the time frame of the exported utilization metrics
+const metricsPeriod = 24 * time.Hour
+
+var (
+	lockedDesc = prometheus.NewDesc(
+		"slack_bot_pool_resource_locked",
+		"1 if the resource is currently locked, 0 otherwise",
+		[]string{"resource"}, nil,
+	)
+	utilizationDesc = prometheus.NewDesc(
+		"slack_bot_pool_resource_utilization_ratio",
+		"ratio of the last 24h in which the resource was locked",
+		[]string{"resource"}, nil,
+	)
+	userLockedDesc = prometheus.NewDesc(
+		"slack_bot_pool_user_locked_seconds",
+		"seconds in the last 24h in which the user had a locked resource",
+		[]string{"user"}, nil,
+	)
+	expiredDesc = prometheus.NewDesc(
+		"slack_bot_pool_user_expired_locks",
+		"number of locks in the last 24h which expired without an unlock",
+		[]string{"user"}, nil,
+	)
+	waitingDesc = prometheus.NewDesc(
+		"slack_bot_pool_waiting_users",
+		"number of entries in the waiting list",
+		nil, nil,
+	)
+	reservationsDesc = prometheus.NewDesc(
+		"slack_bot_pool_reservations",
+		"number of upcoming reservations",
+		nil, nil,
+	)
+)
+
+// metricsCollector exports the current state and the utilization of the pool as prometheus gauges
+type metricsCollector struct {
+	pool *pool
+}
+
+// Describe returns all descriptions of the collector.
+func (c *metricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- lockedDesc
+	ch <- utilizationDesc
+	ch <- userLockedDesc
+	ch <- expiredDesc
+	ch <- waitingDesc
+	ch <- reservationsDesc
+}
+
+// Collect returns the current state of all pool metrics
+func (c *metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	report := getUsageReport(c.pool.GetHistory("", time.Time{}), metricsPeriod, time.Now())
+
+	for _, lock := range c.pool.GetLocks("") {
+		ch <- prometheus.MustNewConstMetric(lockedDesc, prometheus.GaugeValue, 1, lock.Resource.Name)
+		ch <- prometheus.MustNewConstMetric(utilizationDesc, prometheus.GaugeValue, report.utilization(lock.Resource.Name), lock.Resource.Name)
+	}
+
+	for _, resource := range c.pool.GetFree() {
+		ch <- prometheus.MustNewConstMetric(lockedDesc, prometheus.GaugeValue, 0, resource.Name)
+		ch <- prometheus.MustNewConstMetric(utilizationDesc, prometheus.GaugeValue, report.utilization(resource.Name), resource.Name)
+	}
+
+	for user, duration := range report.UserUsage {
+		ch <- prometheus.MustNewConstMetric(userLockedDesc, prometheus.GaugeValue, duration.Seconds(), user)
+	}
+
+	for user, count := range report.Expired {
+		ch <- prometheus.MustNewConstMetric(expiredDesc, prometheus.GaugeValue, float64(count), user)
+	}
+
+	ch <- prometheus.MustNewConstMetric(waitingDesc, prometheus.GaugeValue, float64(len(c.pool.GetWaitingList())))
+	ch <- prometheus.MustNewConstMetric(reservationsDesc, prometheus.GaugeValue, float64(len(c.pool.GetReservations(""))))
+}
@@ -1,7 +1,9 @@
 package pool
 
 import (
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,6 +22,8 @@ var (
 	ErrResourceLockedByDifferentUser = errors.New("resources locked by different user")
 	ErrNoLockedResourceFound         = errors.New("no locked resource found")
 	ErrNoResourceAvailable           = errors.New("no resource available")
+	ErrResourceNotFound              = errors.New("resource not found")
+	ErrResourceReserved              = errors.New("resource is reserved by a different user")
 )
 
 // ResourceLock struct to hold and store the current locks
@@ -32,9 +36,14 @@ type ResourceLock struct {
 }
 
 type pool struct {
-	locks        map[*config.Resource]*ResourceLock
-	lockDuration time.Duration
-	mu           sync.Mutex
+	locks            map[*config.Resource]*ResourceLock
+	reservations     []*Reservation
+	waitingList      []*WaitingEntry
+	events           []Event
+	health           map[string]healthState
+	lockDuration     time.Duration
+	historyRetention time.Duration
+	mu               sync.Mutex
 }
 
 // getNewPool create a new pool and initialize it by the local storage
@@ -42,6 +51,7 @@ func getNewPool(cfg *config.Pool) *pool {
 	var p pool
 
 	p.lockDuration = cfg.LockDuration
+	p.historyRetention = cfg.GetHistoryRetention()
 
 	p.locks = make(map[*config.Resource]*ResourceLock)
 	for _, resource := range cfg.Resources {
@@ -64,16 +74,39 @@ func getNewPool(cfg *config.Pool) *pool {
 			}
 		}
 	}
+
+	p.loadReservations()
+	p.loadWaitingList()
+	p.loadHistory()
+
 	return &p
 }
 
 // Lock a resource in the pool for a user
 func (p *pool) Lock(user, reason, resourceName string) (*ResourceLock, error) {
-	specificResource := len(resourceName) > 0
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
+	return p.tryLock(user, reason, resourceName, nil)
+}
+
+// LockWithFeatures locks any free resource for a user which provides all the given features
+func (p *pool) LockWithFeatures(user, reason string, features []string) (*ResourceLock, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	return p.tryLock(user, reason, "", features)
+}
+
+// tryLock locks a free resource, the caller has to hold p.mu
+func (p *pool) tryLock(user, reason, resourceName string, features []string) (*ResourceLock, error) {
+	specificResource := len(resourceName) > 0
+
+	now := time.Now()
+	lockUntil := now.Add(p.lockDuration)
+	reserved := false
+	unhealthy := false
+
 	for k, v := range p.locks {
 		if v != nil {
 			// it's already in used
@@ -90,11 +123,28 @@ func (p *pool) Lock(user, reason, resourceName string) (*ResourceLock, error) {
 			continue
 		}
 
+		if !hasFeatures(k, features) {
+			// resource is missing at least one of the requested features
+			continue
+		}
+
+		if !p.isHealthy(k.Name) {
+			// failed health check -> not usable right now
+			unhealthy = true
+			continue
+		}
+
+		if p.isReservedByOther(k.Name, user, now, lockUntil) {
+			// the lock would overlap with an upcoming reservation
+			reserved = true
+			continue
+		}
+
 		resourceLock := &ResourceLock{
 			Resource:  *k,
 			User:      user,
 			Reason:    reason,
-			LockUntil: time.Now().Add(p.lockDuration),
+			LockUntil: lockUntil,
 		}
 
 		p.locks[k] = resourceLock
@@ -102,9 +152,19 @@ func (p *pool) Lock(user, reason, resourceName string) (*ResourceLock, error) {
 		if err := storage.Write(storageKey, k.Name, resourceLock); err != nil {
 			log.Error(errors.Wrap(err, "error while storing pool lock entry"))
 		}
+		p.recordEvent(EventLock, resourceLock)
+
 		return resourceLock, nil
 	}
 
+	if specificResource && reserved {
+		return nil, ErrResourceReserved
+	}
+
+	if specificResource && unhealthy {
+		return nil, ErrResourceUnhealthy
+	}
+
 	return nil, ErrNoResourceAvailable
 }
 
@@ -131,12 +191,17 @@ func (p *pool) ExtendLock(user, resourceName, duration string) (*ResourceLock, e
 			return nil, err
 		}
 
+		if p.isReservedByOther(k.Name, user, v.LockUntil, v.LockUntil.Add(d)) {
+			return nil, ErrResourceReserved
+		}
+
 		v.LockUntil = v.LockUntil.Add(d)
 		v.WarningSend = false
 
 		if err := storage.Write(storageKey, k.Name, v); err != nil {
 			log.Error(errors.Wrap(err, "error while storing pool lock entry"))
 		}
+		p.recordEvent(EventExtend, v)
 
 		return v, nil
 	}
@@ -146,6 +211,15 @@ func (p *pool) ExtendLock(user, resourceName, duration string) (*ResourceLock, e
 
 // Unlock a resource of a user
 func (p *pool) Unlock(user, resourceName string) error {
+	return p.unlock(user, resourceName, EventUnlock)
+}
+
+// Expire removes an expired lock of a user
+func (p *pool) Expire(user, resourceName string) error {
+	return p.unlock(user, resourceName, EventExpire)
+}
+
+func (p *pool) unlock(user, resourceName string, eventType EventType) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -167,6 +241,7 @@ func (p *pool) Unlock(user, resourceName string) error {
 		if err := storage.Delete(storageKey, k.Name); err != nil {
 			log.Error(errors.Wrap(err, "error while storing pool lock entry"))
 		}
+		p.recordEvent(eventType, v)
 
 		return nil
 	}
@@ -174,6 +249,61 @@ func (p *pool) Unlock(user, resourceName string) error {
 	return ErrNoLockedResourceFound
 }
 
+// ForceUnlock removes the lock of a resource, regardless of the owner. Returns the removed lock
+func (p *pool) ForceUnlock(resourceName string) (*ResourceLock, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for k, v := range p.locks {
+		if v == nil || k.Name != resourceName {
+			continue
+		}
+
+		p.locks[k] = nil
+
+		if err := storage.Delete(storageKey, k.Name); err != nil {
+			log.Error(errors.Wrap(err, "error while storing pool lock entry"))
+		}
+		p.recordEvent(EventForceUnlock, v)
+
+		return v, nil
+	}
+
+	return nil, ErrNoLockedResourceFound
+}
+
+// Handover transfers the lock of a resource to a different user, without unlocking it in the meantime
+func (p *pool) Handover(user, resourceName, newUser string) (*ResourceLock, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for k, v := range p.locks {
+		if v == nil || k.Name != resourceName {
+			continue
+		}
+
+		if v.User != user {
+			return nil, ErrResourceLockedByDifferentUser
+		}
+
+		if p.isReservedByOther(k.Name, newUser, time.Now(), v.LockUntil) {
+			return nil, ErrResourceReserved
+		}
+
+		v.User = newUser
+		v.WarningSend = false
+
+		if err := storage.Write(storageKey, k.Name, v); err != nil {
+			log.Error(errors.Wrap(err, "error while storing pool lock entry"))
+		}
+		p.recordEvent(EventHandover, v)
+
+		return v, nil
+	}
+
+	return nil, ErrNoLockedResourceFound
+}
+
 // GetLocks returns a sorted list of value-copies of all active locks of a user / all users if userName = "".
 // Callers receive snapshots — mutations on the returned structs do not affect pool state.
 func (p *pool) GetLocks(userName string) []ResourceLock {
@@ -223,3 +353,27 @@ func (p *pool) GetFree() []*config.Resource {
 	})
 	return free
 }
+
+// getResource returns the configured resource with the given name, the caller has to hold p.mu
+func (p *pool) getResource(resourceName string) *config.Resource {
+	for k := range p.locks {
+		if k.Name == resourceName {
+			return k
+		}
+	}
+
+	return nil
+}
+
+// hasFeatures checks if the resource provides all given features (case insensitive)
+func hasFeatures(resource *config.Resource, features []string) bool {
+	for _, feature := range features {
+		if !slices.ContainsFunc(resource.Features, func(given string) bool {
+			return strings.EqualFold(given, feature)
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
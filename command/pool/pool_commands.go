@@ -2,6 +2,9 @@ package pool
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,14 +17,20 @@ import (
 	"github.com/slack-go/slack"
 )
 
+var (
+	featureSplitRe     = regexp.MustCompile(`\s*(?:,| and )\s*`)
+	featureSeparatorRe = regexp.MustCompile(`^(?:,\s*|and )`)
+)
+
 // newPoolCommands display usage of the pool
-func newPoolCommands(slackClient client.SlackClient, cfg *config.Pool, p *pool) bot.Command {
-	return &poolCommands{slackClient, cfg, p}
+func newPoolCommands(slackClient client.SlackClient, cfg *config.Config, p *pool) bot.Command {
+	return &poolCommands{slackClient, &cfg.Pool, cfg.AdminUsers, p}
 }
 
 type poolCommands struct {
 	slackClient client.SlackClient
 	config      *config.Pool
+	adminUsers  config.UserList
 	pool        *pool
 }
 
@@ -36,29 +45,97 @@ func (c *poolCommands) GetMatcher() matcher.Matcher {
 	for _, res := range c.config.Resources {
 		resources = append(resources, res.Name)
 	}
+	resourcesRe := strings.Join(resources, "|")
+
 	return matcher.NewGroupMatcher(
+		matcher.NewRegexpMatcher("pool lock with features? (?P<features>.+)", c.lockResourceWithFeatures),
 		matcher.NewRegexpMatcher(fmt.Sprintf("pool lock\\b( )?(?P<resource>(%s\\b))?(( )?(?P<reason>.+))?", strings.Join(resources, "\\b|")), c.lockResource),
 		matcher.NewRegexpMatcher(fmt.Sprintf("pool unlock( )?(?P<resource>(%s))?", strings.Join(resources, "|")), c.unlockResource),
 		matcher.NewRegexpMatcher("pool locks", c.listUserResources),
 		matcher.NewRegexpMatcher("pool list( )?(?P<status>(free|used|locked))?", c.listResources),
 		matcher.NewRegexpMatcher("pool info( )?(?P<status>(free|used|locked))?", c.listPoolInfo),
-		matcher.NewRegexpMatcher(fmt.Sprintf("pool extend (?P<resource>(%s)) (?P<duration>([0-9]+[hmsd]))", strings.Join(resources, "|")), c.extend),
+		matcher.NewRegexpMatcher(fmt.Sprintf("pool extend (?P<resource>(%s)) (?P<duration>([0-9]+[hmsd]))", resourcesRe), c.extend),
+		matcher.NewRegexpMatcher(fmt.Sprintf("pool reserve (?P<resource>(%s)) (?P<from>.+?) for (?P<duration>[0-9]+[a-z]+)( (?P<reason>.+))?", resourcesRe), c.reserve),
+		matcher.NewRegexpMatcher(fmt.Sprintf("pool cancel reservation (?P<resource>(%s))", resourcesRe), c.cancelReservation),
+		matcher.NewTextMatcher("pool reservations", c.listReservations),
+		matcher.NewRegexpMatcher(fmt.Sprintf("pool wait\\b( )?(?P<resource>(%s\\b))?(( )?(?P<reason>.+))?", strings.Join(resources, "\\b|")), c.wait),
+		matcher.NewTextMatcher("pool stop waiting", c.stopWaiting),
+		matcher.NewRegexpMatcher(fmt.Sprintf("pool history (?P<resource>(%s))", resourcesRe), c.history),
+		matcher.NewRegexpMatcher("pool report( (?P<period>[0-9]+[a-z]+))?", c.report),
+		matcher.NewRegexpMatcher(fmt.Sprintf("pool handover (?P<resource>(%s)) to <?@(?P<user>[\\w\\-.]+)>?", resourcesRe), c.handover),
+		matcher.NewAdminMatcher(
+			c.adminUsers,
+			c.slackClient,
+			matcher.NewRegexpMatcher(fmt.Sprintf("pool force-unlock (?P<resource>(%s))", resourcesRe), c.forceUnlock),
+		),
 	)
 }
 
+// getFeatures returns all features of the resources, the longest first to prefer e.g. "web beauty" over "web"
+func (c *poolCommands) getFeatures() []string {
+	var features []string
+	for _, res := range c.config.Resources {
+		for _, feature := range res.Features {
+			if !slices.ContainsFunc(features, func(f string) bool { return strings.EqualFold(f, feature) }) {
+				features = append(features, feature)
+			}
+		}
+	}
+
+	sort.SliceStable(features, func(i, j int) bool {
+		return len(features[i]) > len(features[j])
+	})
+
+	return features
+}
+
+// parseFeatures splits e.g. "usb plugs and web testing" into the known features and the reason
+func (c *poolCommands) parseFeatures(text string) ([]string, string, error) {
+	knownFeatures := c.getFeatures()
+
+	var features []string
+	rest := strings.TrimSpace(text)
+	for {
+		idx := slices.IndexFunc(knownFeatures, func(feature string) bool {
+			if len(rest) < len(feature) || !strings.EqualFold(rest[:len(feature)], feature) {
+				return false
+			}
+			// the feature needs to be followed by a separator or the reason
+			return len(rest) == len(feature) || strings.ContainsAny(rest[len(feature):len(feature)+1], " ,")
+		})
+		if idx == -1 {
+			unknown := featureSplitRe.Split(rest, 2)[0]
+			return nil, "", fmt.Errorf("unknown feature '%s', available features: %s", unknown, strings.Join(knownFeatures, ", "))
+		}
+
+		features = append(features, knownFeatures[idx])
+		rest = strings.TrimLeft(rest[len(knownFeatures[idx]):], " ")
+
+		separator := featureSeparatorRe.FindString(rest)
+		if separator == "" {
+			// everything after the last feature is the reason
+			return features, rest, nil
+		}
+		rest = rest[len(separator):]
+	}
+}
+
 // RunAsync function to observe, notify and unlock expired locks
 func (c *poolCommands) RunAsync(ctx *util.ServerContext) {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
 
 	for {
+		c.checkHealth(ctx)
+
 		now := time.Now()
 		nowIn := now.Add(c.config.NotifyExpire)
 		allLocks := c.pool.GetLocks("")
 		for _, lock := range allLocks {
 			if now.After(lock.LockUntil) && lock.WarningSend {
-				_ = c.pool.Unlock(lock.User, lock.Resource.Name)
+				_ = c.pool.Expire(lock.User, lock.Resource.Name)
 				c.slackClient.SendToUser(lock.User, fmt.Sprintf("your lock for `%s` expired and got removed", lock.Resource.Name))
+				runHooks(lock.Resource.OnUnlock, getUserRef(lock.User), lock)
 				continue
 			}
 
@@ -81,6 +158,8 @@ func (c *poolCommands) RunAsync(ctx *util.ServerContext) {
 			}
 		}
 
+		c.processQueue()
+
 		select {
 		case <-ticker.C:
 			// wait for next tick
@@ -98,10 +177,44 @@ func (c *poolCommands) lockResource(match matcher.Result, message msg.Message) {
 	reason := match.GetString("reason")
 
 	resource, err := c.pool.Lock(userName, reason, resourceName)
+	if isUnavailableError(err) {
+		// offer to join the waiting list instead of retrying again and again
+		c.slackClient.SendBlockMessage(message, []slack.Block{
+			client.GetTextBlock(err.Error() + ". Do you want to wait for it? I'll lock it for you as soon as it's free."),
+			slack.NewActionBlock(
+				"pool_wait",
+				client.GetInteractionButton("action_wait", "Wait for it", strings.TrimSpace(fmt.Sprintf("pool wait %s %s", resourceName, reason))),
+			),
+		})
+		return
+	} else if err != nil {
+		c.slackClient.ReplyError(message, err)
+		return
+	}
+
+	c.sendLocked(message, resource)
+}
+
+func (c *poolCommands) lockResourceWithFeatures(match matcher.Result, message msg.Message) {
+	_, userName := client.GetUserIDAndName(message.GetUser())
+
+	features, reason, err := c.parseFeatures(match.GetString("features"))
 	if err != nil {
 		c.slackClient.ReplyError(message, err)
 		return
 	}
+
+	resource, err := c.pool.LockWithFeatures(userName, reason, features)
+	if err != nil {
+		c.slackClient.ReplyError(message, err)
+		return
+	}
+
+	c.sendLocked(message, resource)
+}
+
+// sendLocked informs the user about the new lock and executes the on_lock hooks of the resource
+func (c *poolCommands) sendLocked(message msg.Message, resource *ResourceLock) {
 	c.slackClient.SendMessage(
 		message,
 		fmt.Sprintf("`%s` is locked for you until %s!\n%s%s",
@@ -111,6 +224,8 @@ func (c *poolCommands) lockResource(match matcher.Result, message msg.Message) {
 			getAddressesAndFeatures(resource.Resource),
 		),
 	)
+
+	runHooks(resource.Resource.OnLock, message, *resource)
 }
 
 func (c *poolCommands) unlockResource(match matcher.Result, message msg.Message) {
@@ -156,6 +271,24 @@ func (c *poolCommands) unlockResource(match matcher.Result, message msg.Message)
 		return
 	}
 	c.slackClient.SendMessage(message, fmt.Sprintf("`%s` is free again", resourceName))
+
+	if resource := c.getResourceConfig(resourceName); resource != nil {
+		runHooks(resource.OnUnlock, message, ResourceLock{Resource: *resource, User: userName})
+	}
+
+	// maybe someone is already waiting for it
+	c.processQueue()
+}
+
+// getResourceConfig returns the configured resource with the given name
+func (c *poolCommands) getResourceConfig(resourceName string) *config.Resource {
+	for _, resource := range c.config.Resources {
+		if resource.Name == resourceName {
+			return resource
+		}
+	}
+
+	return nil
 }
 
 func (c *poolCommands) extend(match matcher.Result, message msg.Message) {
@@ -182,7 +315,7 @@ func (c *poolCommands) listResources(match matcher.Result, message msg.Message)
 		free := c.pool.GetFree()
 		resources := make([]string, 0, len(free))
 		for _, f := range free {
-			resources = append(resources, fmt.Sprintf("`%s`", f.Name))
+			resources = append(resources, fmt.Sprintf("`%s`%s", f.Name, c.getHealthMarker(f.Name)))
 		}
 		messages = append(messages, strings.Join(resources, ", "))
 	}
@@ -191,7 +324,7 @@ func (c *poolCommands) listResources(match matcher.Result, message msg.Message)
 		locked := c.pool.GetLocks("")
 		messages = append(messages, "*Used/Locked:*")
 		for _, l := range locked {
-			messages = append(messages, fmt.Sprintf("`%s` locked by %s until %s\n%s", l.Resource.Name, l.User, l.LockUntil.Format(time.RFC1123), getFormattedReason(l.Reason)))
+			messages = append(messages, fmt.Sprintf("`%s`%s locked by %s until %s\n%s", l.Resource.Name, c.getHealthMarker(l.Resource.Name), l.User, l.LockUntil.Format(time.RFC1123), getFormattedReason(l.Reason)))
 		}
 	}
 
@@ -222,7 +355,7 @@ func (c *poolCommands) listPoolInfo(match matcher.Result, message msg.Message) {
 		messages = append(messages, "*Available:*")
 		free := c.pool.GetFree()
 		for _, f := range free {
-			messages = append(messages, fmt.Sprintf("`%s`:\n%s\n", f.Name, getAddressesAndFeatures(*f)))
+			messages = append(messages, fmt.Sprintf("`%s`:\n%s%s\n", f.Name, getAddressesAndFeatures(*f), c.getHealthInfo(*f)))
 		}
 	}
 	messages = append(messages, "")
@@ -233,12 +366,13 @@ func (c *poolCommands) listPoolInfo(match matcher.Result, message msg.Message) {
 			messages = append(
 				messages,
 				fmt.Sprintf(
-					"`%s`:\n locked by %s until %s\n%s%s",
+					"`%s`:\n locked by %s until %s\n%s%s%s",
 					l.Resource.Name,
 					l.User,
 					l.LockUntil.Format(time.RFC1123),
 					getFormattedReason(l.Reason),
 					getAddressesAndFeatures(l.Resource),
+					c.getHealthInfo(l.Resource),
 				),
 			)
 		}
@@ -298,6 +432,8 @@ func (c *poolCommands) GetHelp() []bot.Help {
 				"pool lock with reason _lock an available resource with a reason_",
 				"pool lock xa _lock a specific resource_",
 				"pool lock xa with reason _lock a specific resource with a reason_",
+				"pool lock with feature usb plugs _lock an available resource which provides the feature_",
+				"pool lock with features usb plugs, web beauty testing _lock an available resource which provides all features, with a reason_",
 			},
 		},
 		{
@@ -325,5 +461,67 @@ func (c *poolCommands) GetHelp() []bot.Help {
 				"pool extend xa 30m _extend lock of resource xa by 30mins_",
 			},
 		},
+		{
+			Command:     "pool reserve <resource> <time> for <duration> <reason>",
+			Description: "reserve a resource in the future. Other users are not able to lock it in this time frame, at the start time it gets locked for you automatically",
+			Category:    category,
+			Examples: []string{
+				"pool reserve xa tomorrow 10:00 for 2h _reserve xa tomorrow from 10:00 till 12:00_",
+				"pool reserve xa friday 14:30 for 1d load test _reserve xa for one day with a reason_",
+				"pool reserve xa 2024-05-01 10:00 for 30m",
+			},
+		},
+		{
+			Command:     "pool reservations",
+			Description: "list all upcoming reservations and the waiting list",
+			Category:    category,
+			Examples: []string{
+				"pool reservations",
+				"pool cancel reservation xa _cancel your next reservation of xa_",
+			},
+		},
+		{
+			Command:     "pool wait <resource> <reason>",
+			Description: "join the waiting list for a resource: as soon as it's free it gets locked for you and you'll get notified",
+			Category:    category,
+			Examples: []string{
+				"pool wait _wait for any resource_",
+				"pool wait xa _wait for resource xa_",
+				"pool stop waiting _leave the waiting list_",
+			},
+		},
+		{
+			Command:     "pool history <resource>",
+			Description: "show the latest lock/unlock/extend/expire events of a resource",
+			Category:    category,
+			Examples: []string{
+				"pool history xa",
+			},
+		},
+		{
+			Command:     "pool report <period>",
+			Description: "show the utilization per resource and per user, the longest locks and the number of expired locks (default: last 7 days)",
+			Category:    category,
+			Examples: []string{
+				"pool report",
+				"pool report 30d",
+			},
+		},
+		{
+			Command:     "pool handover <resource> to <user>",
+			Description: "hand over your lock of a resource to a different user, without unlocking it in between",
+			Category:    category,
+			Examples: []string{
+				"pool handover xa to @jon.doe",
+			},
+		},
+		{
+			Command:     "pool force-unlock <resource>",
+			Description: "unlock a resource of a different user, the previous owner gets notified (admin only)",
+			Category:    category,
+			Examples: []string{
+				"pool force-unlock xa",
+			},
+		},
 	}
 }
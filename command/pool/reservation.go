@@ -0,0 +1,258 @@
+package pool
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const reservationStorageKey = "pool_reservations"
+
+var (
+	ErrInvalidReservation   = errors.New("reservations need to start in the future and need a positive duration")
+	ErrNoReservationFound   = errors.New("no reservation found")
+	ErrInvalidReservationAt = errors.New("invalid time, use e.g. '10:00', 'tomorrow 10:00', 'friday 14:30' or '2024-05-01 10:00'")
+)
+
+// Reservation blocks a resource for a user in a given time frame in the future
+type Reservation struct {
+	Resource string
+	User     string
+	Reason   string
+	From     time.Time
+	Until    time.Time
+}
+
+// storage key of the reservation, unique per resource and start time
+func (r *Reservation) key() string {
+	return fmt.Sprintf("%s-%d", r.Resource, r.From.Unix())
+}
+
+// overlaps checks if the given time frame collides with the reservation
+func (r *Reservation) overlaps(from, until time.Time) bool {
+	return from.Before(r.Until) && until.After(r.From)
+}
+
+// loadReservations restores all stored reservations, the caller has to hold p.mu
+func (p *pool) loadReservations() {
+	keys, _ := storage.GetKeys(reservationStorageKey)
+	for _, key := range keys {
+		var reservation Reservation
+		if err := storage.Read(reservationStorageKey, key, &reservation); err != nil {
+			log.Errorf("[Pool] unable to restore reservation '%s': %s", key, err)
+			continue
+		}
+
+		if p.getResource(reservation.Resource) == nil {
+			// resource got removed from the config in the meantime
+			continue
+		}
+
+		p.reservations = append(p.reservations, &reservation)
+	}
+
+	p.sortReservations()
+}
+
+// Reserve blocks a resource for the user in the given time frame. Overlapping locks of other users are rejected
+func (p *pool) Reserve(user, reason, resourceName string, from, until time.Time) (*Reservation, error) {
+	if !until.After(from) || from.Before(time.Now()) {
+		return nil, ErrInvalidReservation
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	resource := p.getResource(resourceName)
+	if resource == nil {
+		return nil, ErrResourceNotFound
+	}
+
+	if p.isReservedByOther(resourceName, "", from, until) {
+		return nil, ErrResourceReserved
+	}
+
+	if lock := p.locks[resource]; lock != nil && lock.User != user && lock.LockUntil.After(from) {
+		return nil, ErrResourceLockedByDifferentUser
+	}
+
+	reservation := &Reservation{
+		Resource: resourceName,
+		User:     user,
+		Reason:   reason,
+		From:     from,
+		Until:    until,
+	}
+	p.reservations = append(p.reservations, reservation)
+	p.sortReservations()
+
+	if err := storage.Write(reservationStorageKey, reservation.key(), reservation); err != nil {
+		log.Error(errors.Wrap(err, "error while storing pool reservation"))
+	}
+
+	return reservation, nil
+}
+
+// CancelReservation removes the next upcoming reservation of the user for the given resource
+func (p *pool) CancelReservation(user, resourceName string) (*Reservation, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, reservation := range p.reservations {
+		if reservation.Resource != resourceName || reservation.User != user {
+			continue
+		}
+
+		p.removeReservation(i)
+
+		return reservation, nil
+	}
+
+	return nil, ErrNoReservationFound
+}
+
+// GetReservations returns a list of value-copies of all upcoming reservations of a user / all users if userName = "",
+// sorted by start time
+func (p *pool) GetReservations(userName string) []Reservation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	reservations := make([]Reservation, 0, len(p.reservations))
+	for _, reservation := range p.reservations {
+		if userName == "" || reservation.User == userName {
+			reservations = append(reservations, *reservation)
+		}
+	}
+
+	return reservations
+}
+
+// activateReservations locks all resources with a started reservation for the reserving user.
+// Returns the new locks and the reservations which collided with a lock of another user, so that the users can be notified.
+func (p *pool) activateReservations(now time.Time) ([]ResourceLock, []Reservation) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var activated []ResourceLock
+	var rejected []Reservation
+	for i := 0; i < len(p.reservations); {
+		reservation := p.reservations[i]
+		if reservation.From.After(now) {
+			// reservations are sorted by start time -> nothing more to do
+			break
+		}
+
+		p.removeReservation(i)
+
+		if !reservation.Until.After(now) {
+			// the reservation is already over, e.g. when the bot was not running
+			continue
+		}
+
+		resource := p.getResource(reservation.Resource)
+		current := p.locks[resource]
+		if current != nil && current.User != reservation.User {
+			log.Warnf("[Pool] reservation of %s for %s collides with lock of %s", reservation.User, reservation.Resource, current.User)
+			rejected = append(rejected, *reservation)
+			continue
+		}
+
+		lockUntil := reservation.Until
+		if current != nil && current.LockUntil.After(lockUntil) {
+			lockUntil = current.LockUntil
+		}
+
+		resourceLock := &ResourceLock{
+			Resource:  *resource,
+			User:      reservation.User,
+			Reason:    reservation.Reason,
+			LockUntil: lockUntil,
+		}
+		p.locks[resource] = resourceLock
+
+		if err := storage.Write(storageKey, resource.Name, resourceLock); err != nil {
+			log.Error(errors.Wrap(err, "error while storing pool lock entry"))
+		}
+		p.recordEvent(EventLock, resourceLock)
+
+		activated = append(activated, *resourceLock)
+	}
+
+	return activated, rejected
+}
+
+// isReservedByOther checks if there is a reservation of a different user in the given time frame.
+// An empty user checks for reservations of all users. The caller has to hold p.mu
+func (p *pool) isReservedByOther(resourceName, user string, from, until time.Time) bool {
+	for _, reservation := range p.reservations {
+		if reservation.Resource == resourceName && reservation.User != user && reservation.overlaps(from, until) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeReservation removes the reservation with the given index, the caller has to hold p.mu
+func (p *pool) removeReservation(idx int) {
+	reservation := p.reservations[idx]
+	p.reservations = append(p.reservations[:idx], p.reservations[idx+1:]...)
+
+	if err := storage.Delete(reservationStorageKey, reservation.key()); err != nil {
+		log.Error(errors.Wrap(err, "error while deleting pool reservation"))
+	}
+}
+
+func (p *pool) sortReservations() {
+	sort.SliceStable(p.reservations, func(i, j int) bool {
+		return p.reservations[i].From.Before(p.reservations[j].From)
+	})
+}
+
+var reservationTimeRe = regexp.MustCompile(`^(?:(today|tomorrow|monday|tuesday|wednesday|thursday|friday|saturday|sunday|\d{4}-\d{2}-\d{2}) )?(\d{1,2}):(\d{2})$`)
+
+// parseReservationTime parses a point in time like "10:00", "tomorrow 10:00", "friday 14:30" or "2024-05-01 10:00".
+// A time without a day which already passed today refers to tomorrow.
+func parseReservationTime(input string, now time.Time) (time.Time, error) {
+	match := reservationTimeRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(input)))
+	if match == nil {
+		return time.Time{}, ErrInvalidReservationAt
+	}
+
+	hour, _ := strconv.Atoi(match[2])
+	minute, _ := strconv.Atoi(match[3])
+	if hour > 23 || minute > 59 {
+		return time.Time{}, ErrInvalidReservationAt
+	}
+
+	day := match[1]
+	date := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+
+	switch day {
+	case "":
+		if !date.After(now) {
+			date = date.AddDate(0, 0, 1)
+		}
+	case "today":
+	case "tomorrow":
+		date = date.AddDate(0, 0, 1)
+	default:
+		if parsed, err := time.ParseInLocation(time.DateOnly, day, now.Location()); err == nil {
+			return time.Date(parsed.Year(), parsed.Month(), parsed.Day(), hour, minute, 0, 0, now.Location()), nil
+		}
+
+		// a weekday: use the next matching one
+		for date.Weekday().String() != strings.ToUpper(day[:1])+day[1:] || !date.After(now) {
+			date = date.AddDate(0, 0, 1)
+		}
+	}
+
+	return date, nil
+}
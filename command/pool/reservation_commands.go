@@ -0,0 +1,170 @@
+package pool
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/pkg/errors"
+)
+
+func (c *poolCommands) reserve(match matcher.Result, message msg.Message) {
+	_, userName := client.GetUserIDAndName(message.GetUser())
+
+	resourceName := match.GetString("resource")
+	reason := match.GetString("reason")
+
+	from, err := parseReservationTime(match.GetString("from"), time.Now())
+	if err != nil {
+		c.slackClient.ReplyError(message, err)
+		return
+	}
+
+	duration, err := util.ParseDuration(match.GetString("duration"))
+	if err != nil {
+		c.slackClient.ReplyError(message, err)
+		return
+	}
+
+	reservation, err := c.pool.Reserve(userName, reason, resourceName, from, from.Add(duration))
+	if err != nil {
+		c.slackClient.ReplyError(message, err)
+		return
+	}
+
+	c.slackClient.SendMessage(
+		message,
+		fmt.Sprintf("`%s` is reserved for you from %s until %s!\n%s",
+			reservation.Resource,
+			reservation.From.Format(time.RFC1123),
+			reservation.Until.Format(time.RFC1123),
+			getFormattedReason(reservation.Reason),
+		),
+	)
+}
+
+func (c *poolCommands) cancelReservation(match matcher.Result, message msg.Message) {
+	_, userName := client.GetUserIDAndName(message.GetUser())
+
+	reservation, err := c.pool.CancelReservation(userName, match.GetString("resource"))
+	if err != nil {
+		c.slackClient.ReplyError(message, err)
+		return
+	}
+
+	c.slackClient.SendMessage(
+		message,
+		fmt.Sprintf("your reservation for `%s` at %s got canceled", reservation.Resource, reservation.From.Format(time.RFC1123)),
+	)
+}
+
+func (c *poolCommands) listReservations(_ matcher.Result, message msg.Message) {
+	reservations := c.pool.GetReservations("")
+	waitingList := c.pool.GetWaitingList()
+
+	messages := []string{"*Reservations:*"}
+	for _, r := range reservations {
+		messages = append(messages, fmt.Sprintf("`%s` reserved by %s from %s until %s\n%s", r.Resource, r.User, r.From.Format(time.RFC1123), r.Until.Format(time.RFC1123), getFormattedReason(r.Reason)))
+	}
+
+	messages = append(messages, "", "*Waiting list:*")
+	for i, w := range waitingList {
+		messages = append(messages, fmt.Sprintf("%d. %s is waiting for %s since %s", i+1, w.User, getWaitingResourceName(w.Resource), w.Since.Format(time.RFC1123)))
+	}
+
+	c.slackClient.SendMessage(message, strings.Join(messages, "\n"))
+}
+
+func (c *poolCommands) wait(match matcher.Result, message msg.Message) {
+	_, userName := client.GetUserIDAndName(message.GetUser())
+
+	resourceName := match.GetString("resource")
+	reason := match.GetString("reason")
+
+	// if there is a free resource, just lock it directly
+	resource, err := c.pool.Lock(userName, reason, resourceName)
+	if err == nil {
+		c.sendLocked(message, resource)
+		return
+	}
+
+	position, err := c.pool.Wait(userName, reason, resourceName)
+	if err != nil {
+		c.slackClient.ReplyError(message, err)
+		return
+	}
+
+	c.slackClient.SendMessage(
+		message,
+		fmt.Sprintf("you are on position %d of the waiting list for %s. I'll lock it for you and notify you as soon as it's free!", position, getWaitingResourceName(resourceName)),
+	)
+}
+
+func (c *poolCommands) stopWaiting(_ matcher.Result, message msg.Message) {
+	_, userName := client.GetUserIDAndName(message.GetUser())
+
+	if err := c.pool.StopWaiting(userName); err != nil {
+		c.slackClient.ReplyError(message, err)
+		return
+	}
+
+	c.slackClient.SendMessage(message, "you got removed from the waiting list")
+}
+
+// processQueue starts due reservations and hands over free resources to waiting users
+func (c *poolCommands) processQueue() {
+	activated, rejected := c.pool.activateReservations(time.Now())
+	for _, reservation := range rejected {
+		c.slackClient.SendToUser(
+			reservation.User,
+			fmt.Sprintf("your reservation of `%s` from %s until %s got rejected: the resource is still locked by a different user",
+				reservation.Resource,
+				reservation.From.Format(time.RFC1123),
+				reservation.Until.Format(time.RFC1123),
+			),
+		)
+	}
+
+	for _, lock := range activated {
+		c.slackClient.SendToUser(
+			lock.User,
+			fmt.Sprintf("your reservation started: `%s` is locked for you until %s!\n%s%s",
+				lock.Resource.Name,
+				lock.LockUntil.Format(time.RFC1123),
+				getFormattedReason(lock.Reason),
+				getAddressesAndFeatures(lock.Resource),
+			),
+		)
+		runHooks(lock.Resource.OnLock, getUserRef(lock.User), lock)
+	}
+
+	for _, lock := range c.pool.processWaitingList() {
+		c.slackClient.SendToUser(
+			lock.User,
+			fmt.Sprintf("your wait is over: `%s` is locked for you until %s!\n%s%s",
+				lock.Resource.Name,
+				lock.LockUntil.Format(time.RFC1123),
+				getFormattedReason(lock.Reason),
+				getAddressesAndFeatures(lock.Resource),
+			),
+		)
+		runHooks(lock.Resource.OnLock, getUserRef(lock.User), lock)
+	}
+}
+
+// isUnavailableError checks if the lock failed because the resource is just in use right now
+func isUnavailableError(err error) bool {
+	return errors.Is(err, ErrNoResourceAvailable) || errors.Is(err, ErrResourceReserved) || errors.Is(err, ErrResourceUnhealthy)
+}
+
+func getWaitingResourceName(resourceName string) string {
+	if resourceName == "" {
+		return "any resource"
+	}
+
+	return fmt.Sprintf("`%s`", resourceName)
+}
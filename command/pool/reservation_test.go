@@ -0,0 +1,254 @@
+package pool
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReservationTime(t *testing.T) {
+	// a wednesday
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"14:00", time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC)},
+		{"10:00", time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)},
+		{"today 18:30", time.Date(2024, 5, 1, 18, 30, 0, 0, time.UTC)},
+		{"tomorrow 10:00", time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)},
+		{"Friday 9:15", time.Date(2024, 5, 3, 9, 15, 0, 0, time.UTC)},
+		{"wednesday 10:00", time.Date(2024, 5, 8, 10, 0, 0, 0, time.UTC)},
+		{"2024-06-10 08:00", time.Date(2024, 6, 10, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.input, func(t *testing.T) {
+			actual, err := parseReservationTime(testCase.input, now)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+
+	for _, input := range []string{"", "soon", "25:00", "10:61", "tomorrow"} {
+		_, err := parseReservationTime(input, now)
+		assert.Equal(t, ErrInvalidReservationAt, err, input)
+	}
+}
+
+func TestPoolReservations(t *testing.T) {
+	storage.InitStorage("")
+
+	cfg := &config.Pool{
+		LockDuration: time.Hour,
+		Resources: []*config.Resource{
+			{Name: "server1"},
+			{Name: "server2"},
+		},
+	}
+
+	t.Run("reserve and block locks", func(t *testing.T) {
+		p := getNewPool(cfg)
+
+		from := time.Now().Add(30 * time.Minute)
+		reservation, err := p.Reserve("user1", "load test", "server1", from, from.Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, "server1", reservation.Resource)
+
+		// overlapping reservation of another user
+		_, err = p.Reserve("user2", "", "server1", from.Add(time.Hour), from.Add(4*time.Hour))
+		assert.Equal(t, ErrResourceReserved, err)
+
+		// invalid time frames
+		_, err = p.Reserve("user2", "", "server1", time.Now().Add(-time.Hour), time.Now())
+		assert.Equal(t, ErrInvalidReservation, err)
+		_, err = p.Reserve("user2", "", "unknown", from, from.Add(time.Hour))
+		assert.Equal(t, ErrResourceNotFound, err)
+
+		// the lock would overlap with the reservation of user1
+		_, err = p.Lock("user2", "", "server1")
+		assert.Equal(t, ErrResourceReserved, err)
+
+		// ...so server2 is used instead
+		lock, err := p.Lock("user2", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, "server2", lock.Resource.Name)
+
+		// the reserving user is still able to lock it
+		_, err = p.Lock("user1", "", "server1")
+		require.NoError(t, err)
+		require.NoError(t, p.Unlock("user1", "server1"))
+
+		// reservation is still present after a restart
+		p2 := getNewPool(cfg)
+		reservations := p2.GetReservations("user1")
+		require.Len(t, reservations, 1)
+		assert.Equal(t, "load test", reservations[0].Reason)
+
+		// the reservation gets activated
+		activated, rejected := p.activateReservations(from.Add(time.Minute))
+		assert.Empty(t, rejected)
+		require.Len(t, activated, 1)
+		assert.Equal(t, "user1", activated[0].User)
+		assert.Equal(t, from.Add(2*time.Hour), activated[0].LockUntil)
+		assert.Empty(t, p.GetReservations(""))
+		assert.Len(t, p.GetLocks("user1"), 1)
+
+		require.NoError(t, p.Unlock("user1", "server1"))
+		require.NoError(t, p.Unlock("user2", "server2"))
+	})
+
+	t.Run("reservation collides with lock", func(t *testing.T) {
+		p := getNewPool(cfg)
+
+		from := time.Now().Add(30 * time.Minute)
+		_, err := p.Reserve("user1", "", "server1", from, from.Add(time.Hour))
+		require.NoError(t, err)
+
+		// e.g. a lock which got handed over or force-locked by an admin in the meantime
+		p.mu.Lock()
+		p.locks[p.getResource("server1")] = &ResourceLock{Resource: *p.getResource("server1"), User: "user2", LockUntil: from.Add(2 * time.Hour)}
+		p.mu.Unlock()
+
+		activated, rejected := p.activateReservations(from.Add(time.Minute))
+		assert.Empty(t, activated)
+		require.Len(t, rejected, 1)
+		assert.Equal(t, "user1", rejected[0].User)
+		assert.Empty(t, p.GetReservations(""))
+
+		require.NoError(t, p.Unlock("user2", "server1"))
+	})
+
+	t.Run("cancel reservation", func(t *testing.T) {
+		p := getNewPool(cfg)
+
+		from := time.Now().Add(time.Hour)
+		_, err := p.Reserve("user1", "", "server2", from, from.Add(time.Hour))
+		require.NoError(t, err)
+
+		_, err = p.CancelReservation("user2", "server2")
+		assert.Equal(t, ErrNoReservationFound, err)
+
+		reservation, err := p.CancelReservation("user1", "server2")
+		require.NoError(t, err)
+		assert.Equal(t, from, reservation.From)
+		assert.Empty(t, p.GetReservations(""))
+		assert.Empty(t, getNewPool(cfg).GetReservations(""))
+	})
+}
+
+func TestPoolWaitingList(t *testing.T) {
+	storage.InitStorage("")
+
+	cfg := &config.Pool{
+		LockDuration: time.Hour,
+		Resources: []*config.Resource{
+			{Name: "server1"},
+			{Name: "server2"},
+		},
+	}
+
+	p := getNewPool(cfg)
+
+	_, err := p.Lock("user1", "", "server1")
+	require.NoError(t, err)
+	_, err = p.Lock("user2", "", "server2")
+	require.NoError(t, err)
+
+	position, err := p.Wait("user3", "waiting", "server2")
+	require.NoError(t, err)
+	assert.Equal(t, 1, position)
+
+	// the position is counted per resource
+	position, err = p.Wait("user4", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, position)
+
+	position, err = p.Wait("user5", "", "server2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, position)
+	require.NoError(t, p.StopWaiting("user5"))
+
+	_, err = p.Wait("user4", "", "")
+	assert.Equal(t, ErrAlreadyWaiting, err)
+	_, err = p.Wait("user4", "", "unknown")
+	assert.Equal(t, ErrResourceNotFound, err)
+
+	// nothing is free yet
+	assert.Empty(t, p.processWaitingList())
+
+	// server1 is only interesting for user4, who waits for any resource
+	require.NoError(t, p.Unlock("user1", "server1"))
+	locked := p.processWaitingList()
+	require.Len(t, locked, 1)
+	assert.Equal(t, "user4", locked[0].User)
+	assert.Equal(t, "server1", locked[0].Resource.Name)
+
+	// waiting list survives a restart
+	waitingList := getNewPool(cfg).GetWaitingList()
+	require.Len(t, waitingList, 1)
+	assert.Equal(t, "user3", waitingList[0].User)
+
+	require.NoError(t, p.StopWaiting("user3"))
+	assert.Equal(t, ErrNotWaiting, p.StopWaiting("user3"))
+	assert.Empty(t, p.GetWaitingList())
+}
+
+func TestPoolWaitCommand(t *testing.T) {
+	storage.InitStorage("")
+
+	slackClient := mocks.NewSlackClient(t)
+	base := bot.BaseCommand{SlackClient: slackClient}
+
+	client.AllUsers = config.UserMap{
+		"U1": "user1",
+		"U2": "user2",
+	}
+
+	cfg := &config.Pool{
+		LockDuration: time.Hour,
+		Resources: []*config.Resource{
+			{Name: "server1"},
+		},
+	}
+	commands := GetCommands(&config.Config{Pool: *cfg}, base)
+
+	message := msg.Message{}
+	message.User = "U1"
+	message.Text = "pool lock server1"
+	mocks.AssertSlackMessageRegexp(slackClient, message, "^`server1` is locked for you until")
+	assert.True(t, commands.Run(message))
+
+	message = msg.Message{}
+	message.User = "U2"
+	message.Text = "pool wait server1 testing"
+	mocks.AssertSlackMessage(slackClient, message, "you are on position 1 of the waiting list for `server1`. I'll lock it for you and notify you as soon as it's free!")
+	assert.True(t, commands.Run(message))
+
+	message = msg.Message{}
+	message.User = "U2"
+	message.Text = "pool reservations"
+	mocks.AssertSlackMessageRegexp(slackClient, message, "^\\*Reservations:\\*\n\n\\*Waiting list:\\*\n1. user2 is waiting for `server1` since")
+	assert.True(t, commands.Run(message))
+
+	// unlocking hands over the resource to user2
+	message = msg.Message{}
+	message.User = "U1"
+	message.Text = "pool unlock server1"
+	mocks.AssertSlackMessage(slackClient, message, "`server1` is free again")
+	slackClient.On("SendToUser", "user2", mock.MatchedBy(func(text string) bool {
+		return strings.HasPrefix(text, "your wait is over: `server1` is locked for you until")
+	})).Once()
+	assert.True(t, commands.Run(message))
+}
@@ -0,0 +1,156 @@
+package pool
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const waitingListStorageKey = "pool_waiting"
+
+var (
+	ErrAlreadyWaiting = errors.New("you are already on the waiting list")
+	ErrNotWaiting     = errors.New("you are not on the waiting list")
+)
+
+// WaitingEntry is a user waiting for a resource. The resource gets locked automatically for the user when it becomes free
+type WaitingEntry struct {
+	User     string
+	Resource string // empty when waiting for any resource
+	Reason   string
+	Since    time.Time
+}
+
+// storage key of the entry, the waiting list is ordered by this time
+func (w *WaitingEntry) key() string {
+	return strconv.FormatInt(w.Since.UnixNano(), 10)
+}
+
+// loadWaitingList restores the stored waiting list, the caller has to hold p.mu
+func (p *pool) loadWaitingList() {
+	keys, _ := storage.GetKeys(waitingListStorageKey)
+	for _, key := range keys {
+		var entry WaitingEntry
+		if err := storage.Read(waitingListStorageKey, key, &entry); err != nil {
+			log.Errorf("[Pool] unable to restore waiting list entry '%s': %s", key, err)
+			continue
+		}
+
+		p.waitingList = append(p.waitingList, &entry)
+	}
+
+	sort.SliceStable(p.waitingList, func(i, j int) bool {
+		return p.waitingList[i].Since.Before(p.waitingList[j].Since)
+	})
+}
+
+// Wait adds the user to the waiting list of a specific resource / any resource if resourceName = "".
+// Returns the position in the waiting list of this resource.
+func (p *pool) Wait(user, reason, resourceName string) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if resourceName != "" && p.getResource(resourceName) == nil {
+		return 0, ErrResourceNotFound
+	}
+
+	for _, entry := range p.waitingList {
+		if entry.User == user && entry.Resource == resourceName {
+			return 0, ErrAlreadyWaiting
+		}
+	}
+
+	entry := &WaitingEntry{
+		User:     user,
+		Resource: resourceName,
+		Reason:   reason,
+		Since:    time.Now(),
+	}
+	p.waitingList = append(p.waitingList, entry)
+
+	if err := storage.Write(waitingListStorageKey, entry.key(), entry); err != nil {
+		log.Error(errors.Wrap(err, "error while storing pool waiting list entry"))
+	}
+
+	position := 0
+	for _, waiting := range p.waitingList {
+		if waiting.Resource == resourceName {
+			position++
+		}
+	}
+
+	return position, nil
+}
+
+// StopWaiting removes all waiting list entries of the user
+func (p *pool) StopWaiting(user string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	found := false
+	for i := 0; i < len(p.waitingList); {
+		if p.waitingList[i].User != user {
+			i++
+			continue
+		}
+
+		p.removeWaitingEntry(i)
+		found = true
+	}
+
+	if !found {
+		return ErrNotWaiting
+	}
+
+	return nil
+}
+
+// GetWaitingList returns value-copies of all waiting list entries, in order of their arrival
+func (p *pool) GetWaitingList() []WaitingEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entries := make([]WaitingEntry, 0, len(p.waitingList))
+	for _, entry := range p.waitingList {
+		entries = append(entries, *entry)
+	}
+
+	return entries
+}
+
+// processWaitingList locks free resources for waiting users (first come, first served).
+// Returns the new locks, so that the users can be notified.
+func (p *pool) processWaitingList() []ResourceLock {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var locked []ResourceLock
+	for i := 0; i < len(p.waitingList); {
+		entry := p.waitingList[i]
+
+		resourceLock, err := p.tryLock(entry.User, entry.Reason, entry.Resource, nil)
+		if err != nil {
+			i++
+			continue
+		}
+
+		p.removeWaitingEntry(i)
+		locked = append(locked, *resourceLock)
+	}
+
+	return locked
+}
+
+// removeWaitingEntry removes the entry with the given index, the caller has to hold p.mu
+func (p *pool) removeWaitingEntry(idx int) {
+	entry := p.waitingList[idx]
+	p.waitingList = append(p.waitingList[:idx], p.waitingList[idx+1:]...)
+
+	if err := storage.Delete(waitingListStorageKey, entry.key()); err != nil {
+		log.Error(errors.Wrap(err, "error while deleting pool waiting list entry"))
+	}
+}
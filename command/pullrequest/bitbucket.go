@@ -5,6 +5,7 @@ import (
 	"slices"
 	"strings"
 	"text/template"
+	"time"
 
 	gojira "github.com/andygrunwald/go-jira"
 	bitbucket "github.com/gfleury/go-bitbucket-v1"
@@ -41,6 +42,7 @@ func newBitbucketCommand(base bot.BaseCommand, cfg *config.Config, jiraClient *g
 		&bitbucketFetcher{bitbucketClient},
 		"(?s).*" + hostPattern(cfg.Bitbucket.Host) + "/projects/(?P<project>.+)/repos/(?P<repo>.+)/pull-requests/(?P<number>\\d+).*",
 		jiraClient,
+		newSummarizer(cfg),
 	}
 }
 
@@ -77,12 +79,19 @@ func (c *bitbucketFetcher) getPullRequest(match matcher.Result, config *config.P
 	}
 
 	approvers := make([]string, 0)
+	var reviewers []string
 	for _, reviewer := range rawPullRequest.Reviewers {
+		reviewers = append(reviewers, reviewer.User.Name)
 		if reviewer.Approved {
 			approvers = append(approvers, reviewer.User.Name)
 		}
 	}
 
+	var createdAt time.Time
+	if rawPullRequest.CreatedDate > 0 {
+		createdAt = time.UnixMilli(rawPullRequest.CreatedDate)
+	}
+
 	var author string
 	if rawPullRequest.Author != nil {
 		author = rawPullRequest.Author.User.Name
@@ -114,6 +123,8 @@ func (c *bitbucketFetcher) getPullRequest(match matcher.Result, config *config.P
 		Link:                          link,
 		Branch:                        rawPullRequest.FromRef.DisplayID,
 		Approvers:                     approvers,
+		Reviewers:                     reviewers,
+		CreatedAt:                     createdAt,
 		LatestReviewCommentsTimestamp: latestCommentTimestamp,
 	}
 
@@ -136,6 +147,67 @@ func (c *bitbucketFetcher) getStatus(pr *bitbucket.PullRequest) prStatus {
 	return prStatusOpen
 }
 
+func (c *bitbucketFetcher) getDiff(match matcher.Result) (string, string, error) {
+	project := match.GetString("project")
+	repo := match.GetString("repo")
+	number := match.GetInt("number")
+
+	rawResponse, err := c.bitbucketClient.GetPullRequest(project, repo, number)
+	if err != nil {
+		return "", "", errors.Wrap(err, "error while loading data from Bitbucket")
+	}
+
+	rawPullRequest, err := bitbucket.GetPullRequestResponse(rawResponse)
+	if err != nil {
+		return "", "", err
+	}
+
+	rawDiff, err := c.bitbucketClient.GetPullRequestDiffRaw(project, repo, number, map[string]any{})
+	if err != nil {
+		return "", "", errors.Wrap(err, "error while loading diff from Bitbucket")
+	}
+
+	return rawPullRequest.Description, string(rawDiff.Payload), nil
+}
+
+// bitbucketMergeStrategies maps the merge method to the Bitbucket merge strategy
+var bitbucketMergeStrategies = map[string]string{
+	"merge":  "no-ff",
+	"squash": "squash",
+	"rebase": "rebase-no-ff",
+}
+
+func (c *bitbucketFetcher) merge(match matcher.Result, method string) error {
+	project := match.GetString("project")
+	repo := match.GetString("repo")
+	number := match.GetInt("number")
+
+	// the current version of the PR is needed to merge it
+	rawResponse, err := c.bitbucketClient.GetPullRequest(project, repo, number)
+	if err != nil {
+		return errors.Wrap(err, "error while loading data from Bitbucket")
+	}
+
+	rawPullRequest, err := bitbucket.GetPullRequestResponse(rawResponse)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]string{
+		"strategyId": bitbucketMergeStrategies[method],
+	}
+	_, err = c.bitbucketClient.Merge(
+		project,
+		repo,
+		number,
+		map[string]any{"version": rawPullRequest.Version},
+		body,
+		[]string{"application/json"},
+	)
+
+	return errors.Wrap(err, "error while merging the PR in Bitbucket")
+}
+
 // try to extract the current build Status from a PR, based on the recent commit
 func (c *bitbucketFetcher) getBuildStatus(lastCommit string) buildStatus {
 	status := buildStatusUnknown
@@ -0,0 +1,241 @@
+package pullrequest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	gojira "github.com/andygrunwald/go-jira"
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const bitbucketCloudHost = "https://bitbucket.org"
+
+type bitbucketCloudFetcher struct {
+	client *client.BitbucketCloud
+}
+
+func newBitbucketCloudCommand(base bot.BaseCommand, cfg *config.Config, jiraClient *gojira.Client) bot.Command {
+	if !cfg.BitbucketCloud.IsEnabled() {
+		return nil
+	}
+
+	bitbucketClient, err := client.GetBitbucketCloudClient(cfg.BitbucketCloud)
+	if err != nil {
+		log.Error(errors.Wrap(err, "error while initializing bitbucket cloud client"))
+		return nil
+	}
+
+	return command{
+		base,
+		cfg.PullRequest,
+		&bitbucketCloudFetcher{bitbucketClient},
+		"(?s).*" + hostPattern(bitbucketCloudHost) + "/(?P<project>[^/\\s]+)/(?P<repo>[^/\\s]+)/pull-requests/(?P<number>\\d+).*",
+		jiraClient,
+		newSummarizer(cfg),
+	}
+}
+
+// bitbucketCloudUser is a user in the Bitbucket Cloud API. "nickname" is the public username
+type bitbucketCloudUser struct {
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname"`
+}
+
+func (u bitbucketCloudUser) getName() string {
+	if u.Nickname != "" {
+		return u.Nickname
+	}
+
+	return u.DisplayName
+}
+
+// bitbucketCloudPullRequest contains the used fields of https://developer.atlassian.com/cloud/bitbucket/rest/api-group-pullrequests/
+type bitbucketCloudPullRequest struct {
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	State       string             `json:"state"`
+	Author      bitbucketCloudUser `json:"author"`
+	CreatedOn   time.Time          `json:"created_on"`
+	Source      struct {
+		Branch struct {
+			Name string `json:"name"`
+		} `json:"branch"`
+	} `json:"source"`
+	Reviewers    []bitbucketCloudUser `json:"reviewers"`
+	Participants []struct {
+		User     bitbucketCloudUser `json:"user"`
+		Approved bool               `json:"approved"`
+	} `json:"participants"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+func getBitbucketCloudPath(match matcher.Result) string {
+	return fmt.Sprintf(
+		"repositories/%s/%s/pullrequests/%d",
+		match.GetString("project"),
+		match.GetString("repo"),
+		match.GetInt("number"),
+	)
+}
+
+func (c *bitbucketCloudFetcher) loadPullRequest(match matcher.Result) (*bitbucketCloudPullRequest, error) {
+	rawPullRequest := &bitbucketCloudPullRequest{}
+	if err := c.client.Get(getBitbucketCloudPath(match), rawPullRequest); err != nil {
+		return nil, err
+	}
+
+	return rawPullRequest, nil
+}
+
+func (c *bitbucketCloudFetcher) getPullRequest(match matcher.Result, _ *config.PullRequest) (pullRequest, error) {
+	rawPullRequest, err := c.loadPullRequest(match)
+	if err != nil {
+		// handle deleted PR as already "closed" one
+		var apiErr client.BitbucketCloudError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return closedPr, nil
+		}
+
+		return pullRequest{}, errors.Wrap(err, "error while loading data from Bitbucket Cloud")
+	}
+
+	approvers := make([]string, 0)
+	for _, participant := range rawPullRequest.Participants {
+		if participant.Approved {
+			approvers = append(approvers, participant.User.getName())
+		}
+	}
+
+	reviewers := make([]string, 0, len(rawPullRequest.Reviewers))
+	for _, reviewer := range rawPullRequest.Reviewers {
+		reviewers = append(reviewers, reviewer.getName())
+	}
+
+	return pullRequest{
+		Name:        rawPullRequest.Title,
+		Status:      c.getStatus(rawPullRequest),
+		BuildStatus: c.getBuildStatus(match),
+		Author:      rawPullRequest.Author.getName(),
+		Link:        rawPullRequest.Links.HTML.Href,
+		Branch:      rawPullRequest.Source.Branch.Name,
+		Approvers:   approvers,
+		Reviewers:   reviewers,
+		CreatedAt:   rawPullRequest.CreatedOn,
+	}, nil
+}
+
+func (c *bitbucketCloudFetcher) getStatus(pr *bitbucketCloudPullRequest) prStatus {
+	switch pr.State {
+	case "MERGED":
+		return prStatusMerged
+	case "DECLINED", "SUPERSEDED":
+		return prStatusClosed
+	}
+
+	if len(pr.Reviewers) > 0 {
+		return prStatusInReview
+	}
+
+	return prStatusOpen
+}
+
+// getBuildStatus loads the build states of the latest commit of the PR
+func (c *bitbucketCloudFetcher) getBuildStatus(match matcher.Result) buildStatus {
+	status := buildStatusUnknown
+
+	rawStatuses, err := c.client.GetAllPages(getBitbucketCloudPath(match) + "/statuses")
+	if err != nil {
+		return status
+	}
+
+	for _, rawStatus := range rawStatuses {
+		var build struct {
+			State string `json:"state"`
+		}
+		if err = json.Unmarshal(rawStatus, &build); err != nil {
+			continue
+		}
+
+		switch build.State {
+		case "SUCCESSFUL":
+			if status == buildStatusUnknown {
+				status = buildStatusSuccess
+			}
+		case "INPROGRESS":
+			status = buildStatusRunning
+		case "FAILED", "STOPPED":
+			if status != buildStatusRunning {
+				status = buildStatusFailed
+			}
+		}
+	}
+
+	return status
+}
+
+func (c *bitbucketCloudFetcher) getDiff(match matcher.Result) (string, string, error) {
+	rawPullRequest, err := c.loadPullRequest(match)
+	if err != nil {
+		return "", "", errors.Wrap(err, "error while loading data from Bitbucket Cloud")
+	}
+
+	rawDiff, err := c.client.GetRaw(getBitbucketCloudPath(match) + "/diff")
+	if err != nil {
+		return "", "", errors.Wrap(err, "error while loading diff from Bitbucket Cloud")
+	}
+
+	return rawPullRequest.Description, string(rawDiff), nil
+}
+
+// bitbucketCloudMergeStrategies maps the merge method to the Bitbucket Cloud merge strategy
+var bitbucketCloudMergeStrategies = map[string]string{
+	"merge":  "merge_commit",
+	"squash": "squash",
+	"rebase": "fast_forward",
+}
+
+func (c *bitbucketCloudFetcher) merge(match matcher.Result, method string) error {
+	body := map[string]string{
+		"merge_strategy": bitbucketCloudMergeStrategies[method],
+	}
+	err := c.client.Post(getBitbucketCloudPath(match)+"/merge", body, nil)
+
+	return errors.Wrap(err, "error while merging the PR in Bitbucket Cloud")
+}
+
+func (c *bitbucketCloudFetcher) GetTemplateFunction(cfg *config.PullRequest) template.FuncMap {
+	return template.FuncMap{
+		"bitbucketCloudPullRequest": func(workspace string, repo string, number string) (pullRequest, error) {
+			return c.getPullRequest(matcher.Result{
+				"project": workspace,
+				"repo":    repo,
+				"number":  number,
+			}, cfg)
+		},
+	}
+}
+
+func (c *bitbucketCloudFetcher) getHelp() []bot.Help {
+	return []bot.Help{
+		{
+			Command:     "bitbucket cloud pull request",
+			Category:    category,
+			Description: "tracks the state of bitbucket.org pull requests",
+			Examples: []string{
+				"https://bitbucket.org/my-workspace/my-repo/pull-requests/42",
+			},
+		},
+	}
+}
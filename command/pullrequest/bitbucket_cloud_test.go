@@ -0,0 +1,147 @@
+package pullrequest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitbucketCloud(t *testing.T) {
+	var mergeBody map[string]string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repositories/workspace/repo/pullrequests/12", func(res http.ResponseWriter, req *http.Request) {
+		username, password, _ := req.BasicAuth()
+		assert.Equal(t, "bot", username)
+		assert.Equal(t, "app-password", password)
+
+		fmt.Fprint(res, `{
+			"title": "Add feature",
+			"description": "adds the feature",
+			"state": "OPEN",
+			"created_on": "2024-01-02T10:00:00.000000+00:00",
+			"author": {"display_name": "Alice", "nickname": "alice"},
+			"source": {"branch": {"name": "feature/PROJ-123-feature"}},
+			"reviewers": [{"display_name": "Bob", "nickname": "bob"}, {"display_name": "Carol", "nickname": "carol"}],
+			"participants": [
+				{"user": {"nickname": "bob"}, "approved": true},
+				{"user": {"nickname": "carol"}, "approved": false}
+			],
+			"links": {"html": {"href": "https://bitbucket.org/workspace/repo/pull-requests/12"}}
+		}`)
+	})
+	mux.HandleFunc("/repositories/workspace/repo/pullrequests/12/statuses", func(res http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(res, `{"values": [{"state": "SUCCESSFUL"}, {"state": "INPROGRESS"}]}`)
+	})
+	mux.HandleFunc("/repositories/workspace/repo/pullrequests/12/diff", func(res http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(res, "diff --git a/readme.md b/readme.md")
+	})
+	mux.HandleFunc("POST /repositories/workspace/repo/pullrequests/12/merge", func(res http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		_ = json.Unmarshal(body, &mergeBody)
+		fmt.Fprint(res, `{"state": "MERGED"}`)
+	})
+	mux.HandleFunc("/repositories/workspace/repo/pullrequests/13", func(res http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(res, `{"title": "Old feature", "state": "MERGED"}`)
+	})
+	mux.HandleFunc("/repositories/workspace/repo/pullrequests/13/statuses", func(res http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(res, `{"values": [{"state": "FAILED"}]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	slackClient := mocks.NewSlackClient(t)
+	base := bot.BaseCommand{SlackClient: slackClient}
+
+	t.Run("not active", func(t *testing.T) {
+		assert.Nil(t, newBitbucketCloudCommand(base, &config.DefaultConfig, nil))
+	})
+
+	cfg := config.DefaultConfig
+	cfg.BitbucketCloud = config.BitbucketCloud{
+		Username:    "bot",
+		AppPassword: "app-password",
+		APIURL:      server.URL,
+	}
+
+	cmd := newBitbucketCloudCommand(base, &cfg, nil).(command)
+	fetcher := cmd.fetcher.(*bitbucketCloudFetcher)
+
+	commands := bot.Commands{}
+	commands.AddCommand(cmd)
+
+	t.Run("open PR", func(t *testing.T) {
+		pr, err := fetcher.getPullRequest(matcher.Result{"project": "workspace", "repo": "repo", "number": "12"}, &cfg.PullRequest)
+		require.NoError(t, err)
+
+		assert.True(t, pr.CreatedAt.Equal(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)))
+		pr.CreatedAt = time.Time{}
+
+		assert.Equal(t, pullRequest{
+			Name:        "Add feature",
+			Status:      prStatusInReview,
+			BuildStatus: buildStatusRunning,
+			Author:      "alice",
+			Link:        "https://bitbucket.org/workspace/repo/pull-requests/12",
+			Branch:      "feature/PROJ-123-feature",
+			Approvers:   []string{"bob"},
+			Reviewers:   []string{"bob", "carol"},
+		}, pr)
+	})
+
+	t.Run("not existing PR", func(t *testing.T) {
+		pr, err := fetcher.getPullRequest(matcher.Result{"project": "workspace", "repo": "repo", "number": "14"}, &cfg.PullRequest)
+		require.NoError(t, err)
+		assert.Equal(t, closedPr, pr)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		description, diff, err := fetcher.getDiff(matcher.Result{"project": "workspace", "repo": "repo", "number": "12"})
+		require.NoError(t, err)
+		assert.Equal(t, "adds the feature", description)
+		assert.Equal(t, "diff --git a/readme.md b/readme.md", diff)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		err := fetcher.merge(matcher.Result{"project": "workspace", "repo": "repo", "number": "12"}, "squash")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"merge_strategy": "squash"}, mergeBody)
+	})
+
+	t.Run("PR link", func(t *testing.T) {
+		pattern := regexp.MustCompile(cmd.regexp)
+
+		match := pattern.FindStringSubmatch("please review bitbucket.org/workspace/repo/pull-requests/13/overview")
+		require.NotNil(t, match)
+		assert.Equal(t, []string{"workspace", "repo", "13"}, match[1:])
+
+		assert.False(t, pattern.MatchString("https://bitbucket.example.com/projects/workspace/repos/repo/pull-requests/13"))
+	})
+
+	t.Run("render template", func(t *testing.T) {
+		tpl, err := util.CompileTemplate(`{{$pr := bitbucketCloudPullRequest "workspace" "repo" "13"}}PR: {{$pr.Name}} - {{$pr.BuildStatus}}`)
+		require.NoError(t, err)
+
+		res, err := util.EvalTemplate(tpl, util.Parameters{})
+		require.NoError(t, err)
+
+		assert.Equal(t, fmt.Sprintf("PR: Old feature - %d", buildStatusFailed), res)
+	})
+
+	t.Run("help", func(t *testing.T) {
+		assert.Len(t, commands.GetHelp(), 1)
+	})
+}
@@ -29,10 +29,26 @@ func GetCommands(base bot.BaseCommand, cfg *config.Config) bot.Commands {
 		}
 	}
 
+	prCommands := append(
+		[]bot.Command{
+			newGitlabCommand(base, cfg, jiraClient),
+			newGithubCommand(base, cfg, jiraClient),
+			newBitbucketCommand(base, cfg, jiraClient),
+			newBitbucketCloudCommand(base, cfg, jiraClient),
+		},
+		newGithubEnterpriseCommands(base, cfg, jiraClient)...,
+	)
+
+	// "merge pr <url>" and "summarize pr <url>" have to be registered before the PR watchers, which are matching any PR link
+	commands.AddCommand(
+		newMergeCommand(base, cfg, prCommands),
+		newSummaryCommand(base, cfg, prCommands),
+	)
+	commands.AddCommand(prCommands...)
 	commands.AddCommand(
-		newGitlabCommand(base, cfg, jiraClient),
-		newGithubCommand(base, cfg, jiraClient),
-		newBitbucketCommand(base, cfg, jiraClient),
+		newWebhookCommand(cfg.PullRequest.Webhook),
+		newDashboardCommand(base, cfg.PullRequest),
+		newReminderCommand(base, cfg.PullRequest),
 	)
 
 	return commands
@@ -0,0 +1,279 @@
+package pullrequest
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/innogames/slack-bot/v2/client"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const dashboardStorageKey = "pull_requests"
+
+var (
+	prURLRegexp         = regexp.MustCompile(`https?://[^\s|>]+`)
+	invalidStorageChars = regexp.MustCompile(`[^\w\-]+`)
+)
+
+// watchedPullRequest is the latest known state of a watched PR, used by "list prs"
+type watchedPullRequest struct {
+	PullRequest  pullRequest
+	URL          string
+	Message      msg.MessageRef
+	WatchedSince time.Time
+	UpdatedAt    time.Time
+
+	// time of the last status change, like since when the PR is in review
+	StatusSince time.Time
+}
+
+// getCreatedAt returns the creation time of the PR, or the start of the watching when it's unknown
+func (w watchedPullRequest) getCreatedAt() time.Time {
+	if w.PullRequest.CreatedAt.IsZero() {
+		return w.WatchedSince
+	}
+
+	return w.PullRequest.CreatedAt
+}
+
+// getAge returns the age of the PR, or the time since it's watched when the creation date is unknown
+func (w watchedPullRequest) getAge(now time.Time) time.Duration {
+	return now.Sub(w.getCreatedAt())
+}
+
+// getWaitingReviewers returns the reviewers who didn't approve the PR yet
+func (w watchedPullRequest) getWaitingReviewers() []string {
+	return slices.DeleteFunc(slices.Clone(w.PullRequest.Reviewers), func(reviewer string) bool {
+		return containsUser(w.PullRequest.Approvers, reviewer)
+	})
+}
+
+// isWaitingFor checks if the given Slack user is a reviewer of the PR who didn't approve it yet
+func (w watchedPullRequest) isWaitingFor(cfg config.PullRequest, userID string) bool {
+	if w.PullRequest.Status != prStatusOpen && w.PullRequest.Status != prStatusInReview {
+		return false
+	}
+
+	isUser := func(vcsUser string) bool {
+		return isSlackUser(cfg, vcsUser, userID)
+	}
+
+	return slices.ContainsFunc(w.PullRequest.Reviewers, isUser) && !slices.ContainsFunc(w.PullRequest.Approvers, isUser)
+}
+
+// isSlackUser checks if the VCS user is the given Slack user, VCS usernames are resolved via the "user_mapping" config
+func isSlackUser(cfg config.PullRequest, vcsUser string, userID string) bool {
+	slackUserID, _ := client.GetUserIDAndName(cfg.GetSlackUser(vcsUser))
+
+	return slackUserID != "" && slackUserID == userID
+}
+
+func containsUser(users []string, userName string) bool {
+	return slices.ContainsFunc(users, func(user string) bool {
+		return strings.EqualFold(user, userName)
+	})
+}
+
+func getDashboardKey(message msg.Ref, match matcher.Result) string {
+	return invalidStorageChars.ReplaceAllString(
+		message.GetChannel()+"-"+message.GetTimestamp()+"-"+getMatchWatchKey(match),
+		"-",
+	)
+}
+
+// storeWatchedPullRequest stores the latest state of the PR for the dashboard
+func storeWatchedPullRequest(key string, url string, message msg.Message, pr pullRequest, watchedSince time.Time) {
+	if url == "" {
+		url = pr.Link
+	}
+
+	now := time.Now()
+	watched := watchedPullRequest{
+		PullRequest:  pr,
+		URL:          url,
+		Message:      message.MessageRef,
+		WatchedSince: watchedSince,
+		UpdatedAt:    now,
+		StatusSince:  now,
+	}
+
+	var previous watchedPullRequest
+	if err := storage.Read(dashboardStorageKey, key, &previous); err == nil && previous.PullRequest.Status == pr.Status && !previous.StatusSince.IsZero() {
+		watched.StatusSince = previous.StatusSince
+	}
+
+	if err := storage.Write(dashboardStorageKey, key, watched); err != nil {
+		log.Warnf("error while storing watched PR: %s", err)
+	}
+}
+
+func deleteWatchedPullRequest(key string) {
+	if err := storage.Delete(dashboardStorageKey, key); err != nil {
+		log.Warnf("error while deleting watched PR: %s", err)
+	}
+}
+
+func loadWatchedPullRequests() []watchedPullRequest {
+	keys, _ := storage.GetKeys(dashboardStorageKey)
+
+	pullRequests := make([]watchedPullRequest, 0, len(keys))
+	for _, key := range keys {
+		var watched watchedPullRequest
+		if err := storage.Read(dashboardStorageKey, key, &watched); err != nil {
+			continue
+		}
+		pullRequests = append(pullRequests, watched)
+	}
+
+	return pullRequests
+}
+
+type dashboardCommand struct {
+	bot.BaseCommand
+	cfg config.PullRequest
+}
+
+// newDashboardCommand lists all currently watched pull requests
+func newDashboardCommand(base bot.BaseCommand, cfg config.PullRequest) bot.Command {
+	return &dashboardCommand{base, cfg}
+}
+
+func (c *dashboardCommand) GetMatcher() matcher.Matcher {
+	return matcher.NewRegexpMatcher(`list (prs|pull requests)(?P<channel> in channel)?( (?P<filter>mine|to review))?`, c.list)
+}
+
+func (c *dashboardCommand) list(match matcher.Result, message msg.Message) {
+	inChannel := match.GetString("channel") != ""
+	filter := match.GetString("filter")
+
+	pullRequests := slices.DeleteFunc(loadWatchedPullRequests(), func(watched watchedPullRequest) bool {
+		if inChannel && watched.Message.Channel != message.GetChannel() {
+			return true
+		}
+
+		switch filter {
+		case "mine":
+			return watched.Message.User != message.GetUser() && !isSlackUser(c.cfg, watched.PullRequest.Author, message.GetUser())
+		case "to review":
+			return !watched.isWaitingFor(c.cfg, message.GetUser())
+		}
+
+		return false
+	})
+
+	// oldest PRs first
+	now := time.Now()
+	slices.SortFunc(pullRequests, func(a, b watchedPullRequest) int {
+		return cmp.Compare(b.getAge(now), a.getAge(now))
+	})
+
+	title := fmt.Sprintf("*%d watched pull requests*", len(pullRequests))
+	if filter == "to review" {
+		title = fmt.Sprintf("*%d pull requests are waiting for your review*", len(pullRequests))
+	}
+
+	blocks := make([]slack.Block, 0, len(pullRequests)+3)
+	blocks = append(blocks, client.GetTextBlock(title))
+	for _, watched := range pullRequests {
+		blocks = append(blocks, client.GetTextBlock(c.formatPullRequest(watched, now)))
+	}
+
+	// add "Updated at..." time if there was an update
+	var msgOptions []slack.MsgOption
+	if message.IsUpdatedMessage() {
+		blocks = append(blocks, client.GetContextBlock("Updated at: "+now.Format(time.Stamp)))
+		msgOptions = append(msgOptions, slack.MsgOptionUpdate(message.Timestamp))
+	}
+
+	blocks = append(
+		blocks,
+		slack.NewActionBlock(
+			"",
+			client.GetInteractionButton("refresh", "Refresh :arrows_counterclockwise:", message.GetText()),
+		),
+	)
+
+	c.SendBlockMessage(message, blocks, msgOptions...)
+}
+
+func (c *dashboardCommand) formatPullRequest(watched watchedPullRequest, now time.Time) string {
+	pr := watched.PullRequest
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s *<%s|%s>*", c.getStatusIcons(pr), watched.URL, pr.Name)
+	if pr.Author != "" {
+		fmt.Fprintf(&text, " by %s", pr.Author)
+	}
+	fmt.Fprintf(&text, " (%s old, <%s|message>)", util.FormatDuration(watched.getAge(now).Round(time.Minute)), client.GetSlackArchiveLink(watched.Message))
+
+	if len(pr.Approvers) > 0 {
+		fmt.Fprintf(&text, "\nApproved by: %s", strings.Join(pr.Approvers, ", "))
+	}
+
+	if waitingFor := watched.getWaitingReviewers(); len(waitingFor) > 0 {
+		fmt.Fprintf(&text, "\nWaiting for: %s", strings.Join(waitingFor, ", "))
+	}
+
+	return text.String()
+}
+
+// getStatusIcons uses the configured PR reactions to visualize the review and build status
+func (c *dashboardCommand) getStatusIcons(pr pullRequest) string {
+	var icon util.Reaction
+	switch {
+	case pr.Status == prStatusMerged:
+		icon = c.cfg.Reactions.Merged
+	case pr.Status == prStatusClosed:
+		icon = c.cfg.Reactions.Closed
+	case len(pr.Approvers) > 0:
+		icon = c.cfg.Reactions.Approved
+	case pr.Status == prStatusInReview:
+		icon = c.cfg.Reactions.InReview
+	}
+
+	icons := ""
+	if icon != "" {
+		icons = fmt.Sprintf(":%s:", icon.ToSlackReaction())
+	}
+
+	switch pr.BuildStatus {
+	case buildStatusFailed:
+		icons += fmt.Sprintf(":%s:", c.cfg.Reactions.BuildFailed.ToSlackReaction())
+	case buildStatusRunning:
+		icons += fmt.Sprintf(":%s:", c.cfg.Reactions.BuildRunning.ToSlackReaction())
+	case buildStatusSuccess, buildStatusUnknown:
+	}
+
+	if icons == "" {
+		return ":white_circle:"
+	}
+
+	return icons
+}
+
+func (c *dashboardCommand) GetHelp() []bot.Help {
+	return []bot.Help{
+		{
+			Command:     "list prs",
+			Description: "lists all watched pull requests with their review and build status",
+			Category:    category,
+			Examples: []string{
+				"list prs",
+				"list prs in channel",
+				"list prs mine",
+				"list prs to review",
+			},
+		},
+	}
+}
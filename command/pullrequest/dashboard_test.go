@@ -0,0 +1,166 @@
+package pullrequest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboard(t *testing.T) {
+	storage.InitStorage("")
+	client.AllUsers = config.UserMap{
+		"U1": "alice",
+		"U2": "bob",
+		"U4": "erin",
+		"U5": "frank",
+	}
+
+	slackClient := mocks.NewSlackClient(t)
+	base := bot.BaseCommand{SlackClient: slackClient}
+
+	cfg := config.DefaultConfig.PullRequest
+	cfg.UserMapping = map[string]string{
+		"e.smith": "erin",
+		"f.jones": "U5",
+	}
+
+	commands := bot.Commands{}
+	commands.AddCommand(newDashboardCommand(base, cfg))
+
+	now := time.Now()
+
+	message1 := msg.Message{}
+	message1.Channel = "C1"
+	message1.User = "U1"
+	message1.Timestamp = "1000.0001"
+	message1.Text = "please review <https://github.com/innogames/slack-bot/pull/1>"
+	match1 := matcher.Result{"project": "innogames", "repo": "slack-bot", "number": "1"}
+	key1 := getDashboardKey(message1, match1)
+	assert.Equal(t, "C1-1000-0001-innogames-slack-bot-1", key1)
+
+	storeWatchedPullRequest(key1, "https://github.com/innogames/slack-bot/pull/1", message1, pullRequest{
+		Name:        "Fix login",
+		Author:      "alice",
+		Status:      prStatusInReview,
+		BuildStatus: buildStatusFailed,
+		Reviewers:   []string{"bob", "carol"},
+		Approvers:   []string{"carol"},
+		CreatedAt:   now.Add(-2 * time.Hour),
+	}, now)
+
+	message2 := msg.Message{}
+	message2.Channel = "C2"
+	message2.User = "U3"
+	message2.Timestamp = "1000.0002"
+	message2.Text = "https://github.com/innogames/slack-bot/pull/2"
+	key2 := getDashboardKey(message2, matcher.Result{"project": "innogames", "repo": "slack-bot", "number": "2"})
+	storeWatchedPullRequest(key2, message2.Text, message2, pullRequest{
+		Name:      "Add feature",
+		Author:    "dave",
+		Status:    prStatusOpen,
+		Reviewers: []string{"Bob"},
+		CreatedAt: now.Add(-24 * time.Hour),
+	}, now)
+
+	watched := loadWatchedPullRequests()
+	require.Len(t, watched, 2)
+
+	t.Run("list all", func(t *testing.T) {
+		message := msg.Message{}
+		message.Channel = "C1"
+		message.User = "U2"
+		message.Text = "list prs"
+
+		mocks.AssertContainsSlackBlocks(t, slackClient, message, client.GetTextBlock("*2 watched pull requests*"))
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("list in channel", func(t *testing.T) {
+		message := msg.Message{}
+		message.Channel = "C1"
+		message.User = "U2"
+		message.Text = "list prs in channel"
+
+		mocks.AssertContainsSlackBlocks(t, slackClient, message, client.GetTextBlock(
+			":white_check_mark::fire: *<https://github.com/innogames/slack-bot/pull/1|Fix login>* by alice (2h0m0s old, <"+client.GetSlackArchiveLink(message1)+"|message>)\nApproved by: carol\nWaiting for: bob",
+		))
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("list mine", func(t *testing.T) {
+		message := msg.Message{}
+		message.User = "U1"
+		message.Text = "list prs mine"
+
+		mocks.AssertContainsSlackBlocks(t, slackClient, message, client.GetTextBlock("*1 watched pull requests*"))
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("list to review", func(t *testing.T) {
+		message := msg.Message{}
+		message.User = "U2"
+		message.Text = "list prs to review"
+
+		mocks.AssertContainsSlackBlocks(t, slackClient, message, client.GetTextBlock("*2 pull requests are waiting for your review*"))
+		assert.True(t, commands.Run(message))
+
+		message.User = "U1"
+		mocks.AssertContainsSlackBlocks(t, slackClient, message, client.GetTextBlock("*0 pull requests are waiting for your review*"))
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		message := msg.Message{}
+		message.User = "U2"
+		message.Text = "list prs"
+		message.UpdatedMessage = true
+		message.Timestamp = "1234.5678"
+
+		mocks.AssertContainsSlackBlocks(t, slackClient, message, slack.NewActionBlock(
+			"",
+			client.GetInteractionButton("refresh", "Refresh :arrows_counterclockwise:", "list prs"),
+		))
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("mapped users", func(t *testing.T) {
+		message3 := msg.Message{}
+		message3.Channel = "C3"
+		message3.User = "U3"
+		message3.Timestamp = "1000.0003"
+		message3.Text = "https://github.com/innogames/slack-bot/pull/3"
+		key3 := getDashboardKey(message3, matcher.Result{"project": "innogames", "repo": "slack-bot", "number": "3"})
+		storeWatchedPullRequest(key3, message3.Text, message3, pullRequest{
+			Name:      "Update docs",
+			Author:    "E.Smith",
+			Status:    prStatusInReview,
+			Reviewers: []string{"f.jones"},
+		}, now)
+		defer deleteWatchedPullRequest(key3)
+
+		message := msg.Message{}
+		message.User = "U4"
+		message.Text = "list prs mine"
+		mocks.AssertContainsSlackBlocks(t, slackClient, message, client.GetTextBlock("*1 watched pull requests*"))
+		assert.True(t, commands.Run(message))
+
+		message.User = "U5"
+		message.Text = "list prs to review"
+		mocks.AssertContainsSlackBlocks(t, slackClient, message, client.GetTextBlock("*1 pull requests are waiting for your review*"))
+		assert.True(t, commands.Run(message))
+	})
+
+	deleteWatchedPullRequest(key1)
+	deleteWatchedPullRequest(key2)
+	assert.Empty(t, loadWatchedPullRequests())
+}
@@ -2,6 +2,8 @@ package pullrequest
 
 import (
 	"context"
+	"net/http"
+	"slices"
 	"text/template"
 
 	gojira "github.com/andygrunwald/go-jira"
@@ -11,39 +13,70 @@ import (
 	"github.com/innogames/slack-bot/v2/bot/matcher"
 	"github.com/innogames/slack-bot/v2/client"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
 )
 
+const githubHost = "https://github.com"
+
 type githubFetcher struct {
 	client *github.Client
+
+	// host of the GitHub server, like "https://github.com" or a GitHub Enterprise host
+	host string
 }
 
 func newGithubCommand(base bot.BaseCommand, cfg *config.Config, jiraClient *gojira.Client) bot.Command {
-	var githubClient *github.Client
-	if cfg.Github.AccessToken == "" {
-		githubClient = github.NewClient(client.GetHTTPClient())
-	} else {
-		ctx := context.Background()
+	githubClient := github.NewClient(getGithubHTTPClient(cfg.Github.AccessToken))
 
-		oauthClient := oauth2.NewClient(
-			context.WithValue(ctx, oauth2.HTTPClient, client.GetHTTPClient()),
-			oauth2.StaticTokenSource(
-				&oauth2.Token{AccessToken: cfg.Github.AccessToken},
-			),
-		)
-		githubClient = github.NewClient(oauthClient)
+	return newGithubHostCommand(base, cfg, jiraClient, githubClient, githubHost)
+}
+
+// newGithubEnterpriseCommands creates a PR watcher for each configured GitHub Enterprise server
+func newGithubEnterpriseCommands(base bot.BaseCommand, cfg *config.Config, jiraClient *gojira.Client) []bot.Command {
+	commands := make([]bot.Command, 0, len(cfg.Github.Enterprise))
+	for _, enterprise := range cfg.Github.Enterprise {
+		apiURL := enterprise.GetAPIURL()
+		githubClient, err := github.NewEnterpriseClient(apiURL, apiURL, getGithubHTTPClient(enterprise.AccessToken))
+		if err != nil {
+			log.Error(errors.Wrapf(err, "error while initializing GitHub Enterprise client for %s", enterprise.Host))
+			continue
+		}
+
+		commands = append(commands, newGithubHostCommand(base, cfg, jiraClient, githubClient, enterprise.Host))
 	}
 
+	return commands
+}
+
+func newGithubHostCommand(base bot.BaseCommand, cfg *config.Config, jiraClient *gojira.Client, githubClient *github.Client, host string) command {
 	return command{
 		base,
 		cfg.PullRequest,
-		&githubFetcher{githubClient},
-		"(?s).*https://github.com/(?P<project>.+)/(?P<repo>.+)/pull/(?P<number>\\d+).*",
+		&githubFetcher{githubClient, host},
+		"(?s).*" + hostPattern(host) + "/(?P<project>.+)/(?P<repo>.+)/pull/(?P<number>\\d+).*",
 		jiraClient,
+		newSummarizer(cfg),
+	}
+}
+
+// getGithubHTTPClient returns an authenticated http client, when an access token is given
+func getGithubHTTPClient(accessToken string) *http.Client {
+	if accessToken == "" {
+		return client.GetHTTPClient()
 	}
+
+	ctx := context.Background()
+
+	return oauth2.NewClient(
+		context.WithValue(ctx, oauth2.HTTPClient, client.GetHTTPClient()),
+		oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: accessToken},
+		),
+	)
 }
 
-func (c *githubFetcher) getPullRequest(match matcher.Result, _ *config.PullRequest) (pullRequest, error) {
+func (c *githubFetcher) getPullRequest(match matcher.Result, cfg *config.PullRequest) (pullRequest, error) {
 	var pr pullRequest
 
 	project := match.GetString("project")
@@ -66,10 +99,25 @@ func (c *githubFetcher) getPullRequest(match matcher.Result, _ *config.PullReque
 		return pr, err
 	}
 
+	var author string
+	if rawPullRequest.User != nil && rawPullRequest.User.Login != nil {
+		author = *rawPullRequest.User.Login
+	}
+
 	approvers := make([]string, 0)
 	inReview := false
 
+	var reviewers []string
+	for _, reviewer := range rawPullRequest.RequestedReviewers {
+		reviewers = append(reviewers, reviewer.GetLogin())
+	}
+
 	for _, review := range reviews {
+		login := review.GetUser().GetLogin()
+		if login != author && !slices.Contains(reviewers, login) {
+			reviewers = append(reviewers, login)
+		}
+
 		state := review.GetState()
 		if state == "COMMENTED" {
 			continue
@@ -81,23 +129,31 @@ func (c *githubFetcher) getPullRequest(match matcher.Result, _ *config.PullReque
 		}
 	}
 
-	var author string
-	if rawPullRequest.User != nil && rawPullRequest.User.Login != nil {
-		author = *rawPullRequest.User.Login
-	}
-
 	var link string
 	if rawPullRequest.URL != nil {
 		link = *rawPullRequest.URL
 	}
 
+	var latestCommentTimestamp int64
+	if cfg.Notifications.NewReviewComments.IsEnabled() && slices.Contains(cfg.Notifications.NewReviewComments.Repos, repo) {
+		latestCommentTimestamp, err = c.getLatestReviewCommentTimestamp(ctx, project, repo, prNumber, author, reviews)
+		if err != nil {
+			return pr, errors.Wrap(err, "error while loading review comments from GitHub")
+		}
+	}
+
 	pr = pullRequest{
-		Name:      rawPullRequest.GetTitle(),
-		Status:    c.getStatus(rawPullRequest, inReview),
-		Author:    author,
-		Link:      link,
-		Branch:    rawPullRequest.GetHead().GetRef(),
-		Approvers: approvers,
+		Name:                          rawPullRequest.GetTitle(),
+		Status:                        c.getStatus(rawPullRequest, inReview),
+		BuildStatus:                   c.getBuildStatus(ctx, project, repo, rawPullRequest.GetHead().GetSHA()),
+		MergeBlocked:                  isMergeBlocked(rawPullRequest.GetMergeableState()),
+		Author:                        author,
+		Link:                          link,
+		Branch:                        rawPullRequest.GetHead().GetRef(),
+		Approvers:                     approvers,
+		Reviewers:                     reviewers,
+		CreatedAt:                     rawPullRequest.GetCreatedAt(),
+		LatestReviewCommentsTimestamp: latestCommentTimestamp,
 	}
 
 	return pr, nil
@@ -116,7 +172,152 @@ func (c *githubFetcher) getStatus(pr *github.PullRequest, inReview bool) prStatu
 	}
 }
 
+// getBuildStatus combines the check runs (GitHub Actions etc.) and the commit statuses (external CI) of the given commit
+func (c *githubFetcher) getBuildStatus(ctx context.Context, project string, repo string, sha string) buildStatus {
+	status := buildStatusUnknown
+	if sha == "" {
+		return status
+	}
+
+	checkRuns, _, err := c.client.Checks.ListCheckRunsForRef(ctx, project, repo, sha, &github.ListCheckRunsOptions{})
+	if err != nil {
+		log.Warnf("error while loading GitHub check runs of %s/%s: %s", project, repo, err)
+	} else {
+		for _, checkRun := range checkRuns.CheckRuns {
+			status = combineBuildStatus(status, getCheckRunStatus(checkRun))
+		}
+	}
+
+	combinedStatus, _, err := c.client.Repositories.GetCombinedStatus(ctx, project, repo, sha, &github.ListOptions{})
+	if err != nil {
+		log.Warnf("error while loading GitHub commit status of %s/%s: %s", project, repo, err)
+	} else if combinedStatus.GetTotalCount() > 0 {
+		status = combineBuildStatus(status, getCommitStatus(combinedStatus.GetState()))
+	}
+
+	return status
+}
+
+func getCheckRunStatus(checkRun *github.CheckRun) buildStatus {
+	if checkRun.GetStatus() != "completed" {
+		// "queued" or "in_progress"
+		return buildStatusRunning
+	}
+
+	switch checkRun.GetConclusion() {
+	case "success", "neutral", "skipped":
+		return buildStatusSuccess
+	case "failure", "timed_out", "cancelled", "action_required":
+		return buildStatusFailed
+	}
+
+	return buildStatusUnknown
+}
+
+func getCommitStatus(state string) buildStatus {
+	switch state {
+	case "success":
+		return buildStatusSuccess
+	case "pending":
+		return buildStatusRunning
+	case "failure", "error":
+		return buildStatusFailed
+	}
+
+	return buildStatusUnknown
+}
+
+// combineBuildStatus merges two build states: a running build wins over a failed one, which wins over a successful one
+func combineBuildStatus(current buildStatus, status buildStatus) buildStatus {
+	switch {
+	case current == buildStatusRunning || status == buildStatusRunning:
+		return buildStatusRunning
+	case current == buildStatusFailed || status == buildStatusFailed:
+		return buildStatusFailed
+	case current == buildStatusSuccess || status == buildStatusSuccess:
+		return buildStatusSuccess
+	}
+
+	return buildStatusUnknown
+}
+
+// isMergeBlocked checks the "mergeable_state" of GitHub, e.g. merge conflicts or a missing required review
+func isMergeBlocked(mergeableState string) bool {
+	switch mergeableState {
+	case "dirty", "blocked", "behind":
+		return true
+	}
+
+	// "clean", "unstable", "has_hooks" or "unknown" while GitHub is still calculating it
+	return false
+}
+
+// getLatestReviewCommentTimestamp returns the time (in ms) of the latest comment which was not written by the author
+func (c *githubFetcher) getLatestReviewCommentTimestamp(ctx context.Context, project string, repo string, prNumber int, author string, reviews []*github.PullRequestReview) (int64, error) {
+	var latestTimestamp int64
+
+	for _, review := range reviews {
+		if review.GetUser().GetLogin() == author || review.GetBody() == "" || review.SubmittedAt == nil {
+			continue
+		}
+		latestTimestamp = max(latestTimestamp, review.SubmittedAt.UnixMilli())
+	}
+
+	comments, _, err := c.client.PullRequests.ListComments(ctx, project, repo, prNumber, &github.PullRequestListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, comment := range comments {
+		if comment.GetUser().GetLogin() == author || comment.CreatedAt == nil {
+			continue
+		}
+		latestTimestamp = max(latestTimestamp, comment.CreatedAt.UnixMilli())
+	}
+
+	return latestTimestamp, nil
+}
+
+func (c *githubFetcher) merge(match matcher.Result, method string) error {
+	_, _, err := c.client.PullRequests.Merge(
+		context.Background(),
+		match.GetString("project"),
+		match.GetString("repo"),
+		match.GetInt("number"),
+		"",
+		&github.PullRequestOptions{MergeMethod: method},
+	)
+
+	return err
+}
+
+func (c *githubFetcher) getDiff(match matcher.Result) (string, string, error) {
+	ctx := context.Background()
+	project := match.GetString("project")
+	repo := match.GetString("repo")
+	prNumber := match.GetInt("number")
+
+	rawPullRequest, _, err := c.client.PullRequests.Get(ctx, project, repo, prNumber)
+	if err != nil {
+		return "", "", err
+	}
+
+	diff, _, err := c.client.PullRequests.GetRaw(ctx, project, repo, prNumber, github.RawOptions{Type: github.Diff})
+	if err != nil {
+		return "", "", err
+	}
+
+	return rawPullRequest.GetBody(), diff, nil
+}
+
 func (c *githubFetcher) GetTemplateFunction(cfg *config.PullRequest) template.FuncMap {
+	if c.host != githubHost {
+		// the template function is only available for github.com
+		return template.FuncMap{}
+	}
+
 	return template.FuncMap{
 		"githubPullRequest": func(project string, repo string, number string) (pullRequest, error) {
 			return c.getPullRequest(matcher.Result{
@@ -129,6 +330,19 @@ func (c *githubFetcher) GetTemplateFunction(cfg *config.PullRequest) template.Fu
 }
 
 func (c *githubFetcher) getHelp() []bot.Help {
+	if c.host != githubHost {
+		return []bot.Help{
+			{
+				Command:     "github enterprise pull request",
+				Description: "tracks the state of pull requests on " + c.host,
+				Category:    category,
+				Examples: []string{
+					c.host + "/project/repo/pull/1",
+				},
+			},
+		}
+	}
+
 	return []bot.Help{
 		{
 			Command:     "github pull request",
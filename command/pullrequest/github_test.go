@@ -1,7 +1,11 @@
 package pullrequest
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"regexp"
 	"testing"
 	"time"
 
@@ -111,3 +115,108 @@ func TestGithub(t *testing.T) {
 		assert.Equal(t, prStatusOpen, actual)
 	})
 }
+
+func TestGithubEnterprise(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/team/repo/pulls/12", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"title": "Add feature", "state": "open", "mergeable_state": "dirty", "user": {"login": "author"}, "head": {"ref": "feature/TEST-12", "sha": "abc123"}}`)
+	})
+	mux.HandleFunc("/api/v3/repos/team/repo/pulls/12/reviews", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[
+			{"state": "APPROVED", "user": {"login": "reviewer1"}, "submitted_at": "2024-01-01T10:00:00Z"},
+			{"state": "COMMENTED", "body": "looks good", "user": {"login": "reviewer2"}, "submitted_at": "2024-01-01T11:00:00Z"},
+			{"state": "COMMENTED", "body": "fixed", "user": {"login": "author"}, "submitted_at": "2024-01-01T13:00:00Z"}
+		]`)
+	})
+	mux.HandleFunc("/api/v3/repos/team/repo/pulls/12/comments", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[
+			{"body": "nit", "user": {"login": "reviewer1"}, "created_at": "2024-01-01T12:00:00Z"},
+			{"body": "done", "user": {"login": "author"}, "created_at": "2024-01-01T14:00:00Z"}
+		]`)
+	})
+	mux.HandleFunc("/api/v3/repos/team/repo/commits/abc123/check-runs", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"total_count": 2, "check_runs": [
+			{"status": "completed", "conclusion": "success"},
+			{"status": "completed", "conclusion": "failure"}
+		]}`)
+	})
+	mux.HandleFunc("/api/v3/repos/team/repo/commits/abc123/status", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"state": "success", "total_count": 1}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	slackClient := mocks.NewSlackClient(t)
+	base := bot.BaseCommand{SlackClient: slackClient}
+
+	cfg := &config.Config{}
+	cfg.PullRequest = config.DefaultConfig.PullRequest
+	cfg.PullRequest.Notifications.NewReviewComments = config.NewReviewComments{
+		Enabled: true,
+		Repos:   []string{"repo"},
+	}
+	cfg.Github.Enterprise = []config.GithubEnterprise{
+		{Host: server.URL, AccessToken: "secret"},
+	}
+
+	enterpriseCommands := newGithubEnterpriseCommands(base, cfg, nil)
+	require.Len(t, enterpriseCommands, 1)
+	cmd := enterpriseCommands[0].(command)
+
+	t.Run("match enterprise links", func(t *testing.T) {
+		re := regexp.MustCompile(cmd.regexp)
+		assert.True(t, re.MatchString(server.URL+"/team/repo/pull/12"))
+		assert.False(t, re.MatchString("https://github.com/team/repo/pull/12"))
+	})
+
+	t.Run("help", func(t *testing.T) {
+		help := cmd.GetHelp()
+		require.Len(t, help, 1)
+		assert.Equal(t, server.URL+"/project/repo/pull/1", help[0].Examples[0])
+		assert.Empty(t, cmd.GetTemplateFunction())
+	})
+
+	t.Run("fetch PR", func(t *testing.T) {
+		pr, err := cmd.fetcher.getPullRequest(matcher.Result{
+			"project": "team",
+			"repo":    "repo",
+			"number":  "12",
+		}, &cfg.PullRequest)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Add feature", pr.Name)
+		assert.Equal(t, prStatusInReview, pr.Status)
+		assert.Equal(t, buildStatusFailed, pr.BuildStatus)
+		assert.True(t, pr.MergeBlocked)
+		assert.Equal(t, []string{"reviewer1"}, pr.Approvers)
+		assert.Equal(t, "feature/TEST-12", pr.Branch)
+
+		// the comments of the author are ignored
+		expectedTimestamp := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC).UnixMilli()
+		assert.Equal(t, expectedTimestamp, pr.LatestReviewCommentsTimestamp)
+	})
+}
+
+func TestGithubBuildStatus(t *testing.T) {
+	running := "in_progress"
+	completed := "completed"
+	success := "success"
+	failure := "failure"
+
+	assert.Equal(t, buildStatusRunning, getCheckRunStatus(&github.CheckRun{Status: &running}))
+	assert.Equal(t, buildStatusSuccess, getCheckRunStatus(&github.CheckRun{Status: &completed, Conclusion: &success}))
+	assert.Equal(t, buildStatusFailed, getCheckRunStatus(&github.CheckRun{Status: &completed, Conclusion: &failure}))
+
+	assert.Equal(t, buildStatusRunning, getCommitStatus("pending"))
+	assert.Equal(t, buildStatusFailed, getCommitStatus("error"))
+	assert.Equal(t, buildStatusUnknown, getCommitStatus(""))
+
+	assert.Equal(t, buildStatusSuccess, combineBuildStatus(buildStatusUnknown, buildStatusSuccess))
+	assert.Equal(t, buildStatusFailed, combineBuildStatus(buildStatusSuccess, buildStatusFailed))
+	assert.Equal(t, buildStatusRunning, combineBuildStatus(buildStatusFailed, buildStatusRunning))
+	assert.Equal(t, buildStatusUnknown, combineBuildStatus(buildStatusUnknown, buildStatusUnknown))
+
+	assert.True(t, isMergeBlocked("dirty"))
+	assert.False(t, isMergeBlocked("clean"))
+	assert.False(t, isMergeBlocked("unknown"))
+}
@@ -4,6 +4,7 @@ import (
 	"regexp"
 	"strings"
 	"text/template"
+	"time"
 
 	gojira "github.com/andygrunwald/go-jira"
 	"github.com/innogames/slack-bot/v2/bot"
@@ -35,6 +36,7 @@ func newGitlabCommand(base bot.BaseCommand, cfg *config.Config, jiraClient *goji
 		&gitlabFetcher{gitlabClient},
 		"(?s).*" + regexp.QuoteMeta(cfg.Gitlab.Host) + "/(?P<repo>.+/.+)/merge_requests/(?P<number>\\d+).*",
 		jiraClient,
+		newSummarizer(cfg),
 	}
 }
 
@@ -95,6 +97,45 @@ func (c *gitlabFetcher) getApprovers(rawPullRequest *gitlab.MergeRequest, prNumb
 	return approvers
 }
 
+func (c *gitlabFetcher) merge(match matcher.Result, method string) error {
+	options := &gitlab.AcceptMergeRequestOptions{}
+	switch method {
+	case "squash":
+		options.Squash = gitlab.Ptr(true)
+	case "rebase":
+		// the rebase/fast-forward behavior is defined in the GitLab project settings
+		return errors.New("rebase is not supported for GitLab, use \"merge\" or \"squash\"")
+	}
+
+	repo := strings.TrimSuffix(match.GetString("repo"), "/-")
+	_, _, err := c.client.MergeRequests.AcceptMergeRequest(repo, int64(match.GetInt("number")), options)
+
+	return err
+}
+
+func (c *gitlabFetcher) getDiff(match matcher.Result) (string, string, error) {
+	repo := strings.TrimSuffix(match.GetString("repo"), "/-")
+	prNumber := int64(match.GetInt("number"))
+
+	rawPullRequest, _, err := c.client.MergeRequests.GetMergeRequest(repo, prNumber, &gitlab.GetMergeRequestsOptions{})
+	if err != nil {
+		return "", "", err
+	}
+
+	diffs, _, err := c.client.MergeRequests.ListMergeRequestDiffs(repo, prNumber, &gitlab.ListMergeRequestDiffsOptions{})
+	if err != nil {
+		return "", "", err
+	}
+
+	var diff strings.Builder
+	for _, fileDiff := range diffs {
+		diff.WriteString("--- " + fileDiff.OldPath + "\n+++ " + fileDiff.NewPath + "\n")
+		diff.WriteString(fileDiff.Diff)
+	}
+
+	return rawPullRequest.Description, diff.String(), nil
+}
+
 func (c *gitlabFetcher) GetTemplateFunction(cfg *config.PullRequest) template.FuncMap {
 	return template.FuncMap{
 		"gitlabPullRequest": func(repo string, number string) (pullRequest, error) {
@@ -129,7 +170,26 @@ func (c *gitlabFetcher) convertToPullRequest(rawPullRequest *gitlab.MergeRequest
 		Link:        rawPullRequest.WebURL,
 		Branch:      rawPullRequest.SourceBranch,
 		BuildStatus: c.getPipelineStatus(rawPullRequest),
+		Reviewers:   c.getReviewers(rawPullRequest),
+		CreatedAt:   getTime(rawPullRequest.CreatedAt),
+	}
+}
+
+func (c *gitlabFetcher) getReviewers(rawPullRequest *gitlab.MergeRequest) []string {
+	var reviewers []string
+	for _, reviewer := range rawPullRequest.Reviewers {
+		reviewers = append(reviewers, reviewer.Username)
+	}
+
+	return reviewers
+}
+
+func getTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
 	}
+
+	return *t
 }
 
 // getPipelineStatus will convert the Pipeline.Status into a buildStatus
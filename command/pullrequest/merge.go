@@ -0,0 +1,145 @@
+package pullrequest
+
+import (
+	"fmt"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+// merger is implemented by all fetchers which are able to merge a PR via the API
+type merger interface {
+	merge(match matcher.Result, method string) error
+}
+
+type mergeCommand struct {
+	bot.BaseCommand
+	cfg        config.PullRequest
+	adminUsers config.UserList
+
+	// the PR watchers, used to detect the provider of the given PR link
+	commands []command
+}
+
+// newMergeCommand merges PRs of the allowed repositories via "merge pr <url>"
+func newMergeCommand(base bot.BaseCommand, cfg *config.Config, prCommands []bot.Command) bot.Command {
+	return &mergeCommand{base, cfg.PullRequest, cfg.AdminUsers, getWatchCommands(prCommands)}
+}
+
+func (c *mergeCommand) IsEnabled() bool {
+	return c.cfg.Merge.IsEnabled()
+}
+
+func (c *mergeCommand) GetMatcher() matcher.Matcher {
+	return matcher.NewRegexpMatcher(`merge pr (?P<url>\S+)( (?P<method>merge|squash|rebase))?`, c.merge)
+}
+
+func (c *mergeCommand) merge(match matcher.Result, message msg.Message) {
+	url := getPlainURL(match.GetString("url"))
+
+	method := match.GetString("method")
+	if method == "" {
+		method = c.cfg.Merge.GetMethod()
+	}
+
+	cmd, prMatch := findWatchCommand(c.commands, url)
+	if prMatch == nil {
+		c.ReplyError(message, fmt.Errorf("unsupported pull request link: %s", url))
+		return
+	}
+
+	repo := getMatchRepo(prMatch)
+	if !c.cfg.Merge.IsAllowedRepo(repo) {
+		c.ReplyError(message, fmt.Errorf("merging pull requests of %s is not allowed", repo))
+		return
+	}
+
+	prMerger, ok := cmd.fetcher.(merger)
+	if !ok {
+		c.ReplyError(message, errors.New("merging is not supported for this provider"))
+		return
+	}
+
+	pr, err := cmd.fetcher.getPullRequest(prMatch, &c.cfg)
+	if err != nil {
+		c.ReplyError(message, err)
+		return
+	}
+
+	if err = c.checkMergeable(pr, message); err != nil {
+		c.ReplyError(message, err)
+		return
+	}
+
+	if err = prMerger.merge(prMatch, method); err != nil {
+		c.ReplyError(message, errors.Wrap(err, "error while merging the PR"))
+		return
+	}
+
+	// update the reactions of a watched PR directly
+	watchers.trigger(getMatchWatchKey(prMatch))
+
+	c.SendMessage(message, fmt.Sprintf("merged PR *%s* (%s) :%s:", pr.Name, method, c.cfg.Reactions.Merged.ToSlackReaction()))
+}
+
+// checkMergeable checks the permissions of the user and the approvals/build status of the PR
+func (c *mergeCommand) checkMergeable(pr pullRequest, message msg.Message) error {
+	// only the author of the PR or an admin is allowed to merge it
+	if !isSlackUser(c.cfg, pr.Author, message.GetUser()) && !c.adminUsers.Contains(message.GetUser()) {
+		return errors.New("only the author of the PR or an admin is allowed to merge it")
+	}
+
+	switch {
+	case pr.Status == prStatusMerged || pr.Status == prStatusClosed:
+		return errors.New("the PR is already merged or closed")
+	case len(pr.Approvers) == 0:
+		return errors.New("the PR is not approved yet")
+	case pr.BuildStatus == buildStatusFailed:
+		return errors.New("the build of the PR failed")
+	case pr.BuildStatus == buildStatusRunning:
+		return errors.New("the build of the PR is still running")
+	case pr.MergeBlocked:
+		return errors.New("the PR can't be merged right now, e.g. because of merge conflicts")
+	}
+
+	return nil
+}
+
+// canMerge checks if the "Merge" button should be shown for the watched PR
+func canMerge(cfg config.PullRequest, fetcher fetcher, match matcher.Result) bool {
+	if _, ok := fetcher.(merger); !ok || match == nil {
+		return false
+	}
+
+	return cfg.Merge.IsEnabled() && cfg.Merge.IsAllowedRepo(getMatchRepo(match))
+}
+
+// getMergeBlocks returns the "ready to merge" notification with a "Merge" button
+func getMergeBlocks(text string, url string) []slack.Block {
+	return []slack.Block{
+		client.GetTextBlock(text),
+		slack.NewActionBlock(
+			"",
+			client.GetInteractionButton("merge", "Merge", "merge pr "+url, slack.StylePrimary),
+		),
+	}
+}
+
+func (c *mergeCommand) GetHelp() []bot.Help {
+	return []bot.Help{
+		{
+			Command:     "merge pr <url> [merge|squash|rebase]",
+			Description: "merges an approved pull request with a successful build (only for the author or admins)",
+			Category:    category,
+			Examples: []string{
+				"merge pr https://github.com/innogames/slack-bot/pull/1",
+				"merge pr https://github.com/innogames/slack-bot/pull/1 squash",
+			},
+		},
+	}
+}
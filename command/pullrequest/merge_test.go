@@ -0,0 +1,178 @@
+package pullrequest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	client.AllUsers = config.UserMap{
+		"U1": "alice",
+		"U2": "bob",
+		"U3": "admin",
+	}
+
+	var mergeMethods []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/team/repo/pulls/12", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"title": "Add feature", "state": "open", "mergeable_state": "clean", "user": {"login": "octo-alice"}, "head": {"sha": "abc123"}}`)
+	})
+	mux.HandleFunc("/api/v3/repos/team/repo/pulls/13", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"title": "Fix bug", "state": "open", "user": {"login": "octo-alice"}, "head": {"sha": "def456"}}`)
+	})
+	mux.HandleFunc("/api/v3/repos/team/repo/pulls/12/reviews", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"state": "APPROVED", "user": {"login": "bob"}}]`)
+	})
+	mux.HandleFunc("/api/v3/repos/team/repo/pulls/13/reviews", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/api/v3/repos/team/repo/commits/", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"total_count": 0}`)
+	})
+	mux.HandleFunc("PUT /api/v3/repos/team/repo/pulls/12/merge", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			MergeMethod string `json:"merge_method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mergeMethods = append(mergeMethods, body.MergeMethod)
+		fmt.Fprint(w, `{"merged": true}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	slackClient := mocks.NewSlackClient(t)
+	base := bot.BaseCommand{SlackClient: slackClient}
+
+	cfg := &config.Config{}
+	cfg.AdminUsers = config.UserList{"U3"}
+	cfg.PullRequest = config.DefaultConfig.PullRequest
+	cfg.PullRequest.UserMapping = map[string]string{"octo-alice": "alice"}
+	cfg.PullRequest.Merge = config.PullRequestMerge{
+		Repos: []string{"team/repo"},
+	}
+	cfg.Github.Enterprise = []config.GithubEnterprise{
+		{Host: server.URL, AccessToken: "secret"},
+	}
+
+	prCommands := newGithubEnterpriseCommands(base, cfg, nil)
+	commands := bot.Commands{}
+	commands.AddCommand(newMergeCommand(base, cfg, prCommands))
+
+	prURL := server.URL + "/team/repo/pull/12"
+
+	t.Run("disabled", func(t *testing.T) {
+		disabledCfg := &config.Config{}
+		cmd := newMergeCommand(base, disabledCfg, prCommands).(*mergeCommand)
+		assert.False(t, cmd.IsEnabled())
+	})
+
+	t.Run("unsupported link", func(t *testing.T) {
+		message := msg.Message{}
+		message.User = "U1"
+		message.Text = "merge pr https://example.com/foo"
+
+		mocks.AssertError(slackClient, message, "unsupported pull request link: https://example.com/foo")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("repo not allowed", func(t *testing.T) {
+		message := msg.Message{}
+		message.User = "U1"
+		message.Text = "merge pr " + server.URL + "/team/other/pull/1"
+
+		mocks.AssertError(slackClient, message, "merging pull requests of team/other is not allowed")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("not the author", func(t *testing.T) {
+		message := msg.Message{}
+		message.User = "U2"
+		message.Text = "merge pr " + prURL
+
+		mocks.AssertError(slackClient, message, "only the author of the PR or an admin is allowed to merge it")
+		assert.True(t, commands.Run(message))
+		assert.Empty(t, mergeMethods)
+	})
+
+	t.Run("not approved", func(t *testing.T) {
+		message := msg.Message{}
+		message.User = "U1"
+		message.Text = "merge pr " + server.URL + "/team/repo/pull/13"
+
+		mocks.AssertError(slackClient, message, "the PR is not approved yet")
+		assert.True(t, commands.Run(message))
+		assert.Empty(t, mergeMethods)
+	})
+
+	t.Run("merge by author", func(t *testing.T) {
+		message := msg.Message{}
+		message.User = "U1"
+		message.Text = "merge pr <" + prURL + ">"
+
+		mocks.AssertSlackMessage(slackClient, message, "merged PR *Add feature* (merge) :twisted_rightwards_arrows:")
+		assert.True(t, commands.Run(message))
+		assert.Equal(t, []string{"merge"}, mergeMethods)
+	})
+
+	t.Run("squash by admin", func(t *testing.T) {
+		message := msg.Message{}
+		message.User = "U3"
+		message.Text = "merge pr " + prURL + " squash"
+
+		mocks.AssertSlackMessage(slackClient, message, "merged PR *Add feature* (squash) :twisted_rightwards_arrows:")
+		assert.True(t, commands.Run(message))
+		assert.Equal(t, []string{"merge", "squash"}, mergeMethods)
+	})
+
+	t.Run("merge button", func(t *testing.T) {
+		cmd := prCommands[0].(command)
+		match := matcher.Result{"project": "team", "repo": "repo", "number": "12"}
+		assert.True(t, canMerge(cmd.cfg, cmd.fetcher, match))
+		assert.False(t, canMerge(cmd.cfg, cmd.fetcher, matcher.Result{"project": "team", "repo": "other", "number": "1"}))
+
+		// the message contains other links as well, only the matched PR link is used for the button
+		message := msg.Message{}
+		message.Text = "see <https://example.com/first-link> and please review <" + prURL + "> thanks"
+		_, match = cmd.GetMatcher().Match(message)
+		require.NotNil(t, match)
+
+		cmd.cfg.Notifications.PullRequestStatusMergeable = true
+		prw := &pullRequestWatch{
+			Author: "U1",
+			Match:  match,
+			URL:    cmd.getPullRequestURL(message.Text),
+			PullRequest: pullRequest{
+				Name:        "Add feature",
+				Author:      "octo-alice",
+				Approvers:   []string{"bob"},
+				BuildStatus: buildStatusSuccess,
+			},
+		}
+
+		blocks := getMergeBlocks("Your PR 'Add feature' is ready to merge!", prURL)
+		require.Len(t, blocks, 2)
+		slackClient.On("SendBlockMessageToUser", "U1", blocks).Once().Return("")
+		cmd.notifyPullRequestStatus(prw)
+		assert.True(t, prw.DidNotifyMergeable)
+
+		// the PR was posted by a different user, who is not allowed to merge it
+		prw.Author = "U2"
+		prw.DidNotifyMergeable = false
+		slackClient.On("SendToUser", "U2", "Your PR 'Add feature' is ready to merge!").Once().Return("")
+		cmd.notifyPullRequestStatus(prw)
+		assert.True(t, prw.DidNotifyMergeable)
+	})
+}
@@ -65,6 +65,9 @@ type command struct {
 	// optional Jira client, used to resolve the priority/severity of the referenced ticket.
 	// nil when Jira is not configured.
 	jira *gojira.Client
+
+	// optional AI summary of the PR, nil when openai is not configured
+	summarizer *summarizer
 }
 
 type pullRequest struct {
@@ -75,6 +78,9 @@ type pullRequest struct {
 	// status of a related CI build
 	BuildStatus buildStatus
 
+	// the PR can't be merged right now, e.g. because of merge conflicts or missing required reviews
+	MergeBlocked bool
+
 	// author of the PR
 	Author string
 
@@ -87,6 +93,12 @@ type pullRequest struct {
 	// list of usernames which approved the PR
 	Approvers []string
 
+	// list of usernames which are requested to review the PR
+	Reviewers []string
+
+	// creation time of the PR, zero if unknown
+	CreatedAt time.Time
+
 	LatestReviewCommentsTimestamp int64
 }
 
@@ -102,6 +114,10 @@ func (c command) execute(match matcher.Result, message msg.Message) {
 		return
 	}
 
+	if c.summarizer.isAutoEnabled(match) && markSummaryPosted(message.GetChannel(), getMatchWatchKey(match)) {
+		go c.summarizer.postAutoSummary(c.BaseCommand, c.fetcher, match, &c.cfg, message)
+	}
+
 	go c.watch(match, message)
 }
 
@@ -116,6 +132,21 @@ type pullRequestWatch struct {
 	// reaction representing the Jira priority/severity of the PR; resolved once per watcher
 	SeverityReaction util.Reaction
 	severityResolved bool
+
+	// the matched PR, URL is the link of the PR within the message, used for the "Merge" button
+	Match matcher.Result
+	URL   string
+}
+
+// getPullRequestURL returns the link of the message which is matched by the watcher, as the message might contain other links as well
+func (c command) getPullRequestURL(text string) string {
+	for _, url := range prURLRegexp.FindAllString(text, -1) {
+		if _, match := findWatchCommand([]command{c}, url); match != nil {
+			return url
+		}
+	}
+
+	return ""
 }
 
 func (c command) watch(match matcher.Result, message msg.Message) {
@@ -132,6 +163,19 @@ func (c command) watch(match matcher.Result, message msg.Message) {
 	var err error
 
 	prw.Author = message.GetUser()
+	prw.Match = match
+	prw.URL = c.getPullRequestURL(message.GetText())
+
+	// the webhook receiver triggers an immediate refresh when the PR got updated
+	watchKey := getMatchWatchKey(match)
+	refresh := watchers.register(watchKey)
+	defer watchers.unregister(watchKey, refresh)
+	defer deleteSummaryMarker(message.GetChannel(), watchKey)
+
+	// the latest state is stored for the "list prs" dashboard
+	watchedSince := time.Now()
+	dashboardKey := getDashboardKey(message, match)
+	defer deleteWatchedPullRequest(dashboardKey)
 
 	for {
 		prw.PullRequest, err = c.fetcher.getPullRequest(match, &c.cfg)
@@ -161,6 +205,8 @@ func (c command) watch(match matcher.Result, message msg.Message) {
 		}
 		currentErrorCount = 0
 
+		storeWatchedPullRequest(dashboardKey, prw.URL, message, prw.PullRequest, watchedSince)
+
 		// resolve the Jira priority once: it's stable for the PR's lifetime
 		if !prw.severityResolved {
 			prw.SeverityReaction = c.getSeverityReaction(prw.PullRequest)
@@ -184,7 +230,24 @@ func (c command) watch(match matcher.Result, message msg.Message) {
 			return
 		}
 
-		time.Sleep(delay.GetNextDelay())
+		c.waitForUpdate(refresh, delay)
+	}
+}
+
+// waitForUpdate blocks until the next poll or until the webhook receiver got an update of the PR
+func (c command) waitForUpdate(refresh <-chan struct{}, delay util.IncreasingDelay) {
+	nextDelay := delay.GetNextDelay()
+	if c.cfg.Webhook.IsEnabled() {
+		// polling is just a slow fallback when webhooks are enabled
+		nextDelay = c.cfg.Webhook.GetFallbackInterval()
+	}
+
+	timer := time.NewTimer(nextDelay)
+	defer timer.Stop()
+
+	select {
+	case <-refresh:
+	case <-timer.C:
 	}
 }
 
@@ -427,14 +490,27 @@ func (c command) notifyPullRequestStatus(prw *pullRequestWatch) {
 		return
 	}
 
+	if prw.PullRequest.MergeBlocked {
+		// e.g. merge conflicts
+		return
+	}
+
 	prw.DidNotifyMergeable = true
 
+	// only the VCS author of the PR is allowed to merge it, so the button is only sent when the author posted the PR
+	if prw.URL != "" && canMerge(c.cfg, c.fetcher, prw.Match) && isSlackUser(c.cfg, prw.PullRequest.Author, prw.Author) {
+		text := fmt.Sprintf("Your PR '%s' is ready to merge!%s", prw.PullRequest.Name, getPRLinkMessage(prw))
+		c.SendBlockMessageToUser(prw.Author, getMergeBlocks(text, prw.URL))
+		return
+	}
+
 	c.sendPrivateMessagef(prw.Author, "Your PR '%s' is ready to merge!%s", prw.PullRequest.Name, getPRLinkMessage(prw))
 }
 
+// sendPrivateMessagef sends a DM to the user. VCS usernames are resolved via the "user_mapping" config
 func (c command) sendPrivateMessagef(username string, format string, parameter ...any) {
 	message := fmt.Sprintf(format, parameter...)
-	c.SendToUser(username, message)
+	c.SendToUser(c.cfg.GetSlackUser(username), message)
 }
 
 func (c command) GetTemplateFunction() template.FuncMap {
@@ -40,9 +40,9 @@ func TestGetCommands(t *testing.T) {
 
 	cfg := &config.Config{}
 
-	// as we pass a empty config, no PR fetcher is able to register -> 0 valid commands
+	// as we pass a empty config, only the GitHub fetcher and the "list prs" command are registered
 	commands := GetCommands(base, cfg)
-	assert.Equal(t, 1, commands.Count())
+	assert.Equal(t, 2, commands.Count())
 }
 
 func TestPullRequest(t *testing.T) {
@@ -170,6 +170,7 @@ func initTest(slackClient client.SlackClient) (bot.Commands, *testFetcher) {
 		fetcher,
 		".*/projects/(?P<project>.+)/repos/(?P<repo>.+)/pull-requests/(?P<number>\\d+).*",
 		nil,
+		nil,
 	}
 	commands.AddCommand(cmd)
 
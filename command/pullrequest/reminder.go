@@ -0,0 +1,264 @@
+package pullrequest
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/innogames/slack-bot/v2/client"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	reminderStorageKey = "pr_reminders"
+	optOutStorageKey   = "pr_reminders_opt_out"
+)
+
+// reminderCheckInterval is the interval to check all watched PRs for due reminders
+var reminderCheckInterval = 5 * time.Minute
+
+type reminderCommand struct {
+	bot.BaseCommand
+	cfg config.PullRequest
+}
+
+// newReminderCommand sends reminders for watched PRs which are waiting too long, based on the "reminders" config
+func newReminderCommand(base bot.BaseCommand, cfg config.PullRequest) bot.Command {
+	return &reminderCommand{base, cfg}
+}
+
+func (c *reminderCommand) IsEnabled() bool {
+	return c.cfg.Reminders.IsEnabled()
+}
+
+func (c *reminderCommand) GetMatcher() matcher.Matcher {
+	return matcher.NewRegexpMatcher(`(?P<action>enable|disable) pr reminders`, c.toggle)
+}
+
+// toggle enables/disables the "thread" reminders of the current channel
+func (c *reminderCommand) toggle(match matcher.Result, message msg.Message) {
+	var err error
+	if match.GetString("action") == "disable" {
+		err = storage.Write(optOutStorageKey, message.GetChannel(), true)
+	} else {
+		err = storage.Delete(optOutStorageKey, message.GetChannel())
+	}
+
+	if err != nil {
+		c.ReplyError(message, err)
+		return
+	}
+
+	c.SendMessage(message, fmt.Sprintf("PR reminders are %sd for this channel", match.GetString("action")))
+}
+
+// RunAsync checks all watched PRs periodically
+func (c *reminderCommand) RunAsync(ctx *util.ServerContext) {
+	ctx.RegisterChild()
+	defer ctx.ChildDone()
+
+	ticker := time.NewTicker(reminderCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.sendReminders(time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sendReminders sends all due reminders of the watched PRs
+func (c *reminderCommand) sendReminders(now time.Time) {
+	if !isWorkingTime(c.cfg.Reminders, now) {
+		return
+	}
+
+	keys, _ := storage.GetKeys(dashboardStorageKey)
+	c.cleanupReminders(keys)
+
+	for _, key := range keys {
+		var watched watchedPullRequest
+		if err := storage.Read(dashboardStorageKey, key, &watched); err != nil {
+			continue
+		}
+
+		sent := make(map[string]time.Time)
+		_ = storage.Read(reminderStorageKey, key, &sent)
+
+		changed := false
+		for i, rule := range c.cfg.Reminders.Rules {
+			since, ok := getReminderStart(rule, watched)
+			if !ok || now.Sub(since) < rule.After {
+				continue
+			}
+
+			ruleKey := strconv.Itoa(i)
+			if last, ok := sent[ruleKey]; ok && !last.Before(since) && (rule.Repeat == 0 || now.Sub(last) < rule.Repeat) {
+				continue
+			}
+
+			c.notify(rule, watched, now.Sub(since))
+			sent[ruleKey] = now
+			changed = true
+		}
+
+		if changed {
+			if err := storage.Write(reminderStorageKey, key, sent); err != nil {
+				log.Warnf("error while storing PR reminders: %s", err)
+			}
+		}
+	}
+}
+
+// cleanupReminders removes the reminder state of PRs which are not watched anymore
+func (c *reminderCommand) cleanupReminders(watchedKeys []string) {
+	keys, _ := storage.GetKeys(reminderStorageKey)
+	for _, key := range keys {
+		if !slices.Contains(watchedKeys, key) {
+			_ = storage.Delete(reminderStorageKey, key)
+		}
+	}
+}
+
+// getReminderStart returns the time since when the rule applies to the PR
+func getReminderStart(rule config.ReminderRule, watched watchedPullRequest) (time.Time, bool) {
+	status := watched.PullRequest.Status
+
+	switch rule.Status {
+	case "in_review":
+		if status != prStatusInReview || len(watched.PullRequest.Approvers) > 0 {
+			return time.Time{}, false
+		}
+		if watched.StatusSince.IsZero() {
+			return watched.WatchedSince, true
+		}
+		return watched.StatusSince, true
+	case "open":
+		if status != prStatusOpen && status != prStatusInReview {
+			return time.Time{}, false
+		}
+		return watched.getCreatedAt(), true
+	}
+
+	return time.Time{}, false
+}
+
+func (c *reminderCommand) notify(rule config.ReminderRule, watched watchedPullRequest, waiting time.Duration) {
+	pr := watched.PullRequest
+	link := fmt.Sprintf("<%s|%s>", watched.URL, pr.Name)
+	duration := util.FormatDuration(waiting.Round(time.Minute))
+
+	switch rule.Notify {
+	case "reviewers":
+		for _, reviewer := range watched.getWaitingReviewers() {
+			c.SendToUser(
+				c.cfg.GetSlackUser(reviewer),
+				fmt.Sprintf("Reminder: the pull request %s is waiting for your review since %s", link, duration),
+			)
+		}
+	case "author":
+		author := watched.Message.User
+		if pr.Author != "" {
+			author = c.cfg.GetSlackUser(pr.Author)
+		}
+		if author == "" {
+			return
+		}
+		c.SendToUser(author, fmt.Sprintf("Reminder: your pull request %s is open since %s", link, duration))
+	case "thread":
+		if c.isOptedOut(watched.Message.Channel) {
+			return
+		}
+
+		text := fmt.Sprintf("Reminder: this pull request is waiting since %s", duration)
+		if waitingFor := watched.getWaitingReviewers(); len(waitingFor) > 0 {
+			mentions := make([]string, 0, len(waitingFor))
+			for _, reviewer := range waitingFor {
+				mentions = append(mentions, c.getMention(reviewer))
+			}
+			text += ", waiting for: " + strings.Join(mentions, ", ")
+		}
+
+		// the reminder is posted in the thread of the PR message, which might be a thread reply itself
+		threadTS := watched.Message.GetThread()
+		if threadTS == "" {
+			threadTS = watched.Message.GetTimestamp()
+		}
+		c.SendMessage(watched.Message, text, slack.MsgOptionTS(threadTS))
+	default:
+		log.Warnf("invalid notify target in PR reminder config: %s", rule.Notify)
+	}
+}
+
+// getMention returns a Slack mention of the VCS user, or the plain name if there is no matching Slack user
+func (c *reminderCommand) getMention(vcsUser string) string {
+	userID, _ := client.GetUserIDAndName(c.cfg.GetSlackUser(vcsUser))
+	if userID == "" {
+		return vcsUser
+	}
+
+	return "<@" + userID + ">"
+}
+
+func (c *reminderCommand) isOptedOut(channel string) bool {
+	var optedOut bool
+	_ = storage.Read(optOutStorageKey, channel, &optedOut)
+
+	return optedOut
+}
+
+// isWorkingTime checks the configured working days and working hours, like "09:00-18:00"
+func isWorkingTime(cfg config.PullRequestReminders, now time.Time) bool {
+	if !slices.ContainsFunc(cfg.GetWorkingDays(), func(day string) bool {
+		return strings.EqualFold(day, now.Weekday().String())
+	}) {
+		return false
+	}
+
+	if cfg.WorkingHours == "" {
+		return true
+	}
+
+	from, to, found := strings.Cut(cfg.WorkingHours, "-")
+	if !found {
+		log.Warnf("invalid working_hours in PR reminder config: %s", cfg.WorkingHours)
+		return true
+	}
+
+	fromTime, errFrom := time.Parse("15:04", strings.TrimSpace(from))
+	toTime, errTo := time.Parse("15:04", strings.TrimSpace(to))
+	if errFrom != nil || errTo != nil {
+		log.Warnf("invalid working_hours in PR reminder config: %s", cfg.WorkingHours)
+		return true
+	}
+
+	minutes := now.Hour()*60 + now.Minute()
+
+	return minutes >= fromTime.Hour()*60+fromTime.Minute() && minutes < toTime.Hour()*60+toTime.Minute()
+}
+
+func (c *reminderCommand) GetHelp() []bot.Help {
+	return []bot.Help{
+		{
+			Command:     "disable pr reminders",
+			Description: "disables/enables the reminders for waiting pull requests in the current channel",
+			Category:    category,
+			Examples: []string{
+				"disable pr reminders",
+				"enable pr reminders",
+			},
+		},
+	}
+}
@@ -0,0 +1,168 @@
+package pullrequest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/client"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIsWorkingTime(t *testing.T) {
+	cfg := config.PullRequestReminders{WorkingHours: "09:00-18:00"}
+
+	// 2026-10-19 is a Monday
+	assert.True(t, isWorkingTime(cfg, time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)))
+	assert.True(t, isWorkingTime(cfg, time.Date(2026, 10, 19, 17, 59, 0, 0, time.UTC)))
+	assert.False(t, isWorkingTime(cfg, time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC)))
+	assert.False(t, isWorkingTime(cfg, time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)))
+
+	// saturday
+	assert.False(t, isWorkingTime(cfg, time.Date(2026, 10, 24, 12, 0, 0, 0, time.UTC)))
+
+	cfg.WorkingDays = []string{"saturday"}
+	cfg.WorkingHours = ""
+	assert.True(t, isWorkingTime(cfg, time.Date(2026, 10, 24, 23, 0, 0, 0, time.UTC)))
+	assert.False(t, isWorkingTime(cfg, time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestGetSlackUser(t *testing.T) {
+	cfg := config.PullRequest{
+		UserMapping: map[string]string{"octocat": "alice"},
+	}
+
+	assert.Equal(t, "alice", cfg.GetSlackUser("OctoCat"))
+	assert.Equal(t, "bob", cfg.GetSlackUser("bob"))
+}
+
+func TestReminders(t *testing.T) {
+	storage.InitStorage("")
+	client.AllUsers = config.UserMap{
+		"U1": "alice",
+		"U2": "bob",
+	}
+
+	slackClient := mocks.NewSlackClient(t)
+	base := bot.BaseCommand{SlackClient: slackClient}
+
+	cfg := config.PullRequest{
+		UserMapping: map[string]string{"octo-bob": "bob"},
+		Reminders: config.PullRequestReminders{
+			Rules: []config.ReminderRule{
+				{Status: "in_review", After: time.Hour, Notify: "reviewers"},
+				{Status: "open", After: 24 * time.Hour, Notify: "thread", Repeat: 24 * time.Hour},
+				{Status: "open", After: 48 * time.Hour, Notify: "author"},
+			},
+		},
+	}
+
+	command := newReminderCommand(base, cfg).(*reminderCommand)
+	assert.True(t, command.IsEnabled())
+	assert.False(t, newReminderCommand(base, config.PullRequest{}).(*reminderCommand).IsEnabled())
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.Local)
+
+	message := msg.Message{}
+	message.Channel = "C1"
+	message.User = "U1"
+	message.Timestamp = "1000.0001"
+
+	watched := watchedPullRequest{
+		PullRequest: pullRequest{
+			Name:      "Fix login",
+			Author:    "octo-alice",
+			Status:    prStatusInReview,
+			Reviewers: []string{"octo-bob"},
+			CreatedAt: now.Add(-30 * time.Hour),
+		},
+		URL:          "https://github.com/innogames/slack-bot/pull/1",
+		Message:      message.MessageRef,
+		WatchedSince: now.Add(-30 * time.Hour),
+		StatusSince:  now.Add(-2 * time.Hour),
+	}
+	_ = storage.Write(dashboardStorageKey, "pr1", watched)
+	_ = storage.Write(reminderStorageKey, "stale", map[string]time.Time{"0": now})
+	defer deleteWatchedPullRequest("pr1")
+
+	t.Run("send due reminders", func(t *testing.T) {
+		slackClient.On("SendToUser", "bob", "Reminder: the pull request <https://github.com/innogames/slack-bot/pull/1|Fix login> is waiting for your review since 2h0m0s").Once()
+		slackClient.On("SendMessage", message.MessageRef, "Reminder: this pull request is waiting since 1d6h0m0s, waiting for: <@U2>", mock.Anything).Once().Return("")
+
+		command.sendReminders(now)
+
+		// already sent -> no new reminders
+		command.sendReminders(now.Add(time.Hour))
+
+		keys, _ := storage.GetKeys(reminderStorageKey)
+		assert.Equal(t, []string{"pr1"}, keys)
+	})
+
+	t.Run("repeat and author reminder", func(t *testing.T) {
+		slackClient.On("SendMessage", message.MessageRef, "Reminder: this pull request is waiting since 2d6h0m0s, waiting for: <@U2>", mock.Anything).Once().Return("")
+		slackClient.On("SendToUser", "octo-alice", "Reminder: your pull request <https://github.com/innogames/slack-bot/pull/1|Fix login> is open since 2d6h0m0s").Once()
+
+		command.sendReminders(now.Add(24 * time.Hour))
+	})
+
+	t.Run("no reminders outside of working time", func(t *testing.T) {
+		// saturday
+		command.sendReminders(time.Date(2026, 10, 24, 12, 0, 0, 0, time.Local))
+	})
+
+	t.Run("opt out", func(t *testing.T) {
+		disable := msg.Message{}
+		disable.Channel = "C1"
+		disable.Text = "disable pr reminders"
+
+		commands := bot.Commands{}
+		commands.AddCommand(command)
+
+		mocks.AssertSlackMessage(slackClient, disable, "PR reminders are disabled for this channel")
+		assert.True(t, commands.Run(disable))
+		assert.True(t, command.isOptedOut("C1"))
+
+		// the repeated thread reminder is skipped
+		command.sendReminders(now.Add(48 * time.Hour))
+
+		enable := disable
+		enable.Text = "enable pr reminders"
+		mocks.AssertSlackMessage(slackClient, enable, "PR reminders are enabled for this channel")
+		assert.True(t, commands.Run(enable))
+		assert.False(t, command.isOptedOut("C1"))
+	})
+
+	t.Run("PR posted in a thread", func(t *testing.T) {
+		threadWatched := watched
+		threadWatched.Message.Thread = "999.0001"
+
+		slackClient.On("SendMessage", threadWatched.Message, "Reminder: this pull request is waiting since 1h0m0s, waiting for: <@U2>", mock.MatchedBy(func(option slack.MsgOption) bool {
+			_, values, _ := slack.UnsafeApplyMsgOptions("", "C1", "", option)
+			return values.Get("thread_ts") == "999.0001"
+		})).Once().Return("")
+
+		command.notify(cfg.Reminders.Rules[1], threadWatched, time.Hour)
+	})
+
+	t.Run("approved PR", func(t *testing.T) {
+		watched.PullRequest.Approvers = []string{"octo-bob"}
+		watched.PullRequest.Status = prStatusInReview
+
+		_, ok := getReminderStart(cfg.Reminders.Rules[0], watched)
+		assert.False(t, ok)
+
+		since, ok := getReminderStart(cfg.Reminders.Rules[1], watched)
+		assert.True(t, ok)
+		assert.Equal(t, watched.PullRequest.CreatedAt, since)
+
+		watched.PullRequest.Status = prStatusMerged
+		_, ok = getReminderStart(cfg.Reminders.Rules[1], watched)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,183 @@
+package pullrequest
+
+import (
+	"fmt"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/command/openai"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const summarySystemMessage = `You are a senior software engineer who summarizes pull requests for the team in Slack.
+Answer in Slack markdown with exactly three short sections:
+*Summary*: what the PR changes and why, in 1-3 sentences
+*Risks*: bullet points of possible risks or breaking changes, or "none"
+*Touched areas*: bullet points of the affected components or directories`
+
+// diffFetcher is implemented by all fetchers which are able to load the description and the diff of a PR
+type diffFetcher interface {
+	getDiff(match matcher.Result) (description string, diff string, err error)
+}
+
+// summarizer generates a summary of a PR via ChatGPT
+type summarizer struct {
+	cfg       config.PullRequestSummary
+	openaiCfg openai.Config
+}
+
+// newSummarizer returns nil if openai is not configured
+func newSummarizer(cfg *config.Config) *summarizer {
+	openaiCfg := openai.LoadConfig(cfg)
+	if !openaiCfg.IsEnabled() {
+		return nil
+	}
+
+	return &summarizer{cfg.PullRequest.Summary, openaiCfg}
+}
+
+// isAutoEnabled checks if the posted PR gets a summary automatically
+func (s *summarizer) isAutoEnabled(match matcher.Result) bool {
+	return s != nil && s.cfg.IsAutoEnabled(getMatchRepo(match))
+}
+
+// summary markers of the watched PRs, so the summary is not posted again when the watcher gets restarted
+const summaryStorageKey = "pr_summaries"
+
+// getSummaryMarkerKey returns the storage key of the summary marker: the same PR might be watched in multiple channels
+func getSummaryMarkerKey(channel string, watchKey string) string {
+	return invalidStorageChars.ReplaceAllString(channel+"-"+watchKey, "-")
+}
+
+// markSummaryPosted returns false if the summary of the watched PR was already posted in the channel, otherwise the PR gets marked
+func markSummaryPosted(channel string, watchKey string) bool {
+	key := getSummaryMarkerKey(channel, watchKey)
+
+	var posted bool
+	if err := storage.Read(summaryStorageKey, key, &posted); err == nil && posted {
+		return false
+	}
+
+	if err := storage.Write(summaryStorageKey, key, true); err != nil {
+		log.Warnf("error while storing PR summary marker: %s", err)
+	}
+
+	return true
+}
+
+func deleteSummaryMarker(channel string, watchKey string) {
+	if err := storage.Delete(summaryStorageKey, getSummaryMarkerKey(channel, watchKey)); err != nil {
+		log.Warnf("error while deleting PR summary marker: %s", err)
+	}
+}
+
+// summarize loads the diff of the PR and returns the generated summary
+func (s *summarizer) summarize(prFetcher fetcher, match matcher.Result, cfg *config.PullRequest) (string, error) {
+	prDiffFetcher, ok := prFetcher.(diffFetcher)
+	if !ok {
+		return "", errors.New("summaries are not supported for this provider")
+	}
+
+	pr, err := prFetcher.getPullRequest(match, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	description, diff, err := prDiffFetcher.getDiff(match)
+	if err != nil {
+		return "", errors.Wrap(err, "error while loading the diff")
+	}
+
+	prompt := fmt.Sprintf(
+		"Title: %s\nAuthor: %s\nDescription:\n%s\n\nDiff:\n%s",
+		pr.Name,
+		pr.Author,
+		description,
+		s.openaiCfg.TruncateText(diff, s.cfg.GetMaxTokens()),
+	)
+
+	messages := []openai.ChatMessage{
+		{Role: "system", Content: summarySystemMessage},
+		{Role: "user", Content: prompt},
+	}
+
+	return openai.Complete(s.openaiCfg, messages)
+}
+
+// postSummary replies the summary of the PR in the thread of the given message
+func (s *summarizer) postSummary(base bot.BaseCommand, prFetcher fetcher, match matcher.Result, cfg *config.PullRequest, message msg.Ref) error {
+	summary, err := s.summarize(prFetcher, match, cfg)
+	if err != nil {
+		return errors.Wrap(err, "can't generate the summary")
+	}
+
+	// the PR message might be a thread reply itself
+	threadTS := message.GetThread()
+	if threadTS == "" {
+		threadTS = message.GetTimestamp()
+	}
+	base.SendMessage(message, summary, slack.MsgOptionTS(threadTS))
+
+	return nil
+}
+
+// postAutoSummary posts the summary of a watched PR. Errors are only logged, as nobody asked for the summary.
+func (s *summarizer) postAutoSummary(base bot.BaseCommand, prFetcher fetcher, match matcher.Result, cfg *config.PullRequest, message msg.Ref) {
+	if err := s.postSummary(base, prFetcher, match, cfg, message); err != nil {
+		log.Warnf("error while posting the PR summary: %s", err)
+	}
+}
+
+type summaryCommand struct {
+	bot.BaseCommand
+	cfg        config.PullRequest
+	summarizer *summarizer
+
+	// the PR watchers, used to detect the provider of the given PR link
+	commands []command
+}
+
+// newSummaryCommand generates an AI summary of a PR via "summarize pr <url>"
+func newSummaryCommand(base bot.BaseCommand, cfg *config.Config, prCommands []bot.Command) bot.Command {
+	return &summaryCommand{base, cfg.PullRequest, newSummarizer(cfg), getWatchCommands(prCommands)}
+}
+
+func (c *summaryCommand) IsEnabled() bool {
+	return c.summarizer != nil
+}
+
+func (c *summaryCommand) GetMatcher() matcher.Matcher {
+	return matcher.NewRegexpMatcher(`summarize pr (?P<url>\S+)`, c.summarize)
+}
+
+func (c *summaryCommand) summarize(match matcher.Result, message msg.Message) {
+	url := getPlainURL(match.GetString("url"))
+
+	cmd, prMatch := findWatchCommand(c.commands, url)
+	if prMatch == nil {
+		c.ReplyError(message, fmt.Errorf("unsupported pull request link: %s", url))
+		return
+	}
+
+	if err := c.summarizer.postSummary(c.BaseCommand, cmd.fetcher, prMatch, &c.cfg, message); err != nil {
+		c.ReplyError(message, err)
+	}
+}
+
+func (c *summaryCommand) GetHelp() []bot.Help {
+	return []bot.Help{
+		{
+			Command:     "summarize pr <url>",
+			Description: "generates a short summary, risk notes and touched areas of a pull request via ChatGPT",
+			Category:    category,
+			Examples: []string{
+				"summarize pr https://github.com/innogames/slack-bot/pull/1",
+			},
+		},
+	}
+}
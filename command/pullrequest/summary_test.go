@@ -0,0 +1,169 @@
+package pullrequest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+	"github.com/innogames/slack-bot/v2/bot/storage"
+	"github.com/innogames/slack-bot/v2/mocks"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	storage.InitStorage("")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/team/repo/pulls/12", func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.Header.Get("Accept"), "diff") {
+			fmt.Fprint(w, "diff --git a/readme.md b/readme.md\n+new line")
+			return
+		}
+		fmt.Fprint(w, `{"title": "Add feature", "body": "adds the feature", "state": "open", "user": {"login": "alice"}}`)
+	})
+	mux.HandleFunc("/api/v3/repos/team/repo/pulls/12/reviews", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+
+	var prompts []string
+	rateLimited := false
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		if rateLimited {
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":{"message":"Rate limit reached"}}`)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+
+		var request struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.Unmarshal(body, &request)
+		prompts = append(prompts, request.Messages[1].Content)
+
+		fmt.Fprint(w, `{"choices": [{"message": {"role": "assistant", "content": "*Summary*: adds a feature"}}]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	slackClient := mocks.NewSlackClient(t)
+	base := bot.BaseCommand{SlackClient: slackClient}
+
+	t.Run("openai is not configured", func(t *testing.T) {
+		cmd := newSummaryCommand(base, &config.Config{}, nil).(*summaryCommand)
+		assert.False(t, cmd.IsEnabled())
+		assert.False(t, cmd.summarizer.isAutoEnabled(matcher.Result{"repo": "repo"}))
+	})
+
+	cfg := &config.Config{}
+	cfg.PullRequest = config.DefaultConfig.PullRequest
+	cfg.PullRequest.Summary = config.PullRequestSummary{Repos: []string{"team/repo"}}
+	cfg.Github.Enterprise = []config.GithubEnterprise{
+		{Host: server.URL, AccessToken: "secret"},
+	}
+	cfg.Set("openai", map[string]any{
+		"api_key":  "0815pass",
+		"api_host": server.URL,
+		"model":    "gpt-4o",
+	})
+
+	prCommands := newGithubEnterpriseCommands(base, cfg, nil)
+	cmd := newSummaryCommand(base, cfg, prCommands).(*summaryCommand)
+	require.True(t, cmd.IsEnabled())
+
+	commands := bot.Commands{}
+	commands.AddCommand(cmd)
+
+	t.Run("auto summary", func(t *testing.T) {
+		assert.True(t, cmd.summarizer.isAutoEnabled(matcher.Result{"project": "Team", "repo": "repo", "number": "12"}))
+		assert.False(t, cmd.summarizer.isAutoEnabled(matcher.Result{"project": "team", "repo": "other", "number": "12"}))
+
+		// the summary is posted only once per watched PR and channel, also when the watcher gets restarted
+		watchKey := getMatchWatchKey(matcher.Result{"project": "team", "repo": "repo", "number": "12"})
+		assert.True(t, markSummaryPosted("C1", watchKey))
+		assert.False(t, markSummaryPosted("C1", watchKey))
+		assert.True(t, markSummaryPosted("C2", watchKey))
+
+		deleteSummaryMarker("C1", watchKey)
+		assert.True(t, markSummaryPosted("C1", watchKey))
+		assert.False(t, markSummaryPosted("C2", watchKey))
+		deleteSummaryMarker("C1", watchKey)
+		deleteSummaryMarker("C2", watchKey)
+	})
+
+	t.Run("auto summary in a thread", func(t *testing.T) {
+		message := msg.Message{}
+		message.Channel = "C1"
+		message.Timestamp = "1234.5679"
+		message.Thread = "1234.0000"
+
+		slackClient.On("SendMessage", message, "*Summary*: adds a feature", mock.MatchedBy(func(option slack.MsgOption) bool {
+			_, values, _ := slack.UnsafeApplyMsgOptions("", "C1", "", option)
+			return values.Get("thread_ts") == "1234.0000"
+		})).Once().Return("")
+
+		match := matcher.Result{"project": "team", "repo": "repo", "number": "12"}
+		cmd.summarizer.postAutoSummary(base, prCommands[0].(command).fetcher, match, &cfg.PullRequest, message)
+		prompts = nil
+	})
+
+	t.Run("unsupported link", func(t *testing.T) {
+		message := msg.Message{}
+		message.Text = "summarize pr https://example.com/foo"
+
+		mocks.AssertError(slackClient, message, "unsupported pull request link: https://example.com/foo")
+		assert.True(t, commands.Run(message))
+	})
+
+	t.Run("summarize pr", func(t *testing.T) {
+		message := msg.Message{}
+		message.Timestamp = "1234.5678"
+		message.Text = "summarize pr <" + server.URL + "/team/repo/pull/12>"
+
+		slackClient.On("SendMessage", message, "*Summary*: adds a feature", mock.AnythingOfType("slack.MsgOption")).Once().Return("")
+		assert.True(t, commands.Run(message))
+
+		require.Len(t, prompts, 1)
+		assert.Equal(t, "Title: Add feature\nAuthor: alice\nDescription:\nadds the feature\n\nDiff:\ndiff --git a/readme.md b/readme.md\n+new line", prompts[0])
+	})
+
+	t.Run("summarize pr with API error", func(t *testing.T) {
+		rateLimited = true
+		defer func() {
+			rateLimited = false
+		}()
+
+		message := msg.Message{}
+		message.Text = "summarize pr <" + server.URL + "/team/repo/pull/12>"
+
+		mocks.AssertError(slackClient, message, "can't generate the summary: Rate limit reached")
+		assert.True(t, commands.Run(message))
+
+		// the automatic summary is skipped without a reply
+		match := matcher.Result{"project": "team", "repo": "repo", "number": "12"}
+		cmd.summarizer.postAutoSummary(base, prCommands[0].(command).fetcher, match, &cfg.PullRequest, message)
+	})
+
+	t.Run("truncated diff", func(t *testing.T) {
+		cmd.summarizer.cfg.MaxTokens = 2
+
+		summary, err := cmd.summarizer.summarize(prCommands[0].(command).fetcher, matcher.Result{"project": "team", "repo": "repo", "number": "12"}, &cfg.PullRequest)
+		require.NoError(t, err)
+		assert.Equal(t, "*Summary*: adds a feature", summary)
+		assert.True(t, strings.HasSuffix(prompts[1], "Diff:\ndiff --\n...(truncated)"))
+	})
+}
@@ -0,0 +1,130 @@
+package pullrequest
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/msg"
+)
+
+// watchers contains a refresh channel for each currently watched PR, used by the webhook receiver
+var watchers = &watchRegistry{
+	watchers: make(map[string][]chan struct{}),
+}
+
+type watchRegistry struct {
+	mu       sync.Mutex
+	watchers map[string][]chan struct{}
+}
+
+// register returns a channel which receives a signal when the PR got updated
+func (r *watchRegistry) register(key string) chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	refresh := make(chan struct{}, 1)
+	r.watchers[key] = append(r.watchers[key], refresh)
+
+	return refresh
+}
+
+func (r *watchRegistry) unregister(key string, refresh chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.watchers[key] {
+		if existing == refresh {
+			r.watchers[key] = append(r.watchers[key][:i], r.watchers[key][i+1:]...)
+			break
+		}
+	}
+
+	if len(r.watchers[key]) == 0 {
+		delete(r.watchers, key)
+	}
+}
+
+// trigger notifies all watchers of the given PR key, a repo key (ending with "#") notifies all PRs of the repo.
+// Returns the number of notified watchers
+func (r *watchRegistry) trigger(key string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for watchedKey, channels := range r.watchers {
+		if watchedKey != key && (!strings.HasSuffix(key, "#") || !strings.HasPrefix(watchedKey, key)) {
+			continue
+		}
+
+		for _, refresh := range channels {
+			// non-blocking: there is already a pending refresh
+			select {
+			case refresh <- struct{}{}:
+			default:
+			}
+			count++
+		}
+	}
+
+	return count
+}
+
+// getWatchKey returns a provider independent key of a PR, like "innogames/slack-bot#12"
+func getWatchKey(repo string, number int) string {
+	return getRepoWatchKey(repo) + strconv.Itoa(number)
+}
+
+func getRepoWatchKey(repo string) string {
+	return strings.ToLower(repo) + "#"
+}
+
+// getMatchWatchKey builds the watch key based on the matched PR link
+func getMatchWatchKey(match matcher.Result) string {
+	return getWatchKey(getMatchRepo(match), match.GetInt("number"))
+}
+
+// getMatchRepo returns the full repository name of the matched PR link, like "innogames/slack-bot"
+func getMatchRepo(match matcher.Result) string {
+	repo := strings.TrimSuffix(match.GetString("repo"), "/-")
+	if project := match.GetString("project"); project != "" {
+		repo = project + "/" + repo
+	}
+
+	return repo
+}
+
+// getWatchCommands returns the PR watchers of the given commands, skipping disabled providers
+func getWatchCommands(prCommands []bot.Command) []command {
+	commands := make([]command, 0, len(prCommands))
+	for _, prCommand := range prCommands {
+		if cmd, ok := prCommand.(command); ok {
+			commands = append(commands, cmd)
+		}
+	}
+
+	return commands
+}
+
+// findWatchCommand returns the PR watcher which is able to handle the given PR link
+func findWatchCommand(commands []command, url string) (command, matcher.Result) {
+	message := msg.Message{}
+	message.Text = url
+
+	for _, cmd := range commands {
+		if _, match := cmd.GetMatcher().Match(message); match != nil {
+			return cmd, match
+		}
+	}
+
+	return command{}, nil
+}
+
+// getPlainURL removes the Slack link formatting like "<https://example.com|example.com>"
+func getPlainURL(url string) string {
+	url, _, _ = strings.Cut(strings.Trim(url, "<>"), "|")
+
+	return url
+}
@@ -0,0 +1,259 @@
+package pullrequest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/innogames/slack-bot/v2/bot"
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/innogames/slack-bot/v2/bot/util"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const maxWebhookBodySize = 5 * 1024 * 1024
+
+var errInvalidSignature = errors.New("invalid webhook signature")
+
+// webhookCommand runs the HTTP receiver for PR/MR events of GitHub, GitLab and Bitbucket.
+// Each event triggers an immediate refresh of the matching PR watchers.
+type webhookCommand struct {
+	cfg config.PullRequestWebhook
+}
+
+func newWebhookCommand(cfg config.PullRequestWebhook) bot.Command {
+	return &webhookCommand{cfg}
+}
+
+func (c *webhookCommand) IsEnabled() bool {
+	return c.cfg.IsEnabled()
+}
+
+func (c *webhookCommand) GetMatcher() matcher.Matcher {
+	return matcher.NewVoidMatcher()
+}
+
+func (c *webhookCommand) RunAsync(ctx *util.ServerContext) {
+	ctx.RegisterChild()
+	defer ctx.ChildDone()
+
+	log.Infof("[PR] Init webhook receiver on http://%s/webhook/", c.cfg.Listener)
+
+	server := &http.Server{
+		Addr:              c.cfg.Listener,
+		Handler:           c.getHandler(),
+		ReadHeaderTimeout: 3 * time.Second,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Warnf("[PR] Failed to start webhook receiver: %s", err)
+		}
+	}()
+
+	<-ctx.Done()
+	_ = server.Shutdown(ctx)
+}
+
+func (c *webhookCommand) getHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /webhook/github", c.handle(c.cfg.GithubSecret, validateGithubSignature, parseGithubEvent))
+	mux.HandleFunc("POST /webhook/gitlab", c.handle(c.cfg.GitlabToken, validateGitlabToken, parseGitlabEvent))
+	mux.HandleFunc("POST /webhook/bitbucket", c.handle(c.cfg.BitbucketSecret, validateBitbucketSignature, parseBitbucketEvent))
+
+	return mux
+}
+
+type (
+	// validates the signature/token of the request with the configured secret
+	signatureValidator func(r *http.Request, body []byte, secret string) error
+
+	// extracts the keys of the affected PRs from the event. Keys ending with "#" are affecting all PRs of the repo
+	eventParser func(r *http.Request, body []byte) ([]string, error)
+)
+
+func (c *webhookCommand) handle(secret string, validate signatureValidator, parse eventParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if secret == "" {
+			http.Error(w, "webhook is not configured", http.StatusNotFound)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err = validate(r, body, secret); err != nil {
+			log.Warnf("[PR] Rejected webhook %s: %s", r.URL.Path, err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		keys, err := parse(r, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		triggered := 0
+		for _, key := range keys {
+			triggered += watchers.trigger(key)
+		}
+
+		log.Infof("[PR] Webhook %s refreshed %d watched PRs", r.URL.Path, triggered)
+		fmt.Fprintf(w, "refreshed %d watched pull requests", triggered)
+	}
+}
+
+// getHMACSignature returns the hex encoded HMAC-SHA256 of the body, like used by GitHub and Bitbucket
+func getHMACSignature(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func validateHMACHeader(r *http.Request, header string, body []byte, secret string) error {
+	signature, found := strings.CutPrefix(r.Header.Get(header), "sha256=")
+	if !found {
+		return errInvalidSignature
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(getHMACSignature(body, secret))) {
+		return errInvalidSignature
+	}
+
+	return nil
+}
+
+// https://docs.github.com/en/webhooks/using-webhooks/validating-webhook-deliveries
+func validateGithubSignature(r *http.Request, body []byte, secret string) error {
+	return validateHMACHeader(r, "X-Hub-Signature-256", body, secret)
+}
+
+// https://confluence.atlassian.com/bitbucketserver/manage-webhooks-938025878.html
+func validateBitbucketSignature(r *http.Request, body []byte, secret string) error {
+	return validateHMACHeader(r, "X-Hub-Signature", body, secret)
+}
+
+// GitLab sends the plain secret token: https://docs.gitlab.com/ee/user/project/integrations/webhooks.html
+func validateGitlabToken(r *http.Request, _ []byte, secret string) error {
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+		return errInvalidSignature
+	}
+
+	return nil
+}
+
+type githubEvent struct {
+	Number      int `json:"number"`
+	PullRequest struct {
+		Number int `json:"number"`
+	} `json:"pull_request"`
+	CheckRun struct {
+		PullRequests []struct {
+			Number int `json:"number"`
+		} `json:"pull_requests"`
+	} `json:"check_run"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+func parseGithubEvent(r *http.Request, body []byte) ([]string, error) {
+	var event githubEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+
+	repo := event.Repository.FullName
+	switch r.Header.Get("X-GitHub-Event") {
+	case "pull_request", "pull_request_review", "pull_request_review_comment":
+		return []string{getWatchKey(repo, max(event.Number, event.PullRequest.Number))}, nil
+	case "check_run":
+		keys := make([]string, 0, len(event.CheckRun.PullRequests))
+		for _, pr := range event.CheckRun.PullRequests {
+			keys = append(keys, getWatchKey(repo, pr.Number))
+		}
+		return keys, nil
+	case "check_suite", "status":
+		// only the commit is known: refresh all watched PRs of the repo
+		return []string{getRepoWatchKey(repo)}, nil
+	}
+
+	return nil, nil
+}
+
+type gitlabEvent struct {
+	ObjectKind string `json:"object_kind"`
+	Project    struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID int `json:"iid"`
+	} `json:"object_attributes"`
+	MergeRequest struct {
+		IID int `json:"iid"`
+	} `json:"merge_request"`
+}
+
+func parseGitlabEvent(_ *http.Request, body []byte) ([]string, error) {
+	var event gitlabEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+
+	repo := event.Project.PathWithNamespace
+	switch event.ObjectKind {
+	case "merge_request":
+		return []string{getWatchKey(repo, event.ObjectAttributes.IID)}, nil
+	case "note", "pipeline":
+		if event.MergeRequest.IID == 0 {
+			// e.g. a pipeline of a branch without MR
+			return nil, nil
+		}
+		return []string{getWatchKey(repo, event.MergeRequest.IID)}, nil
+	}
+
+	return nil, nil
+}
+
+type bitbucketEvent struct {
+	PullRequest struct {
+		ID    int `json:"id"`
+		ToRef struct {
+			Repository struct {
+				Slug    string `json:"slug"`
+				Project struct {
+					Key string `json:"key"`
+				} `json:"project"`
+			} `json:"repository"`
+		} `json:"toRef"`
+	} `json:"pullRequest"`
+}
+
+func parseBitbucketEvent(r *http.Request, body []byte) ([]string, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Event-Key"), "pr:") {
+		// e.g. "diagnostics:ping" or repository events
+		return nil, nil
+	}
+
+	var event bitbucketEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+
+	repo := event.PullRequest.ToRef.Repository
+	return []string{getWatchKey(repo.Project.Key+"/"+repo.Slug, event.PullRequest.ID)}, nil
+}
@@ -0,0 +1,148 @@
+package pullrequest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/innogames/slack-bot/v2/bot/config"
+	"github.com/innogames/slack-bot/v2/bot/matcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchRegistry(t *testing.T) {
+	key := getMatchWatchKey(matcher.Result{"project": "Innogames", "repo": "slack-bot", "number": "12"})
+	assert.Equal(t, "innogames/slack-bot#12", key)
+	assert.Equal(t, "group/project#3", getMatchWatchKey(matcher.Result{"repo": "group/project/-", "number": "3"}))
+
+	refresh := watchers.register(key)
+	otherRefresh := watchers.register("innogames/slack-bot#13")
+	defer watchers.unregister("innogames/slack-bot#13", otherRefresh)
+
+	assert.Equal(t, 1, watchers.trigger("innogames/slack-bot#12"))
+	// a pending refresh is not blocking
+	assert.Equal(t, 1, watchers.trigger("innogames/slack-bot#12"))
+	assert.Len(t, refresh, 1)
+	assert.Empty(t, otherRefresh)
+
+	// all PRs of the repo
+	assert.Equal(t, 2, watchers.trigger(getRepoWatchKey("innogames/slack-bot")))
+	assert.Equal(t, 0, watchers.trigger(getRepoWatchKey("innogames/slack")))
+
+	watchers.unregister(key, refresh)
+	assert.Equal(t, 0, watchers.trigger("innogames/slack-bot#12"))
+}
+
+func TestWebhook(t *testing.T) {
+	cfg := config.PullRequestWebhook{
+		Listener:        ":0",
+		GithubSecret:    "github-secret",
+		GitlabToken:     "gitlab-token",
+		BitbucketSecret: "bitbucket-secret",
+	}
+	handler := newWebhookCommand(cfg).(*webhookCommand).getHandler()
+
+	send := func(path string, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("GitHub", func(t *testing.T) {
+		refresh := watchers.register("innogames/slack-bot#12")
+		defer watchers.unregister("innogames/slack-bot#12", refresh)
+
+		body := `{"action": "submitted", "pull_request": {"number": 12}, "repository": {"full_name": "innogames/slack-bot"}}`
+
+		rec := send("/webhook/github", body, map[string]string{
+			"X-GitHub-Event":      "pull_request_review",
+			"X-Hub-Signature-256": "sha256=" + getHMACSignature([]byte(body), "wrong"),
+		})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Empty(t, refresh)
+
+		rec = send("/webhook/github", body, map[string]string{
+			"X-GitHub-Event":      "pull_request_review",
+			"X-Hub-Signature-256": "sha256=" + getHMACSignature([]byte(body), "github-secret"),
+		})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "refreshed 1 watched pull requests", rec.Body.String())
+		assert.Len(t, refresh, 1)
+	})
+
+	t.Run("GitHub events", func(t *testing.T) {
+		keys, err := parseGithubEvent(
+			&http.Request{Header: http.Header{"X-Github-Event": []string{"check_run"}}},
+			[]byte(`{"check_run": {"pull_requests": [{"number": 1}, {"number": 2}]}, "repository": {"full_name": "a/b"}}`),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a/b#1", "a/b#2"}, keys)
+
+		keys, err = parseGithubEvent(
+			&http.Request{Header: http.Header{"X-Github-Event": []string{"status"}}},
+			[]byte(`{"sha": "abc", "repository": {"full_name": "a/b"}}`),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a/b#"}, keys)
+
+		keys, err = parseGithubEvent(
+			&http.Request{Header: http.Header{"X-Github-Event": []string{"ping"}}},
+			[]byte(`{}`),
+		)
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	t.Run("GitLab", func(t *testing.T) {
+		refresh := watchers.register("group/project#5")
+		defer watchers.unregister("group/project#5", refresh)
+
+		body := `{"object_kind": "pipeline", "project": {"path_with_namespace": "Group/Project"}, "merge_request": {"iid": 5}}`
+
+		rec := send("/webhook/gitlab", body, map[string]string{"X-Gitlab-Token": "wrong"})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec = send("/webhook/gitlab", body, map[string]string{"X-Gitlab-Token": "gitlab-token"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, refresh, 1)
+
+		keys, err := parseGitlabEvent(nil, []byte(`{"object_kind": "merge_request", "project": {"path_with_namespace": "group/project"}, "object_attributes": {"iid": 7}}`))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"group/project#7"}, keys)
+	})
+
+	t.Run("Bitbucket", func(t *testing.T) {
+		refresh := watchers.register("proj/repo#42")
+		defer watchers.unregister("proj/repo#42", refresh)
+
+		body := `{"pullRequest": {"id": 42, "toRef": {"repository": {"slug": "repo", "project": {"key": "PROJ"}}}}}`
+
+		rec := send("/webhook/bitbucket", body, map[string]string{
+			"X-Event-Key": "pr:reviewer:approved",
+		})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec = send("/webhook/bitbucket", body, map[string]string{
+			"X-Event-Key":     "pr:reviewer:approved",
+			"X-Hub-Signature": "sha256=" + getHMACSignature([]byte(body), "bitbucket-secret"),
+		})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, refresh, 1)
+	})
+
+	t.Run("not configured provider", func(t *testing.T) {
+		handler := newWebhookCommand(config.PullRequestWebhook{Listener: ":0"}).(*webhookCommand).getHandler()
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook/github", strings.NewReader("{}"))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
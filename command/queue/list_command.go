@@ -122,6 +122,7 @@ func (c *listCommand) getQueueAsBlocks(message msg.Message, filter filterFunc) (
 			queuedEvent.GetText(),
 			c.getReactions(queuedEvent),
 		)
+		text += getFollowUpText(queuedEvent)
 
 		textBlock := client.GetTextBlock(text)
 		blocks = append(
@@ -150,6 +151,24 @@ func (c *listCommand) getQueueAsBlocks(message msg.Message, filter filterFunc) (
 	return count, blocks
 }
 
+// getFollowUpText lists the name and the queued "then" commands of a running command
+func getFollowUpText(queuedEvent msg.Message) string {
+	runningCommand := GetRunningCommand(queuedEvent.GetUniqueKey())
+	if runningCommand == nil {
+		return ""
+	}
+
+	var text strings.Builder
+	if name := runningCommand.GetName(); name != "" {
+		fmt.Fprintf(&text, "\nTask: *%s*", name)
+	}
+	for _, followUp := range runningCommand.GetFollowUps() {
+		fmt.Fprintf(&text, "\n> then `%s`", followUp)
+	}
+
+	return text.String()
+}
+
 func (c *listCommand) getReactions(ref msg.Ref) string {
 	var formattedReactions strings.Builder
 	msgRef := slack.NewRefToMessage(ref.GetChannel(), ref.GetTimestamp())
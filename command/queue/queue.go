@@ -1,6 +1,7 @@
 package queue
 
 import (
+	"fmt"
 	"slices"
 	"strings"
 	"sync"
@@ -58,6 +59,9 @@ func AddRunningCommand(message msg.Message, fallbackCommand string) *RunningComm
 
 		mu.Lock()
 		delete(runningCommands, key)
+		if name := runningCommand.GetName(); name != "" && namedCommands[name] == runningCommand {
+			delete(namedCommands, name)
+		}
 		mu.Unlock()
 		if queueKey != "" {
 			if err := storage.Delete(storageKey, queueKey); err != nil {
@@ -84,6 +88,42 @@ func GetRunningCommand(key string) *RunningCommand {
 	return runningCommands[key]
 }
 
+// GetNamedCommand returns the running command which got the given name via "name task", nil if there is none
+func GetNamedCommand(name string) *RunningCommand {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return namedCommands[strings.ToLower(name)]
+}
+
+// setTaskName assigns a name to the running command of the given key, so other commands can wait for it via "after <name>"
+func setTaskName(key string, name string) error {
+	name = strings.ToLower(name)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	runningCommand, ok := runningCommands[key]
+	if !ok {
+		return errNoRunningCommand
+	}
+
+	if existing, ok := namedCommands[name]; ok && existing != runningCommand {
+		return fmt.Errorf("there is already a running task named %s", name)
+	}
+
+	runningCommand.mu.Lock()
+	if runningCommand.name != "" {
+		delete(namedCommands, runningCommand.name)
+	}
+	runningCommand.name = name
+	runningCommand.mu.Unlock()
+
+	namedCommands[name] = runningCommand
+
+	return nil
+}
+
 func executeFallbackCommand() {
 	keys, _ := storage.GetKeys(storageKey)
 	if len(keys) == 0 {
@@ -260,3 +260,107 @@ func TestFallbackQueueKeepsMessagesSeparate(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, keys)
 }
+
+func TestThenConditionsAndNamedTasks(t *testing.T) {
+	client.InternalMessages = make(chan msg.Message, 2)
+	slackClient := mocks.NewSlackClient(t)
+	base := bot.BaseCommand{SlackClient: slackClient}
+
+	command := bot.Commands{}
+	command.AddCommand(NewQueueCommand(base))
+
+	lock := mocks.LockInternalMessages()
+	defer lock.Unlock()
+
+	message := msg.Message{}
+	message.User = "testUser1"
+	message.Channel = "C1234"
+
+	t.Run("skip follow-up on failure", func(t *testing.T) {
+		runningCommand := AddRunningCommand(message, "")
+
+		message.Text = "then on success reply deploy"
+		successMessage := message
+		mocks.AssertReaction(slackClient, waitIcon, successMessage)
+		skipped := make(chan struct{})
+		slackClient.On("AddReaction", util.Reaction(skipIcon), successMessage).Once().Run(func(mock.Arguments) {
+			close(skipped)
+		})
+		assert.True(t, command.Run(successMessage))
+
+		message.Text = "then on failure reply broken"
+		failureMessage := message
+		mocks.AssertReaction(slackClient, waitIcon, failureMessage)
+		mocks.AssertReaction(slackClient, doneIcon, failureMessage)
+		assert.True(t, command.Run(failureMessage))
+
+		assert.Equal(t, []string{"on success: reply deploy", "on failure: reply broken"}, runningCommand.GetFollowUps())
+
+		runningCommand.DoneWithResult(ResultFailure)
+		// the second call has no effect
+		runningCommand.Done()
+		assert.Equal(t, ResultFailure, runningCommand.WaitForResult())
+
+		handledEvent := mocks.WaitTillHavingInternalMessage()
+		assert.Equal(t, "reply broken", handledEvent.Text)
+
+		<-skipped
+		WaitTillHavingNoQueuedMessage()
+		assert.Empty(t, client.InternalMessages)
+	})
+
+	t.Run("named task", func(t *testing.T) {
+		message.Text = "name task build"
+		mocks.AssertError(slackClient, message, "you have to call this command when another long running command is already running")
+		assert.True(t, command.Run(message))
+
+		runningCommand := AddRunningCommand(message, "")
+
+		mocks.AssertSlackMessage(slackClient, message, "the running task is now named *build*, use `after build <command>` to wait for it")
+		assert.True(t, command.Run(message))
+		assert.Equal(t, "build", runningCommand.GetName())
+		assert.Equal(t, runningCommand, GetNamedCommand("Build"))
+
+		// the name is already used by another task
+		otherMessage := msg.Message{}
+		otherMessage.User = "testUser2"
+		otherMessage.Channel = "C4321"
+		otherMessage.Text = "name task build"
+		otherCommand := AddRunningCommand(otherMessage, "")
+		mocks.AssertError(slackClient, otherMessage, "there is already a running task named build")
+		assert.True(t, command.Run(otherMessage))
+		otherCommand.Done()
+
+		// wait for the task from another channel
+		otherMessage.Text = "after build on success reply build is done"
+		mocks.AssertReaction(slackClient, waitIcon, otherMessage)
+		mocks.AssertReaction(slackClient, doneIcon, otherMessage)
+		assert.True(t, command.Run(otherMessage))
+
+		runningCommand.Done()
+		handledEvent := mocks.WaitTillHavingInternalMessage()
+		assert.Equal(t, "reply build is done", handledEvent.Text)
+		assert.Equal(t, "C4321", handledEvent.Channel)
+
+		WaitTillHavingNoQueuedMessage()
+		assert.Nil(t, GetNamedCommand("build"))
+
+		otherMessage.Text = "after build reply too late"
+		mocks.AssertError(slackClient, otherMessage, "there is no running task named build")
+		assert.True(t, command.Run(otherMessage))
+	})
+
+	t.Run("follow-ups in list", func(t *testing.T) {
+		queuedMessage := message
+		queuedMessage.Text = "trigger job Build"
+		runningCommand := AddRunningCommand(queuedMessage, "")
+		require.NoError(t, setTaskName(queuedMessage.GetUniqueKey(), "build"))
+		runningCommand.addFollowUp("reply done")
+
+		assert.Equal(t, "\nTask: *build*\n> then `reply done`", getFollowUpText(queuedMessage))
+
+		runningCommand.Done()
+		WaitTillHavingNoQueuedMessage()
+		assert.Empty(t, getFollowUpText(queuedMessage))
+	})
+}
@@ -7,11 +7,38 @@ import (
 // list of currently running commands
 var runningCommands = map[string]*RunningCommand{}
 
+// list of currently running commands which got a name via "name task <name>"
+var namedCommands = map[string]*RunningCommand{}
+
+// Result is the outcome of a RunningCommand, e.g. if the Jenkins build was successful
+type Result int
+
+const (
+	// ResultSuccess is the default result when the command is marked as Done()
+	ResultSuccess Result = iota
+	// ResultFailure marks a failed command, e.g. a failed Jenkins build or a rejected approval
+	ResultFailure
+)
+
+func (r Result) String() string {
+	if r == ResultFailure {
+		return "failure"
+	}
+
+	return "success"
+}
+
 // RunningCommand is a wrapper to sync.WaitGroup to control the behavior of a running command:
-// - when the command is done, call the Done() method
+// - when the command is done, call the Done() method (or DoneWithResult() to pass a failure)
 // - listener can register via Wait() method which is blocking until command is done
 type RunningCommand struct {
-	wg sync.WaitGroup
+	wg       sync.WaitGroup
+	doneOnce sync.Once
+
+	mu        sync.RWMutex
+	result    Result
+	name      string
+	followUps []string
 }
 
 // Wait blocks until command is Done()
@@ -19,7 +46,52 @@ func (r *RunningCommand) Wait() {
 	r.wg.Wait()
 }
 
+// WaitForResult blocks until the command is done and returns its result
+func (r *RunningCommand) WaitForResult() Result {
+	r.wg.Wait()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.result
+}
+
 // Done will finish the sync.WaitGroup and releases the lock for Done()
 func (r *RunningCommand) Done() {
-	r.wg.Done()
+	r.DoneWithResult(ResultSuccess)
+}
+
+// DoneWithResult finishes the command with the given result, which is used by "then on success/failure" commands.
+// Only the first call has an effect
+func (r *RunningCommand) DoneWithResult(result Result) {
+	r.doneOnce.Do(func() {
+		r.mu.Lock()
+		r.result = result
+		r.mu.Unlock()
+
+		r.wg.Done()
+	})
+}
+
+// GetName returns the name of the task, empty if it got no name
+func (r *RunningCommand) GetName() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.name
+}
+
+// GetFollowUps returns the queued commands which are waiting for this command
+func (r *RunningCommand) GetFollowUps() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]string(nil), r.followUps...)
+}
+
+func (r *RunningCommand) addFollowUp(followUp string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.followUps = append(r.followUps, followUp)
 }
@@ -2,6 +2,7 @@ package queue
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/innogames/slack-bot/v2/bot"
 	"github.com/innogames/slack-bot/v2/bot/matcher"
@@ -10,6 +11,10 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+const skipIcon = "fast_forward"
+
+var errNoRunningCommand = errors.New("you have to call this command when another long running command is already running")
+
 // NewQueueCommand is able to execute a command when another blocking process is done
 // e.g. have a running jenkins job and using "then reply done!" to get a information later
 func NewQueueCommand(base bot.BaseCommand) bot.Command {
@@ -25,24 +30,66 @@ type thenCommand struct {
 }
 
 func (c *thenCommand) GetMatcher() matcher.Matcher {
-	return matcher.NewRegexpMatcher("(?i:queue|then) (?P<command>.*)", c.run)
+	return matcher.NewGroupMatcher(
+		matcher.NewRegexpMatcher(`name task (?P<task>[\w\-.]+)`, c.nameTask),
+		matcher.NewRegexpMatcher(`after (?P<task>[\w\-.]+)( on (?P<condition>success|failure))? (?P<command>.*)`, c.after),
+		matcher.NewRegexpMatcher(`(?i:queue|then)( on (?P<condition>success|failure))? (?P<command>.*)`, c.run),
+	)
+}
+
+// nameTask gives the currently running command a name, so "after <name>" can wait for it from any other channel
+func (c *thenCommand) nameTask(match matcher.Result, message msg.Message) {
+	task := match.GetString("task")
+	if err := setTaskName(message.GetUniqueKey(), task); err != nil {
+		c.ReplyError(message, err)
+		return
+	}
+
+	c.SendMessage(message, fmt.Sprintf("the running task is now named *%s*, use `after %s <command>` to wait for it", task, task))
 }
 
 func (c *thenCommand) run(match matcher.Result, message msg.Message) {
 	runningCommand := GetRunningCommand(message.GetUniqueKey())
 	if runningCommand == nil {
-		c.ReplyError(
-			message,
-			errors.New("you have to call this command when another long running command is already running"),
-		)
+		c.ReplyError(message, errNoRunningCommand)
 		return
 	}
 
+	c.queue(runningCommand, match, message)
+}
+
+func (c *thenCommand) after(match matcher.Result, message msg.Message) {
+	task := match.GetString("task")
+	runningCommand := GetNamedCommand(task)
+	if runningCommand == nil {
+		c.ReplyError(message, fmt.Errorf("there is no running task named %s", task))
+		return
+	}
+
+	c.queue(runningCommand, match, message)
+}
+
+// queue executes the command when the running command is done and the optional condition is fulfilled
+func (c *thenCommand) queue(runningCommand *RunningCommand, match matcher.Result, message msg.Message) {
 	command := match.GetString("command")
+	condition := match.GetString("condition")
+
+	followUp := command
+	if condition != "" {
+		followUp = fmt.Sprintf("on %s: %s", condition, command)
+	}
+	runningCommand.addFollowUp(followUp)
+
 	c.AddReaction(waitIcon, message)
 
 	go func() {
-		runningCommand.Wait()
+		result := runningCommand.WaitForResult()
+
+		if condition != "" && condition != result.String() {
+			c.AddReaction(skipIcon, message)
+			log.Infof("[Queue] Blocking command is over with %s, skip message: %s", result, command)
+			return
+		}
 
 		c.AddReaction(doneIcon, message)
 
@@ -57,11 +104,28 @@ func (c *thenCommand) GetHelp() []bot.Help {
 	return []bot.Help{
 		{
 			Command:     "then (or queue)",
-			Description: "queue a command which is executed when the current task is done",
+			Description: "queue a command which is executed when the current task is done. With 'on success' or 'on failure' the command is only executed with the matching result",
 			Examples: []string{
 				"queue reply My job is ready",
 				"queue trigger job Deploy master",
 				"then trigger job IntegrationTest",
+				"then on success trigger job DeployProd",
+				"then on failure reply the build is broken!",
+			},
+		},
+		{
+			Command:     "name task <name>",
+			Description: "gives the current task a name, so other commands can wait for it via 'after <name>'",
+			Examples: []string{
+				"name task build",
+			},
+		},
+		{
+			Command:     "after <task>",
+			Description: "queue a command which is executed when the named task is done, also from other channels",
+			Examples: []string{
+				"after build reply build is done",
+				"after build on success trigger job Deploy",
 			},
 		},
 	}
@@ -37,16 +37,29 @@ type vcsCommand struct {
 }
 
 func (c *vcsCommand) GetMatcher() matcher.Matcher {
-	return matcher.NewTextMatcher("list branches", c.listBranches)
+	return matcher.NewRegexpMatcher(`list branches( (?P<repository>[\w\-.]+))?`, c.listBranches)
 }
 
-func (c *vcsCommand) listBranches(_ matcher.Result, message msg.Message) {
-	branches := vcs.GetBranches()
-	slices.Sort(branches)
+func (c *vcsCommand) listBranches(match matcher.Result, message msg.Message) {
+	repository := match.GetString("repository")
+	if repository != "" && !slices.Contains(vcs.GetRepositories(), repository) {
+		c.SendMessage(message, fmt.Sprintf(
+			"Repository *%s* is not known. Known repositories: %s",
+			repository,
+			strings.Join(vcs.GetRepositories(), ", "),
+		))
+		return
+	}
+
+	branchNames := make([]string, 0)
+	for _, branch := range vcs.GetBranches(repository) {
+		branchNames = append(branchNames, branch.Name)
+	}
+	slices.Sort(branchNames)
 
 	response := strings.Builder{}
-	fmt.Fprintf(&response, "Found %d branches:\n", len(branches))
-	for _, branch := range branches {
+	fmt.Fprintf(&response, "Found %d branches:\n", len(branchNames))
+	for _, branch := range branchNames {
 		fmt.Fprintf(&response, "- %s\n", branch)
 	}
 
@@ -56,10 +69,11 @@ func (c *vcsCommand) listBranches(_ matcher.Result, message msg.Message) {
 func (c *vcsCommand) GetHelp() []bot.Help {
 	return []bot.Help{
 		{
-			Command:     "list branch",
-			Description: "List all found VCS branches",
+			Command:     "list branches [repository]",
+			Description: "List all found VCS branches, of all or only of the given repository",
 			Examples: []string{
 				"list branches",
+				"list branches frontend",
 			},
 		},
 	}
@@ -44,4 +44,20 @@ func TestVCS(t *testing.T) {
 		time.Sleep(100 * time.Millisecond)
 		assert.True(t, actual)
 	})
+	t.Run("list branches of unknown repository", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.BranchLookup = config.VCS{
+			Type:           "git",
+			UpdateInterval: time.Second,
+		}
+
+		message := msg.Message{}
+		message.Text = "list branches frontend"
+
+		mocks.AssertSlackMessage(slackClient, message, "Repository *frontend* is not known. Known repositories: ")
+
+		commands := GetCommands(base, cfg)
+		actual := commands.Run(message)
+		assert.True(t, actual)
+	})
 }
@@ -159,6 +159,45 @@ func (_m *SlackClient) GetUserPresence(user string) (*slack.UserPresence, error)
 	return r0, r1
 }
 
+// ListPins provides a mock function with given fields: channel
+func (_m *SlackClient) ListPins(channel string) ([]slack.Item, *slack.Paging, error) {
+	ret := _m.Called(channel)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPins")
+	}
+
+	var r0 []slack.Item
+	var r1 *slack.Paging
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string) ([]slack.Item, *slack.Paging, error)); ok {
+		return rf(channel)
+	}
+	if rf, ok := ret.Get(0).(func(string) []slack.Item); ok {
+		r0 = rf(channel)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]slack.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) *slack.Paging); ok {
+		r1 = rf(channel)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*slack.Paging)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(channel)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // PinMessage provides a mock function with given fields: channel, timestamp
 func (_m *SlackClient) PinMessage(channel string, timestamp string) error {
 	ret := _m.Called(channel, timestamp)